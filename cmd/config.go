@@ -2,13 +2,16 @@ package cmd
 
 import (
 	"os"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/trento-project/agent/internal/agent"
+	"github.com/trento-project/agent/internal/core/sapsystem"
 	"github.com/trento-project/agent/internal/discovery"
 	"github.com/trento-project/agent/internal/discovery/collector"
+	"github.com/trento-project/agent/internal/redaction"
 )
 
 func validatePeriod(durationFlag string, minValue time.Duration) error {
@@ -20,6 +23,32 @@ func validatePeriod(durationFlag string, minValue time.Duration) error {
 	return nil
 }
 
+// loadSAPSystemPaths reads the SAP installation roots and the paths overridden for each SID,
+// which are only available in the configuration file
+func loadSAPSystemPaths() (sapsystem.DiscoveryPaths, error) {
+	paths := sapsystem.DiscoveryPaths{
+		Roots:     viper.GetStringSlice("sapsystem-roots"),
+		Overrides: map[string]sapsystem.SystemPaths{},
+	}
+
+	// the commands without the sapsystem-roots flag, like facts gather, use the default roots
+	if len(paths.Roots) == 0 {
+		paths.Roots = sapsystem.NewDefaultDiscoveryPaths().Roots
+	}
+
+	overrides := map[string]sapsystem.SystemPaths{}
+	if err := viper.UnmarshalKey("sapsystem-path-overrides", &overrides); err != nil {
+		return paths, errors.Wrap(err, "invalid sapsystem-path-overrides")
+	}
+
+	// viper keys are case insensitive, and the SIDs are uppercase
+	for sid, override := range overrides {
+		paths.Overrides[strings.ToUpper(sid)] = override
+	}
+
+	return paths, nil
+}
+
 func LoadConfig() (*agent.Config, error) {
 	minPeriodValues := map[string]time.Duration{
 		"cluster-discovery-period":      discovery.ClusterDiscoveryMinPeriod,
@@ -65,10 +94,24 @@ func LoadConfig() (*agent.Config, error) {
 		Subscription: viper.GetDuration("subscription-discovery-period"),
 	}
 
+	sapSystemPaths, err := loadSAPSystemPaths()
+	if err != nil {
+		return nil, err
+	}
+
 	discoveriesConfig := &discovery.DiscoveriesConfig{
 		SSHAddress:               sshAddress,
 		CollectorConfig:          collectorConfig,
 		DiscoveriesPeriodsConfig: discoveryPeriodsConfig,
+		ClusterEventsEnabled:     viper.GetBool("cluster-discovery-events"),
+		SAPSystemPaths:           sapSystemPaths,
+	}
+
+	redactionConfig := &redaction.Config{
+		KeyPatterns:   viper.GetStringSlice("redaction-key-patterns"),
+		ValuePatterns: viper.GetStringSlice("redaction-value-patterns"),
+		Mode:          viper.GetString("redaction-mode"),
+		Salt:          viper.GetString("redaction-salt"),
 	}
 
 	return &agent.Config{
@@ -78,5 +121,6 @@ func LoadConfig() (*agent.Config, error) {
 		FactsEngineEnabled: viper.GetBool("factsengine"),
 		FactsServiceURL:    viper.GetString("facts-service-url"),
 		PluginsFolder:      viper.GetString("plugins-folder"),
+		RedactionConfig:    redactionConfig,
 	}, nil
 }
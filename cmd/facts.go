@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/trento-project/agent/internal/agent"
+	"github.com/trento-project/agent/internal/core/sapsystem"
 	"github.com/trento-project/agent/internal/factsengine/gatherers"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
 )
@@ -79,7 +80,12 @@ func gather(*cobra.Command, []string) {
 	var argument = viper.GetString("argument")
 	var pluginsFolder = viper.GetString("plugins-folder")
 
-	gathererRegistry := gatherers.NewRegistry(gatherers.StandardGatherers())
+	sapSystemPaths, err := loadSAPSystemPaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gathererRegistry := gatherers.NewRegistry(gatherers.StandardGatherers(sapSystemPaths))
 
 	log.Info("loading plugins")
 
@@ -133,7 +139,7 @@ func cleanupAndFatal(err error) {
 func list(*cobra.Command, []string) {
 	var pluginsFolder = viper.GetString("plugins-folder")
 
-	gathererRegistry := gatherers.NewRegistry(gatherers.StandardGatherers())
+	gathererRegistry := gatherers.NewRegistry(gatherers.StandardGatherers(sapsystem.NewDefaultDiscoveryPaths()))
 
 	log.Info("loading plugins")
 
@@ -13,6 +13,8 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/trento-project/agent/internal/agent"
+	"github.com/trento-project/agent/internal/core/sapsystem"
+	"github.com/trento-project/agent/internal/redaction"
 )
 
 func NewStartCmd() *cobra.Command {
@@ -61,6 +63,31 @@ func NewStartCmd() *cobra.Command {
 			"API key provided by trento control plane. Allows communication",
 		)
 
+	startCmd.Flags().
+		StringSlice(
+			"redaction-key-patterns",
+			redaction.DefaultKeyPatterns,
+			"Fields with a name matching any of these patterns are redacted before publishing, * matches any character",
+		)
+	startCmd.Flags().
+		StringSlice(
+			"redaction-value-patterns",
+			redaction.DefaultValuePatterns,
+			"Values matching any of these regular expressions are redacted before publishing",
+		)
+	startCmd.Flags().
+		String(
+			"redaction-mode",
+			redaction.MarkerMode,
+			"Replace the redacted values with a marker, or with a salted hash (marker, hash)",
+		)
+	startCmd.Flags().
+		String(
+			"redaction-salt",
+			"",
+			"Salt of the hash used to redact the values, required in the hash redaction mode",
+		)
+
 	startCmd.Flags().
 		DurationVarP(
 			&clusterDiscoveryPeriod,
@@ -69,6 +96,12 @@ func NewStartCmd() *cobra.Command {
 			10*time.Second,
 			"Cluster discovery mechanism loop period in seconds",
 		)
+	startCmd.Flags().
+		Bool(
+			"cluster-discovery-events",
+			false,
+			"Run the cluster discovery as soon as the cluster configuration or status changes",
+		)
 	startCmd.Flags().
 		DurationVarP(
 			&sapSystemDiscoveryPeriod,
@@ -77,6 +110,12 @@ func NewStartCmd() *cobra.Command {
 			10*time.Second,
 			"SAP systems discovery mechanism loop period in seconds",
 		)
+	startCmd.Flags().
+		StringSlice(
+			"sapsystem-roots",
+			sapsystem.NewDefaultDiscoveryPaths().Roots,
+			"Folders where the SAP systems are installed, with a folder for each SID",
+		)
 	startCmd.Flags().
 		DurationVarP(
 			&cloudDiscoveryPeriod,
@@ -16,6 +16,7 @@ import (
 	"github.com/trento-project/agent/internal/discovery/collector"
 	"github.com/trento-project/agent/internal/factsengine"
 	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/internal/redaction"
 )
 
 const machineIDPath = "/etc/machine-id"
@@ -30,6 +31,7 @@ type Agent struct {
 	config          *Config
 	collectorClient collector.Client
 	discoveries     []discovery.Discovery
+	redactor        *redaction.Redactor
 }
 
 type Config struct {
@@ -38,6 +40,7 @@ type Config struct {
 	FactsEngineEnabled bool
 	FactsServiceURL    string
 	PluginsFolder      string
+	RedactionConfig    *redaction.Config
 }
 
 // NewAgent returns a new instance of Agent with the given configuration
@@ -49,7 +52,12 @@ func NewAgent(config *Config) (*Agent, error) {
 
 	config.DiscoveriesConfig.CollectorConfig.AgentID = agentID
 
-	collectorClient := collector.NewCollectorClient(config.DiscoveriesConfig.CollectorConfig)
+	redactor, err := redaction.NewRedactor(*config.RedactionConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not configure the redaction of the published data")
+	}
+
+	collectorClient := collector.NewCollectorClient(config.DiscoveriesConfig.CollectorConfig, redactor)
 
 	discoveries := []discovery.Discovery{
 		discovery.NewClusterDiscovery(collectorClient, *config.DiscoveriesConfig),
@@ -64,6 +72,7 @@ func NewAgent(config *Config) (*Agent, error) {
 		config:          config,
 		collectorClient: collectorClient,
 		discoveries:     discoveries,
+		redactor:        redactor,
 	}
 	return agent, nil
 }
@@ -103,7 +112,7 @@ func (a *Agent) Start(ctx context.Context) error {
 
 	if a.config.FactsEngineEnabled {
 
-		gathererRegistry := gatherers.NewRegistry(gatherers.StandardGatherers())
+		gathererRegistry := gatherers.NewRegistry(gatherers.StandardGatherers(a.config.DiscoveriesConfig.SAPSystemPaths))
 
 		log.Info("loading plugins")
 
@@ -121,7 +130,7 @@ func (a *Agent) Start(ctx context.Context) error {
 
 		gathererRegistry.AddGatherers(gatherersFromPlugins)
 
-		c := factsengine.NewFactsEngine(a.agentID, a.config.FactsServiceURL, *gathererRegistry)
+		c := factsengine.NewFactsEngine(a.agentID, a.config.FactsServiceURL, *gathererRegistry, a.redactor)
 
 		g.Go(func() error {
 			log.Info("Starting fact gathering service...")
@@ -156,7 +165,14 @@ func (a *Agent) startDiscoverTicker(ctx context.Context, d discovery.Discovery)
 		}
 		log.Infof("%s discovery tick output: %s", d.GetID(), result)
 	}
-	repeat(ctx, d.GetID(), tick, d.GetInterval())
+
+	var changes chan struct{}
+	if watchable, ok := d.(discovery.WatchableDiscovery); ok {
+		changes = make(chan struct{}, 1)
+		go watchable.Watch(ctx, changes)
+	}
+
+	repeat(ctx, d.GetID(), tick, d.GetInterval(), changes)
 
 }
 
@@ -168,12 +184,12 @@ func (a *Agent) startHeartbeatTicker(ctx context.Context) {
 		}
 	}
 
-	repeat(ctx, "agent.heartbeat", tick, HeartbeatInterval)
+	repeat(ctx, "agent.heartbeat", tick, HeartbeatInterval, nil)
 }
 
-// Repeat executes a function at a given interval.
+// Repeat executes a function at a given interval, and every time a change is notified.
 // the first tick runs immediately
-func repeat(ctx context.Context, operation string, tick func(), interval time.Duration) {
+func repeat(ctx context.Context, operation string, tick func(), interval time.Duration, changes <-chan struct{}) {
 	tick()
 
 	ticker := time.NewTicker(interval)
@@ -186,6 +202,11 @@ func repeat(ctx context.Context, operation string, tick func(), interval time.Du
 		case <-ticker.C:
 			tick()
 			log.Debugf(msg)
+		case <-changes:
+			log.Debugf("Change notified for operation %s", operation)
+			tick()
+			ticker.Reset(interval)
+			log.Debugf(msg)
 		case <-ctx.Done():
 			return
 		}
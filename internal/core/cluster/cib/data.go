@@ -1,5 +1,10 @@
 package cib
 
+import (
+	"strings"
+	"time"
+)
+
 /*
 The Cluster Information Base (Root)
 is an XML representation of the cluster’s configuration
@@ -11,6 +16,9 @@ https://clusterlabs.org/pacemaker/doc/en-US/Pacemaker/2.0/html-single/Pacemaker_
 */
 
 type Root struct {
+	AdminEpoch    int `xml:"admin_epoch,attr"`
+	Epoch         int `xml:"epoch,attr"`
+	NumUpdates    int `xml:"num_updates,attr"`
 	Configuration struct {
 		CrmConfig struct {
 			ClusterProperties []Attribute `xml:"cluster_property_set>nvpair"`
@@ -19,6 +27,7 @@ type Root struct {
 			ID                 string      `xml:"id,attr" json:"Id"` //nolint
 			Uname              string      `xml:"uname,attr"`
 			InstanceAttributes []Attribute `xml:"instance_attributes>nvpair"`
+			Utilization        []Attribute `xml:"utilization>nvpair"`
 		} `xml:"nodes>node"`
 		Resources struct {
 			Primitives []Primitive `xml:"primitive"`
@@ -26,18 +35,145 @@ type Root struct {
 			Clones     []Clone     `xml:"clone"`
 			Groups     []Group     `xml:"group"`
 		} `xml:"resources"`
-		Constraints struct {
-			RscLocations []struct {
-				ID       string `xml:"id,attr" json:"Id"` //nolint
-				Node     string `xml:"node,attr"`
-				Resource string `xml:"rsc,attr"`
-				Role     string `xml:"role,attr"`
-				Score    string `xml:"score,attr"`
-			} `xml:"rsc_location"`
-		} `xml:"constraints"`
+		Constraints     Constraints    `xml:"constraints"`
+		RscDefaults     []Attribute    `xml:"rsc_defaults>meta_attributes>nvpair"`
+		OpDefaults      []Attribute    `xml:"op_defaults>meta_attributes>nvpair"`
+		FencingTopology []FencingLevel `xml:"fencing-topology>fencing-level"`
+		Alerts          []Alert        `xml:"alerts>alert"`
 	} `xml:"configuration"`
 }
 
+const (
+	DefaultOperationTimeout = 20 * time.Second
+	opDefaultsTimeout       = "timeout"
+)
+
+const (
+	// crm_resource --ban and --move create these location constraints, which stay until they are cleared
+	CliBanPrefix    = "cli-ban-"
+	CliPreferPrefix = "cli-prefer-"
+)
+
+type Constraints struct {
+	RscLocations   []RscLocation   `xml:"rsc_location"`
+	RscColocations []RscColocation `xml:"rsc_colocation"`
+	RscOrders      []RscOrder      `xml:"rsc_order"`
+	RscTickets     []RscTicket     `xml:"rsc_ticket"`
+}
+
+type RscLocation struct {
+	ID                string        `xml:"id,attr" json:"Id"` //nolint
+	Node              string        `xml:"node,attr"`
+	Resource          string        `xml:"rsc,attr"`
+	ResourcePattern   string        `xml:"rsc-pattern,attr"`
+	Role              string        `xml:"role,attr"`
+	Score             string        `xml:"score,attr"`
+	ResourceDiscovery string        `xml:"resource-discovery,attr"`
+	Rules             []Rule        `xml:"rule"`
+	ResourceSets      []ResourceSet `xml:"resource_set"`
+}
+
+type RscColocation struct {
+	ID               string        `xml:"id,attr" json:"Id"` //nolint
+	Score            string        `xml:"score,attr"`
+	Resource         string        `xml:"rsc,attr"`
+	ResourceRole     string        `xml:"rsc-role,attr"`
+	WithResource     string        `xml:"with-rsc,attr"`
+	WithResourceRole string        `xml:"with-rsc-role,attr"`
+	NodeAttribute    string        `xml:"node-attribute,attr"`
+	ResourceSets     []ResourceSet `xml:"resource_set"`
+}
+
+type RscOrder struct {
+	ID           string        `xml:"id,attr" json:"Id"` //nolint
+	First        string        `xml:"first,attr"`
+	FirstAction  string        `xml:"first-action,attr"`
+	Then         string        `xml:"then,attr"`
+	ThenAction   string        `xml:"then-action,attr"`
+	Kind         string        `xml:"kind,attr"`
+	Symmetrical  string        `xml:"symmetrical,attr"`
+	Score        string        `xml:"score,attr"`
+	ResourceSets []ResourceSet `xml:"resource_set"`
+}
+
+type RscTicket struct {
+	ID           string        `xml:"id,attr" json:"Id"` //nolint
+	Ticket       string        `xml:"ticket,attr"`
+	Resource     string        `xml:"rsc,attr"`
+	ResourceRole string        `xml:"rsc-role,attr"`
+	LossPolicy   string        `xml:"loss-policy,attr"`
+	ResourceSets []ResourceSet `xml:"resource_set"`
+}
+
+type ResourceSet struct {
+	ID         string `xml:"id,attr" json:"Id"` //nolint
+	Sequential string `xml:"sequential,attr"`
+	RequireAll string `xml:"require-all,attr"`
+	Ordering   string `xml:"ordering,attr"`
+	Action     string `xml:"action,attr"`
+	Role       string `xml:"role,attr"`
+	Score      string `xml:"score,attr"`
+	Kind       string `xml:"kind,attr"`
+	Resources  []struct {
+		ID string `xml:"id,attr" json:"Id"` //nolint
+	} `xml:"resource_ref"`
+}
+
+// Rule is a location rule, the expressions are combined with the boolean operation
+// and rules can be nested
+type Rule struct {
+	ID              string           `xml:"id,attr" json:"Id"` //nolint
+	Score           string           `xml:"score,attr"`
+	ScoreAttribute  string           `xml:"score-attribute,attr"`
+	BooleanOp       string           `xml:"boolean-op,attr"`
+	Role            string           `xml:"role,attr"`
+	Expressions     []Expression     `xml:"expression"`
+	DateExpressions []DateExpression `xml:"date_expression"`
+	Rules           []Rule           `xml:"rule"`
+}
+
+type Expression struct {
+	ID          string `xml:"id,attr" json:"Id"` //nolint
+	Attribute   string `xml:"attribute,attr"`
+	Operation   string `xml:"operation,attr"`
+	Value       string `xml:"value,attr"`
+	Type        string `xml:"type,attr"`
+	ValueSource string `xml:"value-source,attr"`
+}
+
+type DateExpression struct {
+	ID        string `xml:"id,attr" json:"Id"` //nolint
+	Operation string `xml:"operation,attr"`
+	Start     string `xml:"start,attr"`
+	End       string `xml:"end,attr"`
+}
+
+// FencingLevel is a fencing-topology level. The target is a node name, a node name
+// pattern or a node attribute, and the devices are tried in the index order
+type FencingLevel struct {
+	ID              string `xml:"id,attr" json:"Id"` //nolint
+	Index           int    `xml:"index,attr"`
+	Target          string `xml:"target,attr"`
+	TargetPattern   string `xml:"target-pattern,attr"`
+	TargetAttribute string `xml:"target-attribute,attr"`
+	TargetValue     string `xml:"target-value,attr"`
+	Devices         string `xml:"devices,attr"`
+}
+
+type Alert struct {
+	ID                 string      `xml:"id,attr" json:"Id"` //nolint
+	Path               string      `xml:"path,attr"`
+	Description        string      `xml:"description,attr"`
+	InstanceAttributes []Attribute `xml:"instance_attributes>nvpair"`
+	MetaAttributes     []Attribute `xml:"meta_attributes>nvpair"`
+	Recipients         []struct {
+		ID                 string      `xml:"id,attr" json:"Id"` //nolint
+		Value              string      `xml:"value,attr"`
+		InstanceAttributes []Attribute `xml:"instance_attributes>nvpair"`
+		MetaAttributes     []Attribute `xml:"meta_attributes>nvpair"`
+	} `xml:"recipient"`
+}
+
 type Attribute struct {
 	ID    string `xml:"id,attr" json:"Id"` //nolint
 	Name  string `xml:"name,attr"`
@@ -51,14 +187,15 @@ type Primitive struct {
 	Provider           string      `xml:"provider,attr"`
 	InstanceAttributes []Attribute `xml:"instance_attributes>nvpair"`
 	MetaAttributes     []Attribute `xml:"meta_attributes>nvpair"`
-	Operations         []struct {
-		ID   string `xml:"id,attr" json:"Id"` //nolint
-		Name string `xml:"name,attr"`
-		Role string `xml:"role,attr"`
-		// todo: interval and timeout are time based vars. We should in future parse them correctly insteaf of string
-		Interval string `xml:"interval,attr"`
-		Timeout  string `xml:"timeout,attr"`
-	} `xml:"operations>op"`
+	Operations         []Operation `xml:"operations>op"`
+}
+
+type Operation struct {
+	ID       string   `xml:"id,attr" json:"Id"` //nolint
+	Name     string   `xml:"name,attr"`
+	Role     string   `xml:"role,attr"`
+	Interval Duration `xml:"interval,attr"`
+	Timeout  Duration `xml:"timeout,attr"`
 }
 
 type Clone struct {
@@ -71,3 +208,87 @@ type Group struct {
 	ID         string      `xml:"id,attr" json:"Id"` //nolint
 	Primitives []Primitive `xml:"primitive"`
 }
+
+// DeviceList returns the fencing devices of the level
+func (l *FencingLevel) DeviceList() []string {
+	if l.Devices == "" {
+		return []string{}
+	}
+	return strings.Split(l.Devices, ",")
+}
+
+// Agent returns the resource agent of the primitive in the class:provider:type format,
+// or class:type for the classes without provider, like stonith
+func (p *Primitive) Agent() string {
+	if p.Provider == "" {
+		return p.Class + ":" + p.Type
+	}
+	return p.Class + ":" + p.Provider + ":" + p.Type
+}
+
+// Primitives returns all the configured primitives, including the ones in groups, clones and masters
+func (r *Root) Primitives() []Primitive {
+	resources := r.Configuration.Resources
+
+	primitives := append([]Primitive{}, resources.Primitives...)
+	for _, group := range resources.Groups {
+		primitives = append(primitives, group.Primitives...)
+	}
+	for _, clone := range append(append([]Clone{}, resources.Clones...), resources.Masters...) {
+		primitives = append(primitives, clone.Primitive)
+	}
+
+	return primitives
+}
+
+func (r *Root) FindPrimitive(id string) (Primitive, bool) {
+	for _, primitive := range r.Primitives() {
+		if primitive.ID == id {
+			return primitive, true
+		}
+	}
+	return Primitive{}, false
+}
+
+// IsMigrationConstraint returns true for the location constraints left by crm_resource --ban and --move
+func (l *RscLocation) IsMigrationConstraint() bool {
+	return strings.HasPrefix(l.ID, CliBanPrefix) || strings.HasPrefix(l.ID, CliPreferPrefix)
+}
+
+// MigrationConstraints returns the location constraints left by crm_resource --ban and --move.
+// They stay in the configuration until they are cleared, pinning or banning the resource
+func (c *Constraints) MigrationConstraints() []RscLocation {
+	constraints := []RscLocation{}
+	for _, location := range c.RscLocations {
+		if location.IsMigrationConstraint() {
+			constraints = append(constraints, location)
+		}
+	}
+	return constraints
+}
+
+// OperationTimeout returns the effective timeout of the primitive operation. The operation timeout
+// is used if it's set, otherwise the op_defaults timeout, falling back to the Pacemaker default.
+// The role selects the operation only if it's not empty, as monitor operations can be defined per role
+func (r *Root) OperationTimeout(primitive *Primitive, name, role string) time.Duration {
+	for _, operation := range primitive.Operations {
+		if operation.Name != name || (role != "" && operation.Role != role) {
+			continue
+		}
+		if operation.Timeout.Valid {
+			return operation.Timeout.Value
+		}
+		break
+	}
+
+	for _, attribute := range r.Configuration.OpDefaults {
+		if attribute.Name != opDefaultsTimeout {
+			continue
+		}
+		if timeout, err := ParseDuration(attribute.Value); err == nil {
+			return timeout
+		}
+	}
+
+	return DefaultOperationTimeout
+}
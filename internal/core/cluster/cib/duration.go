@@ -0,0 +1,134 @@
+package cib
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nolint:gochecknoglobals
+var (
+	durationRegexp = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([a-z]*)$`)
+	// ISO8601 durations, like PT10M or P1DT12H. Years and months are not allowed in the time part
+	iso8601DurationRegexp = regexp.MustCompile(
+		`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+)
+
+// nolint:gochecknoglobals
+var durationUnits = map[string]time.Duration{
+	"":     time.Second,
+	"us":   time.Microsecond,
+	"usec": time.Microsecond,
+	"ms":   time.Millisecond,
+	"msec": time.Millisecond,
+	"s":    time.Second,
+	"sec":  time.Second,
+	"m":    time.Minute,
+	"min":  time.Minute,
+	"h":    time.Hour,
+	"hr":   time.Hour,
+}
+
+// Duration is a Pacemaker time specification, like the operation interval and timeout.
+// The configured value is kept as is, and published in the same way, as the same duration
+// can be written in many ways
+type Duration struct {
+	Raw   string
+	Value time.Duration
+	// Valid is false if the value is not set or it is not a valid Pacemaker duration
+	Valid bool
+}
+
+// ParseDuration parses a Pacemaker time specification. Values without unit are seconds,
+// and the ms, msec, us, usec, s, sec, m, min, h and hr units are accepted, as well as ISO8601
+// durations. ISO8601 years and months are counted as 365 and 30 days
+func ParseDuration(spec string) (time.Duration, error) {
+	value := strings.ToLower(strings.TrimSpace(spec))
+
+	if strings.HasPrefix(value, "p") {
+		return parseISO8601Duration(strings.ToUpper(value))
+	}
+
+	match := durationRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("invalid duration: %s", spec)
+	}
+
+	unit, found := durationUnits[match[2]]
+	if !found {
+		return 0, fmt.Errorf("invalid duration unit: %s", spec)
+	}
+
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", spec)
+	}
+
+	return time.Duration(number * float64(unit)), nil
+}
+
+func parseISO8601Duration(spec string) (time.Duration, error) {
+	match := iso8601DurationRegexp.FindStringSubmatch(spec)
+	if match == nil || spec == "P" || strings.HasSuffix(spec, "T") {
+		return 0, fmt.Errorf("invalid ISO8601 duration: %s", spec)
+	}
+
+	day := 24 * time.Hour
+	units := []time.Duration{365 * day, 30 * day, 7 * day, day, time.Hour, time.Minute, time.Second}
+
+	var duration time.Duration
+	for index, unit := range units {
+		if match[index+1] == "" {
+			continue
+		}
+		number, err := strconv.ParseFloat(match[index+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO8601 duration: %s", spec)
+		}
+		duration += time.Duration(number * float64(unit))
+	}
+
+	return duration, nil
+}
+
+func NewDuration(spec string) Duration {
+	value, err := ParseDuration(spec)
+	return Duration{
+		Raw:   spec,
+		Value: value,
+		Valid: err == nil,
+	}
+}
+
+// IsSet returns true if the duration is configured, even with an invalid value
+func (d Duration) IsSet() bool {
+	return d.Raw != ""
+}
+
+func (d Duration) String() string {
+	return d.Raw
+}
+
+// UnmarshalXMLAttr doesn't fail with invalid values, so a wrong duration doesn't prevent
+// the rest of the CIB from being parsed. Pacemaker itself rejects them
+func (d *Duration) UnmarshalXMLAttr(attr xml.Attr) error {
+	*d = NewDuration(attr.Value)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Raw)
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*d = NewDuration(raw)
+	return nil
+}
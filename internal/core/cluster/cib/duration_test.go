@@ -0,0 +1,84 @@
+package cib
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DurationTestSuite struct {
+	suite.Suite
+}
+
+func TestDurationTestSuite(t *testing.T) {
+	suite.Run(t, new(DurationTestSuite))
+}
+
+func (suite *DurationTestSuite) TestParseDuration() {
+	cases := map[string]time.Duration{
+		"0":        0,
+		"20":       20 * time.Second,
+		"20s":      20 * time.Second,
+		"20 sec":   20 * time.Second,
+		"1.5s":     1500 * time.Millisecond,
+		"500ms":    500 * time.Millisecond,
+		"500msec":  500 * time.Millisecond,
+		"250us":    250 * time.Microsecond,
+		"10m":      10 * time.Minute,
+		"10min":    10 * time.Minute,
+		"2h":       2 * time.Hour,
+		"2HR":      2 * time.Hour,
+		" 30s ":    30 * time.Second,
+		"PT10M":    10 * time.Minute,
+		"PT1H30M":  90 * time.Minute,
+		"PT0.5S":   500 * time.Millisecond,
+		"P1D":      24 * time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"P1DT12H":  36 * time.Hour,
+		"P1M":      30 * 24 * time.Hour,
+		"P1Y":      365 * 24 * time.Hour,
+		"pt90s":    90 * time.Second,
+		"PT2H0M0S": 2 * time.Hour,
+	}
+
+	for spec, expected := range cases {
+		duration, err := ParseDuration(spec)
+		suite.NoError(err, spec)
+		suite.Equal(expected, duration, spec)
+	}
+}
+
+func (suite *DurationTestSuite) TestParseDurationInvalid() {
+	for _, spec := range []string{"", "s", "10 years", "-10s", "P", "PT", "P1H", "1d", "INFINITY"} {
+		_, err := ParseDuration(spec)
+		suite.Error(err, spec)
+	}
+}
+
+func (suite *DurationTestSuite) TestDuration() {
+	duration := NewDuration("90s")
+	suite.True(duration.IsSet())
+	suite.True(duration.Valid)
+	suite.Equal(90*time.Second, duration.Value)
+	suite.Equal("90s", duration.String())
+
+	invalid := NewDuration("soon")
+	suite.True(invalid.IsSet())
+	suite.False(invalid.Valid)
+
+	unset := Duration{}
+	suite.False(unset.IsSet())
+	suite.False(unset.Valid)
+}
+
+func (suite *DurationTestSuite) TestDurationJSON() {
+	marshalled, err := json.Marshal(NewDuration("PT5M"))
+	suite.NoError(err)
+	suite.Equal(`"PT5M"`, string(marshalled))
+
+	var duration Duration
+	suite.NoError(json.Unmarshal([]byte(`"5min"`), &duration))
+	suite.Equal(NewDuration("5min"), duration)
+}
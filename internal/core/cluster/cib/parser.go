@@ -26,6 +26,24 @@ func (p *Parser) Parse() (Root, error) {
 	return CIB, nil
 }
 
+// ParseVersion queries only the cib element, without the configuration and the status,
+// so it is cheap enough to be run often to detect the CIB changes
+func (p *Parser) ParseVersion() (Version, error) {
+	var version Version
+	cibXML, err := exec.Command( //nolint:gosec
+		p.cibAdminPath, "--query", "--local", "--xpath", "/cib", "--no-children").Output()
+	if err != nil {
+		return version, errors.Wrap(err, "error while executing cibadmin")
+	}
+
+	err = xml.Unmarshal(cibXML, &version)
+	if err != nil {
+		return version, errors.Wrap(err, "could not parse cibadmin version from XML")
+	}
+
+	return version, nil
+}
+
 func NewCibAdminParser(cibAdminPath string) *Parser {
 	return &Parser{cibAdminPath: cibAdminPath}
 }
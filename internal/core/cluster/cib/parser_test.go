@@ -3,6 +3,7 @@ package cib
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/agent/test/helpers"
@@ -57,28 +58,165 @@ func (suite *ParserTestSuite) TestParse() {
 	suite.Equal(5, len(data.Configuration.Resources.Masters[0].Primitive.Operations))
 	suite.Equal("rsc_SAPHana_PRD_HDB00-start-0", data.Configuration.Resources.Masters[0].Primitive.Operations[0].ID)
 	suite.Equal("start", data.Configuration.Resources.Masters[0].Primitive.Operations[0].Name)
-	suite.Equal("0", data.Configuration.Resources.Masters[0].Primitive.Operations[0].Interval)
-	suite.Equal("3600", data.Configuration.Resources.Masters[0].Primitive.Operations[0].Timeout)
+	suite.Equal(time.Duration(0), data.Configuration.Resources.Masters[0].Primitive.Operations[0].Interval.Value)
+	suite.Equal(3600*time.Second, data.Configuration.Resources.Masters[0].Primitive.Operations[0].Timeout.Value)
 	suite.Equal("rsc_SAPHana_PRD_HDB00-stop-0", data.Configuration.Resources.Masters[0].Primitive.Operations[1].ID)
 	suite.Equal("stop", data.Configuration.Resources.Masters[0].Primitive.Operations[1].Name)
-	suite.Equal("0", data.Configuration.Resources.Masters[0].Primitive.Operations[1].Interval)
-	suite.Equal("3600", data.Configuration.Resources.Masters[0].Primitive.Operations[1].Timeout)
+	suite.Equal(time.Duration(0), data.Configuration.Resources.Masters[0].Primitive.Operations[1].Interval.Value)
+	suite.Equal(3600*time.Second, data.Configuration.Resources.Masters[0].Primitive.Operations[1].Timeout.Value)
 	suite.Equal("rsc_SAPHana_PRD_HDB00-promote-0", data.Configuration.Resources.Masters[0].Primitive.Operations[2].ID)
 	suite.Equal("promote", data.Configuration.Resources.Masters[0].Primitive.Operations[2].Name)
-	suite.Equal("0", data.Configuration.Resources.Masters[0].Primitive.Operations[2].Interval)
-	suite.Equal("3600", data.Configuration.Resources.Masters[0].Primitive.Operations[2].Timeout)
+	suite.Equal(time.Duration(0), data.Configuration.Resources.Masters[0].Primitive.Operations[2].Interval.Value)
+	suite.Equal(3600*time.Second, data.Configuration.Resources.Masters[0].Primitive.Operations[2].Timeout.Value)
 	suite.Equal("rsc_SAPHana_PRD_HDB00-monitor-60", data.Configuration.Resources.Masters[0].Primitive.Operations[3].ID)
 	suite.Equal("monitor", data.Configuration.Resources.Masters[0].Primitive.Operations[3].Name)
 	suite.Equal("Master", data.Configuration.Resources.Masters[0].Primitive.Operations[3].Role)
-	suite.Equal("60", data.Configuration.Resources.Masters[0].Primitive.Operations[3].Interval)
-	suite.Equal("700", data.Configuration.Resources.Masters[0].Primitive.Operations[3].Timeout)
+	suite.Equal(60*time.Second, data.Configuration.Resources.Masters[0].Primitive.Operations[3].Interval.Value)
+	suite.Equal(700*time.Second, data.Configuration.Resources.Masters[0].Primitive.Operations[3].Timeout.Value)
 	suite.Equal("rsc_SAPHana_PRD_HDB00-monitor-61", data.Configuration.Resources.Masters[0].Primitive.Operations[4].ID)
 	suite.Equal("monitor", data.Configuration.Resources.Masters[0].Primitive.Operations[4].Name)
 	suite.Equal("Slave", data.Configuration.Resources.Masters[0].Primitive.Operations[4].Role)
-	suite.Equal("61", data.Configuration.Resources.Masters[0].Primitive.Operations[4].Interval)
-	suite.Equal("700", data.Configuration.Resources.Masters[0].Primitive.Operations[4].Timeout)
+	suite.Equal(61*time.Second, data.Configuration.Resources.Masters[0].Primitive.Operations[4].Interval.Value)
+	suite.Equal(700*time.Second, data.Configuration.Resources.Masters[0].Primitive.Operations[4].Timeout.Value)
 	suite.Equal("test", data.Configuration.Resources.Primitives[2].ID)
 	suite.Equal("ocf", data.Configuration.Resources.Primitives[2].Class)
 	suite.Equal("heartbeat", data.Configuration.Resources.Primitives[2].Provider)
 	suite.Equal("Dummy", data.Configuration.Resources.Primitives[2].Type)
 }
+
+func (suite *ParserTestSuite) TestParseConstraints() {
+	p := NewCibAdminParser(helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"))
+	data, err := p.Parse()
+	suite.NoError(err)
+
+	constraints := data.Configuration.Constraints
+
+	suite.Equal(5, len(constraints.RscLocations))
+	suite.Equal("cli-prefer-msl_SAPHana_PRD_HDB00", constraints.RscLocations[0].ID)
+	suite.Equal("node01", constraints.RscLocations[0].Node)
+	suite.Equal("INFINITY", constraints.RscLocations[0].Score)
+	suite.True(constraints.RscLocations[0].IsMigrationConstraint())
+	suite.False(constraints.RscLocations[3].IsMigrationConstraint())
+
+	location := constraints.RscLocations[4]
+	suite.Equal("loc_ip_PRD_HDB00_not_on_maintenance", location.ID)
+	suite.Equal("rsc_ip_PRD_HDB00", location.Resource)
+	suite.Equal(1, len(location.Rules))
+	suite.Equal("-INFINITY", location.Rules[0].Score)
+	suite.Equal("or", location.Rules[0].BooleanOp)
+	suite.Equal(2, len(location.Rules[0].Expressions))
+	suite.Equal("maintenance", location.Rules[0].Expressions[0].Attribute)
+	suite.Equal("eq", location.Rules[0].Expressions[0].Operation)
+	suite.Equal("true", location.Rules[0].Expressions[0].Value)
+	suite.Equal("#uname", location.Rules[0].Expressions[1].Attribute)
+	suite.Equal("not_defined", location.Rules[0].Expressions[1].Operation)
+
+	suite.Equal(2, len(constraints.RscColocations))
+	suite.Equal("col_saphana_ip_PRD_HDB00", constraints.RscColocations[0].ID)
+	suite.Equal("2000", constraints.RscColocations[0].Score)
+	suite.Equal("rsc_ip_PRD_HDB00", constraints.RscColocations[0].Resource)
+	suite.Equal("Started", constraints.RscColocations[0].ResourceRole)
+	suite.Equal("msl_SAPHana_PRD_HDB00", constraints.RscColocations[0].WithResource)
+	suite.Equal("Master", constraints.RscColocations[0].WithResourceRole)
+	suite.Equal("col_test_set", constraints.RscColocations[1].ID)
+	suite.Equal(1, len(constraints.RscColocations[1].ResourceSets))
+	suite.Equal("false", constraints.RscColocations[1].ResourceSets[0].Sequential)
+	suite.Equal(2, len(constraints.RscColocations[1].ResourceSets[0].Resources))
+	suite.Equal("test", constraints.RscColocations[1].ResourceSets[0].Resources[0].ID)
+	suite.Equal("test-stop", constraints.RscColocations[1].ResourceSets[0].Resources[1].ID)
+
+	suite.Equal(1, len(constraints.RscOrders))
+	suite.Equal("ord_SAPHana_PRD_HDB00", constraints.RscOrders[0].ID)
+	suite.Equal("Optional", constraints.RscOrders[0].Kind)
+	suite.Equal("cln_SAPHanaTopology_PRD_HDB00", constraints.RscOrders[0].First)
+	suite.Equal("msl_SAPHana_PRD_HDB00", constraints.RscOrders[0].Then)
+
+	suite.Equal(1, len(constraints.RscTickets))
+	suite.Equal("tkt_SAPHana_PRD_HDB00", constraints.RscTickets[0].ID)
+	suite.Equal("ticket-PRD", constraints.RscTickets[0].Ticket)
+	suite.Equal("msl_SAPHana_PRD_HDB00", constraints.RscTickets[0].Resource)
+	suite.Equal("Master", constraints.RscTickets[0].ResourceRole)
+	suite.Equal("demote", constraints.RscTickets[0].LossPolicy)
+}
+
+func (suite *ParserTestSuite) TestMigrationConstraints() {
+	p := NewCibAdminParser(helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"))
+	data, err := p.Parse()
+	suite.NoError(err)
+
+	migrationConstraints := data.Configuration.Constraints.MigrationConstraints()
+
+	suite.Equal(3, len(migrationConstraints))
+	suite.Equal("cli-prefer-msl_SAPHana_PRD_HDB00", migrationConstraints[0].ID)
+	suite.Equal("cli-prefer-cln_SAPHanaTopology_PRD_HDB00", migrationConstraints[1].ID)
+	suite.Equal("cli-ban-msl_SAPHana_PRD_HDB00-on-node01", migrationConstraints[2].ID)
+	suite.Equal("-INFINITY", migrationConstraints[2].Score)
+}
+
+func (suite *ParserTestSuite) TestParseDefaultsAndTopology() {
+	p := NewCibAdminParser(helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"))
+	data, err := p.Parse()
+	suite.NoError(err)
+
+	configuration := data.Configuration
+
+	suite.Equal(2, len(configuration.RscDefaults))
+	suite.Equal("resource-stickiness", configuration.RscDefaults[0].Name)
+	suite.Equal("1000", configuration.RscDefaults[0].Value)
+	suite.Equal(2, len(configuration.OpDefaults))
+	suite.Equal("timeout", configuration.OpDefaults[0].Name)
+	suite.Equal("600", configuration.OpDefaults[0].Value)
+
+	suite.Equal(2, len(configuration.Nodes[0].Utilization))
+	suite.Equal("cpu", configuration.Nodes[0].Utilization[0].Name)
+	suite.Equal("8", configuration.Nodes[0].Utilization[0].Value)
+	suite.Equal(0, len(configuration.Nodes[1].Utilization))
+
+	suite.Equal(2, len(configuration.FencingTopology))
+	suite.Equal("fencing-1", configuration.FencingTopology[0].ID)
+	suite.Equal(1, configuration.FencingTopology[0].Index)
+	suite.Equal("node01", configuration.FencingTopology[0].Target)
+	suite.Equal([]string{"stonith-sbd"}, configuration.FencingTopology[0].DeviceList())
+
+	suite.Equal(1, len(configuration.Alerts))
+	suite.Equal("alert_file", configuration.Alerts[0].ID)
+	suite.Equal("/usr/share/pacemaker/alerts/alert_file.sh.sample", configuration.Alerts[0].Path)
+	suite.Equal("timeout", configuration.Alerts[0].MetaAttributes[0].Name)
+	suite.Equal(1, len(configuration.Alerts[0].Recipients))
+	suite.Equal("/var/log/cluster-alerts.log", configuration.Alerts[0].Recipients[0].Value)
+}
+
+func (suite *ParserTestSuite) TestOperationTimeout() {
+	p := NewCibAdminParser(helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"))
+	data, err := p.Parse()
+	suite.NoError(err)
+
+	saphana, found := data.FindPrimitive("rsc_SAPHana_PRD_HDB00")
+	suite.True(found)
+
+	suite.Equal(time.Hour, data.OperationTimeout(&saphana, "start", ""))
+	suite.Equal(700*time.Second, data.OperationTimeout(&saphana, "monitor", "Slave"))
+	// demote is not configured in the resource, the op_defaults timeout applies
+	suite.Equal(600*time.Second, data.OperationTimeout(&saphana, "demote", ""))
+
+	data.Configuration.OpDefaults = []Attribute{}
+	suite.Equal(DefaultOperationTimeout, data.OperationTimeout(&saphana, "demote", ""))
+}
+
+func (suite *ParserTestSuite) TestParseVersion() {
+	p := NewCibAdminParser(helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"))
+	version, err := p.ParseVersion()
+	suite.NoError(err)
+	suite.Equal(Version{AdminEpoch: 0, Epoch: 6881, NumUpdates: 0}, version)
+	suite.Equal("0.6881.0", version.String())
+
+	data, err := p.Parse()
+	suite.NoError(err)
+	suite.Equal(version, data.Version())
+}
+
+func (suite *ParserTestSuite) TestParseVersionError() {
+	p := NewCibAdminParser("non_existing_cibadmin")
+	_, err := p.ParseVersion()
+	suite.Error(err)
+}
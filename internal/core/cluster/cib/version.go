@@ -0,0 +1,23 @@
+package cib
+
+import "fmt"
+
+// Version identifies a CIB revision. Pacemaker increases admin_epoch on administrative changes,
+// epoch on configuration changes and num_updates on status changes, like resources moving
+type Version struct {
+	AdminEpoch int `xml:"admin_epoch,attr"`
+	Epoch      int `xml:"epoch,attr"`
+	NumUpdates int `xml:"num_updates,attr"`
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.AdminEpoch, v.Epoch, v.NumUpdates)
+}
+
+func (r *Root) Version() Version {
+	return Version{
+		AdminEpoch: r.AdminEpoch,
+		Epoch:      r.Epoch,
+		NumUpdates: r.NumUpdates,
+	}
+}
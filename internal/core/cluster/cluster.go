@@ -22,29 +22,46 @@ const (
 	cibAdmPath             string = "/usr/sbin/cibadmin"
 	crmmonAdmPath          string = "/usr/sbin/crm_mon"
 	corosyncKeyPath        string = "/etc/corosync/authkey"
+	pacemakerKeyPath       string = "/etc/pacemaker/authkey"
 	clusterNameProperty    string = "cib-bootstrap-options-cluster-name"
 	stonithEnabled         string = "cib-bootstrap-options-stonith-enabled"
 	stonithResourceMissing string = "notconfigured"
 	stonithAgent           string = "stonith:"
 	sbdFencingAgentName    string = "external/sbd"
+	crmmonRemoteNodeType   string = "remote"
+)
+
+// Role of the discovered host in the cluster
+const (
+	MemberNode string = "member"
+	RemoteNode string = "remote"
+	GuestNode  string = "guest"
 )
 
 type DiscoveryTools struct {
-	CibAdmPath      string
-	CrmmonAdmPath   string
-	CorosyncKeyPath string
-	SBDPath         string
-	SBDConfigPath   string
+	CibAdmPath       string
+	CrmmonAdmPath    string
+	CorosyncKeyPath  string
+	PacemakerKeyPath string
+	SBDPath          string
+	SBDConfigPath    string
 }
 
 type Cluster struct {
 	Cib      cib.Root
 	Crmmon   crmmon.Root
 	SBD      SBD
+	Fencing  Fencing
 	ID       string `json:"Id"`
 	Name     string
 	DC       bool
 	Provider string
+	// Role is member for the corosync cluster members, or remote and guest for the pacemaker remote nodes
+	Role string
+	// Health is computed from the discovered data, so the server doesn't need to reimplement it
+	Health Health
+	// MigrationConstraints are the cli-ban and cli-prefer location constraints still in the configuration
+	MigrationConstraints []cib.RscLocation
 }
 
 func Md5sumFile(filePath string) (string, error) {
@@ -63,11 +80,12 @@ func Md5sumFile(filePath string) (string, error) {
 
 func NewCluster() (Cluster, error) {
 	return NewClusterWithDiscoveryTools(&DiscoveryTools{
-		CibAdmPath:      cibAdmPath,
-		CrmmonAdmPath:   crmmonAdmPath,
-		CorosyncKeyPath: corosyncKeyPath,
-		SBDPath:         SBDPath,
-		SBDConfigPath:   SBDConfigPath,
+		CibAdmPath:       cibAdmPath,
+		CrmmonAdmPath:    crmmonAdmPath,
+		CorosyncKeyPath:  corosyncKeyPath,
+		PacemakerKeyPath: pacemakerKeyPath,
+		SBDPath:          SBDPath,
+		SBDConfigPath:    SBDConfigPath,
 	})
 }
 
@@ -76,10 +94,15 @@ func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, erro
 		Cib:      cib.Root{},    //nolint
 		Crmmon:   crmmon.Root{}, //nolint
 		SBD:      SBD{},         //nolint
+		Fencing:  Fencing{},     //nolint
 		ID:       "",
 		Name:     clusterNameProperty,
 		DC:       false,
 		Provider: "",
+		Role:     MemberNode,
+		Health:   Health{}, //nolint
+
+		MigrationConstraints: []cib.RscLocation{},
 	}
 
 	commandExecutor := utils.Executor{}
@@ -91,6 +114,7 @@ func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, erro
 	}
 
 	cluster.Cib = cibConfig
+	cluster.MigrationConstraints = cibConfig.Configuration.Constraints.MigrationConstraints()
 
 	crmmonParser := crmmon.NewCrmMonParser(discoveryTools.CrmmonAdmPath)
 
@@ -100,25 +124,31 @@ func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, erro
 	}
 
 	cluster.Crmmon = crmmonConfig
+	cluster.Fencing = NewFencing(&cluster)
 
-	// Set MD5-hashed key based on the corosync auth key
-	cluster.ID, err = getCorosyncAuthkeyMd5(discoveryTools.CorosyncKeyPath)
+	clusterID, isRemote, err := getClusterID(discoveryTools)
 	if err != nil {
 		return cluster, err
 	}
+	cluster.ID = clusterID
 
 	cluster.Name = getName(cluster)
+	cluster.Role = getRole(&cluster, isRemote)
 
 	if cluster.IsFencingSBD() {
 		sbdData, err := NewSBD(commandExecutor, cluster.ID, discoveryTools.SBDPath, discoveryTools.SBDConfigPath)
-		if err != nil {
+		// remote nodes usually don't have the sbd devices available
+		if err != nil && cluster.Role == MemberNode {
 			return cluster, err
+		} else if err != nil {
+			log.Debugf("SBD data not available in the %s node: %s", cluster.Role, err)
 		}
 
 		cluster.SBD = sbdData
 	}
 
 	cluster.DC = isDC(&cluster)
+	cluster.Health = NewHealth(&cluster)
 
 	cloudIdentifier := cloud.NewIdentifier(commandExecutor)
 	provider, err := cloudIdentifier.IdentifyCloudProvider()
@@ -130,6 +160,29 @@ func NewClusterWithDiscoveryTools(discoveryTools *DiscoveryTools) (Cluster, erro
 	return cluster, nil
 }
 
+// CIBVersion returns the version of the local CIB, without querying the whole CIB
+func CIBVersion() (cib.Version, error) {
+	return cib.NewCibAdminParser(cibAdmPath).ParseVersion()
+}
+
+// getClusterID returns the MD5-hashed key of the cluster and if the host is a pacemaker remote or guest node.
+// The members use the corosync auth key. The remote nodes don't run corosync, so they don't have it,
+// and use the pacemaker auth key they share with the cluster instead
+func getClusterID(discoveryTools *DiscoveryTools) (string, bool, error) {
+	id, err := getCorosyncAuthkeyMd5(discoveryTools.CorosyncKeyPath)
+	if err == nil {
+		return id, false, nil
+	}
+
+	log.Debugf("Corosync auth key not available, looking for the pacemaker remote auth key: %s", err)
+	id, err = Md5sumFile(discoveryTools.PacemakerKeyPath)
+	if err != nil {
+		return "", true, err
+	}
+
+	return id, true, nil
+}
+
 func getCorosyncAuthkeyMd5(corosyncKeyPath string) (string, error) {
 	kp, err := Md5sumFile(corosyncKeyPath)
 	return kp, err
@@ -158,6 +211,31 @@ func isDC(c *Cluster) bool {
 	return false
 }
 
+// getRole returns the role of the host using the node type reported by crm_mon. Guest nodes are
+// remote nodes running in a resource of the cluster, so they have the container resource id
+func getRole(c *Cluster, isRemote bool) string {
+	host, _ := os.Hostname()
+
+	for _, node := range c.Crmmon.Nodes {
+		if node.Name != host {
+			continue
+		}
+		switch {
+		case node.Type == crmmonRemoteNodeType && node.IDAsResource != "":
+			return GuestNode
+		case node.Type == crmmonRemoteNodeType:
+			return RemoteNode
+		default:
+			return MemberNode
+		}
+	}
+
+	if isRemote {
+		return RemoteNode
+	}
+	return MemberNode
+}
+
 func (c *Cluster) IsFencingEnabled() bool {
 	for _, prop := range c.Cib.Configuration.CrmConfig.ClusterProperties {
 		if prop.ID == stonithEnabled {
@@ -169,7 +247,8 @@ func (c *Cluster) IsFencingEnabled() bool {
 		}
 	}
 
-	return false
+	// stonith-enabled is true by default in pacemaker
+	return true
 }
 
 func (c *Cluster) FencingResourceExists() bool {
@@ -178,6 +257,7 @@ func (c *Cluster) FencingResourceExists() bool {
 	return f != stonithResourceMissing
 }
 
+// FencingType returns the agent of the first fencing resource, the Fencing field lists all of them
 func (c *Cluster) FencingType() string {
 	for _, resource := range c.Crmmon.Resources {
 		if strings.HasPrefix(resource.Agent, stonithAgent) {
@@ -187,8 +267,13 @@ func (c *Cluster) FencingType() string {
 	return stonithResourceMissing
 }
 
+// IsFencingSBD returns true if any of the fencing resources uses SBD, as it can be combined with other agents
 func (c *Cluster) IsFencingSBD() bool {
-	f := c.FencingType()
+	for _, resource := range c.Crmmon.Resources {
+		if resource.Agent == stonithAgent+sbdFencingAgentName {
+			return true
+		}
+	}
 
-	return f == sbdFencingAgentName
+	return false
 }
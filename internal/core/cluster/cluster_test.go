@@ -166,6 +166,14 @@ func (suite *ClusterTestSuite) TestIsFencingEnabled() {
 	}
 
 	suite.Equal(false, c.IsFencingEnabled())
+
+	root.Configuration.CrmConfig.ClusterProperties = []cib.Attribute{}
+
+	c = Cluster{
+		Cib: *root,
+	}
+
+	suite.Equal(true, c.IsFencingEnabled())
 }
 
 func (suite *ClusterTestSuite) TestFencingType() {
@@ -250,4 +258,106 @@ func (suite *ClusterTestSuite) TestIsFencingSBD() {
 	}
 
 	suite.Equal(false, c.IsFencingSBD())
+
+	c = Cluster{
+		Crmmon: crmmon.Root{
+			Version: "1.2.3",
+			Resources: []crmmon.Resource{
+				{
+					Agent: "stonith:fence_azure_arm",
+				},
+				{
+					Agent: "stonith:external/sbd",
+				},
+			},
+		},
+	}
+
+	suite.Equal(true, c.IsFencingSBD())
+}
+
+func (suite *ClusterTestSuite) TestGetRole() {
+	host, _ := os.Hostname()
+
+	cases := []struct {
+		node     crmmon.Node
+		isRemote bool
+		expected string
+	}{
+		{crmmon.Node{Name: host, Type: "member"}, false, MemberNode},
+		{crmmon.Node{Name: host, Type: "remote"}, true, RemoteNode},
+		{crmmon.Node{Name: host, Type: "remote", IDAsResource: "vm-guest01"}, true, GuestNode},
+		{crmmon.Node{Name: "othernode", Type: "member"}, true, RemoteNode},
+		{crmmon.Node{Name: "othernode", Type: "remote"}, false, MemberNode},
+	}
+
+	for _, tc := range cases {
+		c := &Cluster{
+			Crmmon: crmmon.Root{
+				Version: "1.2.3",
+				Nodes:   []crmmon.Node{tc.node},
+			},
+		}
+
+		suite.Equal(tc.expected, getRole(c, tc.isRemote))
+	}
+}
+
+func (suite *ClusterTestSuite) TestNewClusterRemoteNode() {
+	c, err := NewClusterWithDiscoveryTools(&DiscoveryTools{
+		CibAdmPath:       helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"),
+		CrmmonAdmPath:    helpers.GetFixturePath("discovery/cluster/fake_crm_mon.sh"),
+		CorosyncKeyPath:  "non_existing_authkey",
+		PacemakerKeyPath: helpers.GetFixturePath("discovery/cluster/pacemaker_authkey"),
+		SBDPath:          helpers.GetFixturePath("discovery/cluster/fake_sbd.sh"),
+		SBDConfigPath:    "non_existing_sbd_config",
+	})
+
+	suite.NoError(err)
+	suite.Equal("cdec37a0f3e2586515e7a2deedc680a4", c.ID)
+	suite.Equal("hana_cluster", c.Name)
+	suite.Equal(RemoteNode, c.Role)
+}
+
+func (suite *ClusterTestSuite) TestNewClusterMemberWithPacemakerAuthkey() {
+	c, err := NewClusterWithDiscoveryTools(&DiscoveryTools{
+		CibAdmPath:       helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"),
+		CrmmonAdmPath:    helpers.GetFixturePath("discovery/cluster/fake_crm_mon.sh"),
+		CorosyncKeyPath:  helpers.GetFixturePath("discovery/cluster/authkey"),
+		PacemakerKeyPath: helpers.GetFixturePath("discovery/cluster/pacemaker_authkey"),
+		SBDPath:          helpers.GetFixturePath("discovery/cluster/fake_sbd.sh"),
+		SBDConfigPath:    helpers.GetFixturePath("discovery/cluster/sbd/sbd_config"),
+	})
+
+	suite.NoError(err)
+	suite.Equal("47d1190ffb4f781974c8356d7f863b03", c.ID)
+	suite.Equal(MemberNode, c.Role)
+}
+
+func (suite *ClusterTestSuite) TestNewClusterMemberWithoutPacemakerAuthkey() {
+	c, err := NewClusterWithDiscoveryTools(&DiscoveryTools{
+		CibAdmPath:       helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"),
+		CrmmonAdmPath:    helpers.GetFixturePath("discovery/cluster/fake_crm_mon.sh"),
+		CorosyncKeyPath:  helpers.GetFixturePath("discovery/cluster/authkey"),
+		PacemakerKeyPath: "non_existing_pacemaker_authkey",
+		SBDPath:          helpers.GetFixturePath("discovery/cluster/fake_sbd.sh"),
+		SBDConfigPath:    helpers.GetFixturePath("discovery/cluster/sbd/sbd_config"),
+	})
+
+	suite.NoError(err)
+	suite.Equal("47d1190ffb4f781974c8356d7f863b03", c.ID)
+	suite.Equal(MemberNode, c.Role)
+}
+
+func (suite *ClusterTestSuite) TestNewClusterNoAuthkey() {
+	_, err := NewClusterWithDiscoveryTools(&DiscoveryTools{
+		CibAdmPath:       helpers.GetFixturePath("discovery/cluster/fake_cibadmin.sh"),
+		CrmmonAdmPath:    helpers.GetFixturePath("discovery/cluster/fake_crm_mon.sh"),
+		CorosyncKeyPath:  "non_existing_authkey",
+		PacemakerKeyPath: "non_existing_pacemaker_authkey",
+		SBDPath:          helpers.GetFixturePath("discovery/cluster/fake_sbd.sh"),
+		SBDConfigPath:    helpers.GetFixturePath("discovery/cluster/sbd/sbd_config"),
+	})
+
+	suite.Error(err)
 }
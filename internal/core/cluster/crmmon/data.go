@@ -31,14 +31,7 @@ type Root struct {
 		} `xml:"node"`
 	} `xml:"node_attributes"`
 	NodeHistory struct {
-		Nodes []struct {
-			Name            string `xml:"name,attr"`
-			ResourceHistory []struct {
-				Name               string `xml:"id,attr" json:"Name"`
-				MigrationThreshold int    `xml:"migration-threshold,attr"`
-				FailCount          int    `xml:"fail-count,attr"`
-			} `xml:"resource_history"`
-		} `xml:"node"`
+		Nodes []NodeHistory `xml:"node"`
 	} `xml:"node_history"`
 	Resources []Resource `xml:"resources>resource"`
 	Clones    []Clone    `xml:"resources>clone"`
@@ -59,6 +52,19 @@ type Node struct {
 	DC               bool   `xml:"is_dc,attr"`
 	ResourcesRunning int    `xml:"resources_running,attr"`
 	Type             string `xml:"type,attr"`
+	// IDAsResource is the resource running the guest node, only set for guest nodes
+	IDAsResource string `xml:"id_as_resource,attr"`
+}
+
+type NodeHistory struct {
+	Name            string            `xml:"name,attr"`
+	ResourceHistory []ResourceHistory `xml:"resource_history"`
+}
+
+type ResourceHistory struct {
+	Name               string `xml:"id,attr" json:"Name"`
+	MigrationThreshold int    `xml:"migration-threshold,attr"`
+	FailCount          int    `xml:"fail-count,attr"`
 }
 
 type Resource struct {
@@ -0,0 +1,213 @@
+package cluster
+
+import (
+	"strings"
+
+	"github.com/trento-project/agent/internal/core/cluster/cib"
+)
+
+const (
+	stonithClass                 string = "stonith"
+	stonithTimeoutProperty       string = "stonith-timeout"
+	priorityFencingDelayProperty string = "priority-fencing-delay"
+
+	pcmkParameterPrefix  string = "pcmk_"
+	pcmkTimeoutSuffix    string = "_timeout"
+	pcmkHostListParam    string = "pcmk_host_list"
+	pcmkHostMapParam     string = "pcmk_host_map"
+	pcmkHostCheckParam   string = "pcmk_host_check"
+	pcmkDelayBaseParam   string = "pcmk_delay_base"
+	pcmkDelayMaxParam    string = "pcmk_delay_max"
+	staticListHostCheck  string = "static-list"
+	dynamicListHostCheck string = "dynamic-list"
+
+	azureFencingAgent   string = "fence_azure_arm"
+	azureMSIParam       string = "msi"
+	hostListSeparators  string = " ,;"
+	hostMapPairSplitter string = ":"
+)
+
+// Authentication used by the fence_azure_arm agent
+const (
+	AzureManagedIdentity  string = "managed_identity"
+	AzureServicePrincipal string = "service_principal"
+)
+
+// Fencing gathers the fencing configuration of the cluster: the cluster wide fencing timeouts
+// and all the configured fencing resources, not only the SBD ones
+type Fencing struct {
+	// Timeout is the stonith-timeout cluster property
+	Timeout cib.Duration
+	// PriorityDelay is the priority-fencing-delay cluster property
+	PriorityDelay cib.Duration
+	Resources     []FencingResource
+}
+
+type FencingResource struct {
+	ID    string `json:"Id"` //nolint
+	Agent string
+	// Parameters are the instance attributes of the resource. The credentials are redacted when publishing
+	Parameters map[string]string
+	// HostCheck is how the fencer finds the nodes the device can fence, pcmk_host_check
+	HostCheck string
+	// Nodes are the nodes the device can fence. With a dynamic host check the device
+	// is asked at fencing time, so all the cluster nodes are reported
+	Nodes []string
+	// Timeouts are the pcmk_<action>_timeout parameters by action, like reboot or monitor
+	Timeouts  map[string]cib.Duration
+	DelayBase cib.Duration
+	// NodesDelayBase is set when pcmk_delay_base has a different delay for each node, like node1:0;node2:10s
+	NodesDelayBase map[string]cib.Duration
+	DelayMax       cib.Duration
+	// AzureAuthentication is managed_identity or service_principal for the fence_azure_arm resources
+	AzureAuthentication string
+}
+
+// NewFencing lists the fencing resources of the cluster from the CIB, including the ones in groups and clones
+func NewFencing(c *Cluster) Fencing {
+	fencing := Fencing{
+		Timeout:       cib.Duration{},
+		PriorityDelay: cib.Duration{},
+		Resources:     []FencingResource{},
+	}
+
+	for _, prop := range c.Cib.Configuration.CrmConfig.ClusterProperties {
+		switch prop.Name {
+		case stonithTimeoutProperty:
+			fencing.Timeout = cib.NewDuration(prop.Value)
+		case priorityFencingDelayProperty:
+			fencing.PriorityDelay = cib.NewDuration(prop.Value)
+		}
+	}
+
+	clusterNodes := []string{}
+	for _, node := range c.Crmmon.Nodes {
+		clusterNodes = append(clusterNodes, node.Name)
+	}
+
+	for _, primitive := range c.Cib.Primitives() {
+		if primitive.Class != stonithClass {
+			continue
+		}
+		fencing.Resources = append(fencing.Resources, newFencingResource(&primitive, clusterNodes))
+	}
+
+	return fencing
+}
+
+func newFencingResource(primitive *cib.Primitive, clusterNodes []string) FencingResource {
+	resource := FencingResource{
+		ID:                  primitive.ID,
+		Agent:               primitive.Type,
+		Parameters:          make(map[string]string),
+		HostCheck:           "",
+		Nodes:               []string{},
+		Timeouts:            make(map[string]cib.Duration),
+		DelayBase:           cib.Duration{},
+		NodesDelayBase:      make(map[string]cib.Duration),
+		DelayMax:            cib.Duration{},
+		AzureAuthentication: "",
+	}
+
+	params := make(map[string]string)
+	for _, attribute := range primitive.InstanceAttributes {
+		params[attribute.Name] = attribute.Value
+		resource.Parameters[attribute.Name] = attribute.Value
+
+		if strings.HasPrefix(attribute.Name, pcmkParameterPrefix) && strings.HasSuffix(attribute.Name, pcmkTimeoutSuffix) {
+			action := strings.TrimSuffix(strings.TrimPrefix(attribute.Name, pcmkParameterPrefix), pcmkTimeoutSuffix)
+			resource.Timeouts[action] = cib.NewDuration(attribute.Value)
+		}
+	}
+
+	resource.DelayBase, resource.NodesDelayBase = parseDelayBase(params[pcmkDelayBaseParam])
+	resource.DelayMax = cib.NewDuration(params[pcmkDelayMaxParam])
+	resource.HostCheck, resource.Nodes = fencedNodes(params, clusterNodes)
+
+	if primitive.Type == azureFencingAgent {
+		resource.AzureAuthentication = AzureServicePrincipal
+		if isPacemakerTrue(params[azureMSIParam]) {
+			resource.AzureAuthentication = AzureManagedIdentity
+		}
+	}
+
+	return resource
+}
+
+// fencedNodes follows the fencer logic: the default host check is static-list when
+// pcmk_host_list or pcmk_host_map are set, and dynamic-list otherwise
+func fencedNodes(params map[string]string, clusterNodes []string) (string, []string) {
+	hostCheck := params[pcmkHostCheckParam]
+	staticNodes := hostListNodes(params[pcmkHostListParam])
+	for _, node := range hostMapNodes(params[pcmkHostMapParam]) {
+		if !containsString(staticNodes, node) {
+			staticNodes = append(staticNodes, node)
+		}
+	}
+
+	if hostCheck == "" {
+		hostCheck = dynamicListHostCheck
+		if len(staticNodes) > 0 {
+			hostCheck = staticListHostCheck
+		}
+	}
+
+	if hostCheck == staticListHostCheck {
+		return hostCheck, staticNodes
+	}
+
+	// dynamic-list and status can't be resolved without asking the device, and none means all the nodes
+	return hostCheck, append([]string{}, clusterNodes...)
+}
+
+func hostListNodes(hostList string) []string {
+	return append([]string{}, strings.FieldsFunc(hostList, isHostListSeparator)...)
+}
+
+// hostMapNodes returns the node names of a pcmk_host_map, like node1:port1;node2:port2
+func hostMapNodes(hostMap string) []string {
+	nodes := []string{}
+	for _, pair := range strings.FieldsFunc(hostMap, isHostListSeparator) {
+		node, _, _ := strings.Cut(pair, hostMapPairSplitter)
+		if node != "" {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// parseDelayBase parses pcmk_delay_base, which is a single delay or a delay for each node
+func parseDelayBase(delayBase string) (cib.Duration, map[string]cib.Duration) {
+	nodesDelay := make(map[string]cib.Duration)
+	if !strings.Contains(delayBase, hostMapPairSplitter) {
+		return cib.NewDuration(delayBase), nodesDelay
+	}
+
+	for _, pair := range strings.FieldsFunc(delayBase, isHostListSeparator) {
+		node, delay, _ := strings.Cut(pair, hostMapPairSplitter)
+		nodesDelay[node] = cib.NewDuration(delay)
+	}
+	return cib.Duration{Raw: delayBase, Value: 0, Valid: false}, nodesDelay
+}
+
+func isHostListSeparator(r rune) bool {
+	return strings.ContainsRune(hostListSeparators, r)
+}
+
+// isPacemakerTrue accepts the boolean values used in the pacemaker configuration
+func isPacemakerTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "y", "on":
+		return true
+	}
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/trento-project/agent/internal/core/cluster/crmmon"
+)
+
+const (
+	HealthPassing  string = "passing"
+	HealthWarning  string = "warning"
+	HealthCritical string = "critical"
+
+	maintenanceModeProperty string = "maintenance-mode"
+)
+
+// Health is the overall health of the cluster, computed from the cluster state seen by the
+// discovered node. The status is the most severe status of the reasons, or passing if there are none
+type Health struct {
+	Status  string
+	Reasons []HealthReason
+}
+
+// HealthReason explains why the cluster is not healthy. The subject is the node,
+// resource or device affected, empty if the reason applies to the whole cluster
+type HealthReason struct {
+	Status  string
+	Code    string
+	Subject string
+	Message string
+}
+
+func (h *Health) addReason(status, code, subject, message string) {
+	h.Reasons = append(h.Reasons, HealthReason{
+		Status:  status,
+		Code:    code,
+		Subject: subject,
+		Message: message,
+	})
+
+	if status == HealthCritical || h.Status == HealthPassing {
+		h.Status = status
+	}
+}
+
+// NewHealth computes the health of the cluster
+func NewHealth(c *Cluster) Health {
+	health := Health{
+		Status:  HealthPassing,
+		Reasons: []HealthReason{},
+	}
+
+	checkClusterHealth(c, &health)
+	checkNodesHealth(c, &health)
+	checkResourcesHealth(c, &health)
+	checkFailCountsHealth(c, &health)
+	checkFencingHealth(c, &health)
+
+	return health
+}
+
+func checkClusterHealth(c *Cluster, health *Health) {
+	for _, prop := range c.Cib.Configuration.CrmConfig.ClusterProperties {
+		if prop.Name != maintenanceModeProperty {
+			continue
+		}
+		if maintenance, _ := strconv.ParseBool(prop.Value); maintenance {
+			health.addReason(HealthWarning, "maintenance_mode", "",
+				"The cluster is in maintenance mode, resources are not managed")
+		}
+	}
+}
+
+func checkNodesHealth(c *Cluster, health *Health) {
+	for _, node := range c.Crmmon.Nodes {
+		switch {
+		case node.Unclean:
+			health.addReason(HealthCritical, "node_unclean", node.Name,
+				fmt.Sprintf("Node %s is unclean and needs to be fenced", node.Name))
+		case !node.Online:
+			health.addReason(HealthCritical, "node_offline", node.Name,
+				fmt.Sprintf("Node %s is offline", node.Name))
+		case node.Standby:
+			health.addReason(HealthWarning, "node_standby", node.Name,
+				fmt.Sprintf("Node %s is in standby", node.Name))
+		}
+
+		if node.Maintenance {
+			health.addReason(HealthWarning, "node_maintenance", node.Name,
+				fmt.Sprintf("Node %s is in maintenance", node.Name))
+		}
+	}
+}
+
+func checkResourcesHealth(c *Cluster, health *Health) {
+	resources := append([]crmmon.Resource{}, c.Crmmon.Resources...)
+	for _, clone := range c.Crmmon.Clones {
+		resources = append(resources, clone.Resources...)
+	}
+	for _, group := range c.Crmmon.Groups {
+		resources = append(resources, group.Resources...)
+	}
+
+	// clone instances share the resource id, each problem is reported once per resource
+	reported := make(map[string]bool)
+	report := func(status, code, resourceID, message string) {
+		if reported[code+resourceID] {
+			return
+		}
+		reported[code+resourceID] = true
+		health.addReason(status, code, resourceID, message)
+	}
+
+	for _, resource := range resources {
+		if resource.Failed && !resource.FailureIgnored {
+			report(HealthCritical, "resource_failed", resource.ID,
+				fmt.Sprintf("Resource %s has failed", resource.ID))
+		}
+		if resource.Blocked {
+			report(HealthCritical, "resource_blocked", resource.ID,
+				fmt.Sprintf("Resource %s is blocked", resource.ID))
+		}
+		if !resource.Managed {
+			report(HealthWarning, "resource_unmanaged", resource.ID,
+				fmt.Sprintf("Resource %s is not managed by the cluster", resource.ID))
+		}
+	}
+}
+
+// checkFailCountsHealth reports the resources banned from a node because the fail-count reached
+// the migration-threshold, and the ones that will be banned with the next failure
+func checkFailCountsHealth(c *Cluster, health *Health) {
+	for _, node := range c.Crmmon.NodeHistory.Nodes {
+		for _, history := range node.ResourceHistory {
+			if history.FailCount == 0 || history.MigrationThreshold == 0 {
+				continue
+			}
+
+			subject := fmt.Sprintf("%s@%s", history.Name, node.Name)
+			remaining := history.MigrationThreshold - history.FailCount
+			switch {
+			case remaining <= 0:
+				health.addReason(HealthCritical, "migration_threshold_reached", subject,
+					fmt.Sprintf("Resource %s reached the migration-threshold (%d) on node %s",
+						history.Name, history.MigrationThreshold, node.Name))
+			case remaining == 1:
+				health.addReason(HealthWarning, "migration_threshold_near", subject,
+					fmt.Sprintf("Resource %s is one failure away from the migration-threshold (%d) on node %s",
+						history.Name, history.MigrationThreshold, node.Name))
+			}
+		}
+	}
+}
+
+func checkFencingHealth(c *Cluster, health *Health) {
+	if !c.IsFencingEnabled() {
+		health.addReason(HealthCritical, "fencing_disabled", "", "Fencing is disabled")
+	}
+
+	if !c.FencingResourceExists() {
+		health.addReason(HealthCritical, "fencing_resource_missing", "", "There is no fencing resource configured")
+		return
+	}
+
+	if !c.IsFencingSBD() {
+		return
+	}
+
+	healthyDevices := 0
+	for _, device := range c.SBD.Devices {
+		if device.Status == SBDStatusHealthy {
+			healthyDevices++
+			continue
+		}
+		health.addReason(HealthWarning, "sbd_device_unhealthy", device.Device,
+			fmt.Sprintf("SBD device %s is unhealthy", device.Device))
+	}
+
+	if len(c.SBD.Devices) > 0 && healthyDevices == 0 {
+		health.addReason(HealthCritical, "sbd_devices_unhealthy", "", "None of the SBD devices is healthy")
+	}
+}
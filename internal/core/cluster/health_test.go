@@ -0,0 +1,228 @@
+//nolint:exhaustruct
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/core/cluster/cib"
+	"github.com/trento-project/agent/internal/core/cluster/crmmon"
+)
+
+type HealthTestSuite struct {
+	suite.Suite
+}
+
+func TestHealthTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
+
+func healthyCluster() *Cluster {
+	c := &Cluster{
+		Crmmon: crmmon.Root{
+			Version: "1.2.3",
+			Nodes: []crmmon.Node{
+				{Name: "node01", Online: true},
+				{Name: "node02", Online: true},
+			},
+			Resources: []crmmon.Resource{
+				{ID: "stonith-sbd", Agent: "stonith:external/sbd", Managed: true},
+			},
+		},
+		SBD: SBD{
+			Devices: []*SBDDevice{
+				{Device: "/dev/vdb", Status: SBDStatusHealthy},
+			},
+		},
+	}
+
+	c.Cib.Configuration.CrmConfig.ClusterProperties = []cib.Attribute{
+		{ID: "cib-bootstrap-options-stonith-enabled", Name: "stonith-enabled", Value: "true"},
+	}
+
+	return c
+}
+
+func (suite *HealthTestSuite) TestHealthPassing() {
+	health := NewHealth(healthyCluster())
+
+	suite.Equal(Health{Status: HealthPassing, Reasons: []HealthReason{}}, health)
+}
+
+func (suite *HealthTestSuite) TestHealthNodes() {
+	c := healthyCluster()
+	c.Crmmon.Nodes = []crmmon.Node{
+		{Name: "node01", Online: true, Standby: true},
+		{Name: "node02", Online: false},
+		{Name: "node03", Online: true, Unclean: true},
+		{Name: "node04", Online: true, Maintenance: true},
+	}
+
+	health := NewHealth(c)
+
+	suite.Equal(HealthCritical, health.Status)
+	suite.Equal([]HealthReason{
+		{Status: HealthWarning, Code: "node_standby", Subject: "node01", Message: "Node node01 is in standby"},
+		{Status: HealthCritical, Code: "node_offline", Subject: "node02", Message: "Node node02 is offline"},
+		{
+			Status:  HealthCritical,
+			Code:    "node_unclean",
+			Subject: "node03",
+			Message: "Node node03 is unclean and needs to be fenced",
+		},
+		{Status: HealthWarning, Code: "node_maintenance", Subject: "node04", Message: "Node node04 is in maintenance"},
+	}, health.Reasons)
+}
+
+func (suite *HealthTestSuite) TestHealthResources() {
+	c := healthyCluster()
+	c.Crmmon.Clones = []crmmon.Clone{
+		{
+			ID: "cln_SAPHanaTopology",
+			Resources: []crmmon.Resource{
+				{ID: "rsc_SAPHanaTopology", Managed: true, Failed: true},
+				{ID: "rsc_SAPHanaTopology", Managed: true, Failed: true},
+			},
+		},
+	}
+	c.Crmmon.Groups = []crmmon.Group{
+		{
+			ID: "grp_ASCS00",
+			Resources: []crmmon.Resource{
+				{ID: "rsc_ip_ASCS00", Managed: true, Blocked: true},
+				{ID: "rsc_fs_ASCS00", Managed: false},
+				{ID: "rsc_sap_ASCS00", Managed: true, Failed: true, FailureIgnored: true},
+			},
+		},
+	}
+
+	health := NewHealth(c)
+
+	suite.Equal(HealthCritical, health.Status)
+	suite.Equal([]HealthReason{
+		{
+			Status:  HealthCritical,
+			Code:    "resource_failed",
+			Subject: "rsc_SAPHanaTopology",
+			Message: "Resource rsc_SAPHanaTopology has failed",
+		},
+		{
+			Status:  HealthCritical,
+			Code:    "resource_blocked",
+			Subject: "rsc_ip_ASCS00",
+			Message: "Resource rsc_ip_ASCS00 is blocked",
+		},
+		{
+			Status:  HealthWarning,
+			Code:    "resource_unmanaged",
+			Subject: "rsc_fs_ASCS00",
+			Message: "Resource rsc_fs_ASCS00 is not managed by the cluster",
+		},
+	}, health.Reasons)
+}
+
+func (suite *HealthTestSuite) TestHealthFailCounts() {
+	c := healthyCluster()
+	c.Crmmon.NodeHistory.Nodes = []crmmon.NodeHistory{
+		{
+			Name: "node01",
+			ResourceHistory: []crmmon.ResourceHistory{
+				{Name: "rsc_ip", MigrationThreshold: 3, FailCount: 2},
+				{Name: "rsc_fs", MigrationThreshold: 3, FailCount: 1},
+				{Name: "rsc_sap", MigrationThreshold: 3, FailCount: 1000000},
+				{Name: "rsc_dummy", MigrationThreshold: 3, FailCount: 0},
+			},
+		},
+	}
+
+	health := NewHealth(c)
+
+	suite.Equal(HealthCritical, health.Status)
+	suite.Equal([]HealthReason{
+		{
+			Status:  HealthWarning,
+			Code:    "migration_threshold_near",
+			Subject: "rsc_ip@node01",
+			Message: "Resource rsc_ip is one failure away from the migration-threshold (3) on node node01",
+		},
+		{
+			Status:  HealthCritical,
+			Code:    "migration_threshold_reached",
+			Subject: "rsc_sap@node01",
+			Message: "Resource rsc_sap reached the migration-threshold (3) on node node01",
+		},
+	}, health.Reasons)
+}
+
+func (suite *HealthTestSuite) TestHealthMaintenanceMode() {
+	c := healthyCluster()
+	c.Cib.Configuration.CrmConfig.ClusterProperties = append(c.Cib.Configuration.CrmConfig.ClusterProperties,
+		cib.Attribute{ID: "cib-bootstrap-options-maintenance-mode", Name: "maintenance-mode", Value: "true"})
+
+	health := NewHealth(c)
+
+	suite.Equal(HealthWarning, health.Status)
+	suite.Equal([]HealthReason{
+		{
+			Status:  HealthWarning,
+			Code:    "maintenance_mode",
+			Subject: "",
+			Message: "The cluster is in maintenance mode, resources are not managed",
+		},
+	}, health.Reasons)
+}
+
+func (suite *HealthTestSuite) TestHealthFencing() {
+	c := healthyCluster()
+	c.Cib.Configuration.CrmConfig.ClusterProperties = []cib.Attribute{
+		{ID: "cib-bootstrap-options-stonith-enabled", Name: "stonith-enabled", Value: "false"},
+	}
+	c.Crmmon.Resources = []crmmon.Resource{}
+
+	health := NewHealth(c)
+
+	suite.Equal(HealthCritical, health.Status)
+	suite.Equal([]HealthReason{
+		{Status: HealthCritical, Code: "fencing_disabled", Subject: "", Message: "Fencing is disabled"},
+		{
+			Status:  HealthCritical,
+			Code:    "fencing_resource_missing",
+			Subject: "",
+			Message: "There is no fencing resource configured",
+		},
+	}, health.Reasons)
+}
+
+func (suite *HealthTestSuite) TestHealthFencingEnabledByDefault() {
+	c := healthyCluster()
+	c.Cib.Configuration.CrmConfig.ClusterProperties = []cib.Attribute{}
+
+	health := NewHealth(c)
+
+	suite.Equal(Health{Status: HealthPassing, Reasons: []HealthReason{}}, health)
+}
+
+func (suite *HealthTestSuite) TestHealthSBDDevices() {
+	c := healthyCluster()
+	c.SBD.Devices = append(c.SBD.Devices, &SBDDevice{Device: "/dev/vdc", Status: SBDStatusUnhealthy})
+
+	health := NewHealth(c)
+
+	suite.Equal(HealthWarning, health.Status)
+	suite.Equal([]HealthReason{
+		{
+			Status:  HealthWarning,
+			Code:    "sbd_device_unhealthy",
+			Subject: "/dev/vdc",
+			Message: "SBD device /dev/vdc is unhealthy",
+		},
+	}, health.Reasons)
+
+	c.SBD.Devices[0].Status = SBDStatusUnhealthy
+
+	health = NewHealth(c)
+
+	suite.Equal(HealthCritical, health.Status)
+	suite.Equal(3, len(health.Reasons))
+	suite.Equal("sbd_devices_unhealthy", health.Reasons[2].Code)
+}
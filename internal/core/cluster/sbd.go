@@ -70,11 +70,7 @@ func NewSBD(executor utils.CommandExecutor, cluster, sbdPath, sbdConfigPath stri
 		return s, fmt.Errorf("could not find SBD_DEVICE entry in sbd config file")
 	}
 
-	sbdDevice, ok := c["SBD_DEVICE"]
-	if !ok {
-		return s, fmt.Errorf("could not cast sbd device to string, %v", c["SBD_DEVICE"])
-	}
-	for _, device := range strings.Split(strings.Trim(sbdDevice, "\""), ";") {
+	for _, device := range SBDDevicePaths(c) {
 		sbdDevice := NewSBDDevice(executor, sbdPath, device)
 		err := sbdDevice.LoadDeviceData()
 		if err != nil {
@@ -107,6 +103,17 @@ func LoadSbdConfig(sbdConfigPath string) (map[string]string, error) {
 	return conf, nil
 }
 
+// SBDDevicePaths returns the devices of the SBD_DEVICE entry, separated by ";"
+func SBDDevicePaths(sbdConfig map[string]string) []string {
+	devices := []string{}
+	for _, device := range strings.Split(strings.Trim(sbdConfig["SBD_DEVICE"], "\""), ";") {
+		if device = strings.TrimSpace(device); device != "" {
+			devices = append(devices, device)
+		}
+	}
+	return devices
+}
+
 func NewSBDDevice(executor utils.CommandExecutor, sbdPath, device string) SBDDevice {
 	return SBDDevice{ //nolint
 		executor: executor,
@@ -511,3 +511,10 @@ func (suite *SbdTestSuite) TestNewSBDQuotedDevices() {
 	suite.Equal("/dev/vdb", s.Devices[1].Device)
 	suite.NoError(err)
 }
+
+func (suite *SbdTestSuite) TestSBDDevicePaths() {
+	suite.Equal(
+		[]string{"/dev/vdc", "/dev/vdb"},
+		SBDDevicePaths(map[string]string{"SBD_DEVICE": "\"/dev/vdc; /dev/vdb;\""}))
+	suite.Equal([]string{}, SBDDevicePaths(map[string]string{"SBD_PACEMAKER": "yes"}))
+}
@@ -1,9 +1,11 @@
 package hosts
 
 type DiscoveredHost struct {
-	SSHAddress         string   `json:"ssh_address"`
-	OSVersion          string   `json:"os_version"`
+	SSHAddress string `json:"ssh_address"`
+	OSVersion  string `json:"os_version"`
+	// HostIPAddresses is kept for backwards compatibility, Network has the labelled addresses
 	HostIPAddresses    []string `json:"ip_addresses"`
+	Network            *Network `json:"network"`
 	HostName           string   `json:"hostname"`
 	CPUCount           int      `json:"cpu_count"`
 	SocketCount        int      `json:"socket_count"`
@@ -0,0 +1,578 @@
+package hosts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	NetworkFamilyIPv4 = "ipv4"
+	NetworkFamilyIPv6 = "ipv6"
+
+	NetworkScopeHost   = "host"
+	NetworkScopeLink   = "link"
+	NetworkScopeGlobal = "global"
+
+	NetworkStateUnknown = "unknown"
+
+	sysClassNetPath    = "/sys/class/net"
+	procNetBondingPath = "/proc/net/bonding"
+	procNetVLANPath    = "/proc/net/vlan/config"
+	procNetRoutePath   = "/proc/net/route"
+	procNetRoute6Path  = "/proc/net/ipv6_route"
+	resolvConfPath     = "/etc/resolv.conf"
+
+	// route flags from include/uapi/linux/route.h
+	routeFlagUp      = 0x0001
+	routeFlagGateway = 0x0002
+)
+
+// nolint:gochecknoglobals
+var bondingModes = map[string]string{
+	"load balancing (round-robin)":          "balance-rr",
+	"fault-tolerance (active-backup)":       "active-backup",
+	"load balancing (xor)":                  "balance-xor",
+	"fault-tolerance (broadcast)":           "broadcast",
+	"IEEE 802.3ad Dynamic link aggregation": "802.3ad",
+	"transmit load balancing":               "balance-tlb",
+	"adaptive load balancing":               "balance-alb",
+}
+
+type Network struct {
+	Interfaces []*NetworkInterface `json:"interfaces"`
+	Routes     []*NetworkRoute     `json:"routes"`
+	DNS        DNSConfig           `json:"dns"`
+}
+
+type NetworkInterface struct {
+	Name       string            `json:"name"`
+	Index      int               `json:"index"`
+	MacAddress string            `json:"mac_address"`
+	MTU        int               `json:"mtu"`
+	State      string            `json:"state"`
+	Loopback   bool              `json:"loopback"`
+	Addresses  []*NetworkAddress `json:"addresses"`
+	Master     string            `json:"master,omitempty"`
+	Bonding    *NetworkBonding   `json:"bonding,omitempty"`
+	VLAN       *NetworkVLAN      `json:"vlan,omitempty"`
+}
+
+type NetworkAddress struct {
+	Address      string `json:"address"`
+	PrefixLength int    `json:"prefix_length"`
+	Family       string `json:"family"`
+	Scope        string `json:"scope"`
+}
+
+type NetworkBonding struct {
+	Mode               string                 `json:"mode"`
+	MIIStatus          string                 `json:"mii_status"`
+	MIIPollingInterval int                    `json:"mii_polling_interval"`
+	ActiveSlave        string                 `json:"active_slave,omitempty"`
+	Slaves             []*NetworkBondingSlave `json:"slaves"`
+}
+
+type NetworkBondingSlave struct {
+	Name             string `json:"name"`
+	MIIStatus        string `json:"mii_status"`
+	LinkFailureCount int    `json:"link_failure_count"`
+}
+
+type NetworkVLAN struct {
+	ID     int    `json:"id"`
+	Parent string `json:"parent"`
+}
+
+type NetworkRoute struct {
+	Family      string `json:"family"`
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway,omitempty"`
+	Interface   string `json:"interface"`
+	Metric      int    `json:"metric"`
+	Default     bool   `json:"default"`
+}
+
+type DNSConfig struct {
+	Nameservers []string `json:"nameservers"`
+	Search      []string `json:"search"`
+	Options     []string `json:"options"`
+}
+
+// NetworkInterfacesProvider lists the network interfaces and their addresses.
+// The default implementation uses the standard library, which queries them through netlink
+//
+//go:generate mockery --name=NetworkInterfacesProvider
+type NetworkInterfacesProvider interface {
+	Interfaces() ([]net.Interface, error)
+	Addrs(iface net.Interface) ([]net.Addr, error)
+}
+
+type NetlinkInterfacesProvider struct{}
+
+func (p NetlinkInterfacesProvider) Interfaces() ([]net.Interface, error) {
+	return net.Interfaces()
+}
+
+func (p NetlinkInterfacesProvider) Addrs(iface net.Interface) ([]net.Addr, error) {
+	return iface.Addrs()
+}
+
+// NewNetwork discovers the network configuration of the host. The interfaces come from the
+// provider, and the link state, bonding, vlan, routing and dns details from /sys, /proc/net and /etc.
+// Only listing the interfaces is mandatory, the errors in the other details are logged and
+// the affected details are left empty
+func NewNetwork(fs afero.Fs, provider NetworkInterfacesProvider) (*Network, error) {
+	systemInterfaces, err := provider.Interfaces()
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the network interfaces")
+	}
+
+	bonds, err := readBondings(fs)
+	if err != nil {
+		log.Warnf("Error discovering the network bondings: %s", err)
+		bonds = make(map[string]*NetworkBonding)
+	}
+
+	vlans, err := readVLANs(fs)
+	if err != nil {
+		log.Warnf("Error discovering the network vlans: %s", err)
+		vlans = make(map[string]*NetworkVLAN)
+	}
+
+	network := &Network{
+		Interfaces: []*NetworkInterface{},
+	}
+
+	for _, systemInterface := range systemInterfaces {
+		networkInterface := &NetworkInterface{
+			Name:       systemInterface.Name,
+			Index:      systemInterface.Index,
+			MacAddress: systemInterface.HardwareAddr.String(),
+			MTU:        systemInterface.MTU,
+			State:      readOperState(fs, systemInterface.Name),
+			Loopback:   systemInterface.Flags&net.FlagLoopback != 0,
+			Addresses:  []*NetworkAddress{},
+			Bonding:    bonds[systemInterface.Name],
+			VLAN:       vlans[systemInterface.Name],
+		}
+
+		addrs, err := provider.Addrs(systemInterface)
+		if err != nil {
+			log.Warnf("Error getting the addresses of the %s interface: %s", systemInterface.Name, err)
+		}
+
+		for _, addr := range addrs {
+			if address := newNetworkAddress(addr); address != nil {
+				networkInterface.Addresses = append(networkInterface.Addresses, address)
+			}
+		}
+
+		network.Interfaces = append(network.Interfaces, networkInterface)
+	}
+
+	for bondName, bond := range bonds {
+		for _, slave := range bond.Slaves {
+			if slaveInterface := network.GetInterface(slave.Name); slaveInterface != nil {
+				slaveInterface.Master = bondName
+			}
+		}
+	}
+
+	network.Routes = readRoutes(fs)
+
+	if network.DNS, err = ReadResolvConf(fs); err != nil {
+		log.Warnf("Error discovering the dns configuration: %s", err)
+	}
+
+	return network, nil
+}
+
+func (n *Network) GetInterface(name string) *NetworkInterface {
+	for _, networkInterface := range n.Interfaces {
+		if networkInterface.Name == name {
+			return networkInterface
+		}
+	}
+	return nil
+}
+
+func (n *Network) DefaultRoutes() []*NetworkRoute {
+	defaultRoutes := []*NetworkRoute{}
+	for _, route := range n.Routes {
+		if route.Default {
+			defaultRoutes = append(defaultRoutes, route)
+		}
+	}
+	return defaultRoutes
+}
+
+// IPAddresses returns the plain list of addresses of all the interfaces
+func (n *Network) IPAddresses() []string {
+	addresses := []string{}
+	for _, networkInterface := range n.Interfaces {
+		for _, address := range networkInterface.Addresses {
+			addresses = append(addresses, address.Address)
+		}
+	}
+	return addresses
+}
+
+func newNetworkAddress(addr net.Addr) *NetworkAddress {
+	ipNet, ok := addr.(*net.IPNet)
+	if !ok {
+		return nil
+	}
+
+	prefixLength, _ := ipNet.Mask.Size()
+	address := &NetworkAddress{
+		Address:      ipNet.IP.String(),
+		PrefixLength: prefixLength,
+		Family:       NetworkFamilyIPv6,
+		Scope:        NetworkScopeGlobal,
+	}
+
+	if ipNet.IP.To4() != nil {
+		address.Family = NetworkFamilyIPv4
+	}
+
+	switch {
+	case ipNet.IP.IsLoopback():
+		address.Scope = NetworkScopeHost
+	case ipNet.IP.IsLinkLocalUnicast():
+		address.Scope = NetworkScopeLink
+	}
+
+	return address
+}
+
+func readOperState(fs afero.Fs, interfaceName string) string {
+	state, err := afero.ReadFile(fs, path.Join(sysClassNetPath, interfaceName, "operstate"))
+	if err != nil {
+		return NetworkStateUnknown
+	}
+	return strings.TrimSpace(string(state))
+}
+
+// readBondings parses the /proc/net/bonding files, one per bonding master
+func readBondings(fs afero.Fs) (map[string]*NetworkBonding, error) {
+	bonds := make(map[string]*NetworkBonding)
+
+	entries, err := afero.ReadDir(fs, procNetBondingPath)
+	if os.IsNotExist(err) {
+		return bonds, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "error reading the bonding information")
+	}
+
+	for _, entry := range entries {
+		content, err := afero.ReadFile(fs, path.Join(procNetBondingPath, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading the bonding information")
+		}
+		bonds[entry.Name()] = parseBonding(content)
+	}
+
+	return bonds, nil
+}
+
+func parseBonding(content []byte) *NetworkBonding {
+	bond := &NetworkBonding{
+		Slaves: []*NetworkBondingSlave{},
+	}
+	var currentSlave *NetworkBondingSlave
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Bonding Mode":
+			bond.Mode = value
+			if mode, found := bondingModes[value]; found {
+				bond.Mode = mode
+			}
+		case "Currently Active Slave":
+			bond.ActiveSlave = value
+		case "MII Polling Interval (ms)":
+			bond.MIIPollingInterval, _ = strconv.Atoi(value)
+		case "Slave Interface":
+			currentSlave = &NetworkBondingSlave{Name: value}
+			bond.Slaves = append(bond.Slaves, currentSlave)
+		case "MII Status":
+			if currentSlave == nil {
+				bond.MIIStatus = value
+			} else {
+				currentSlave.MIIStatus = value
+			}
+		case "Link Failure Count":
+			if currentSlave != nil {
+				currentSlave.LinkFailureCount, _ = strconv.Atoi(value)
+			}
+		}
+	}
+
+	return bond
+}
+
+// readVLANs parses /proc/net/vlan/config, which only exists when the 8021q module is loaded
+func readVLANs(fs afero.Fs) (map[string]*NetworkVLAN, error) {
+	vlans := make(map[string]*NetworkVLAN)
+
+	content, err := afero.ReadFile(fs, procNetVLANPath)
+	if os.IsNotExist(err) {
+		return vlans, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "error reading the vlan information")
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "|")
+		if len(fields) != 3 {
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if err != nil {
+			// header line
+			continue
+		}
+
+		vlans[strings.TrimSpace(fields[0])] = &NetworkVLAN{
+			ID:     id,
+			Parent: strings.TrimSpace(fields[2]),
+		}
+	}
+
+	return vlans, nil
+}
+
+// readRoutes reads the ipv4 and ipv6 routes. The routes of a family that can't be read
+// or decoded are skipped, logging the error
+func readRoutes(fs afero.Fs) []*NetworkRoute {
+	routes := []*NetworkRoute{}
+
+	ipv4Routes, err := readRoutesFile(fs, procNetRoutePath, parseIPv4Routes)
+	if err != nil {
+		log.Warnf("Error discovering the ipv4 routes: %s", err)
+	}
+	routes = append(routes, ipv4Routes...)
+
+	ipv6Routes, err := readRoutesFile(fs, procNetRoute6Path, parseIPv6Routes)
+	if err != nil {
+		log.Warnf("Error discovering the ipv6 routes: %s", err)
+	}
+	routes = append(routes, ipv6Routes...)
+
+	return routes
+}
+
+func readRoutesFile(
+	fs afero.Fs,
+	routesPath string,
+	parse func(content []byte) ([]*NetworkRoute, error),
+) ([]*NetworkRoute, error) {
+	content, err := afero.ReadFile(fs, routesPath)
+	if os.IsNotExist(err) {
+		return []*NetworkRoute{}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error reading %s", routesPath)
+	}
+
+	routes, err := parse(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decoding %s", routesPath)
+	}
+
+	return routes, nil
+}
+
+// parseIPv4Routes parses /proc/net/route, where the addresses are little endian hexadecimal numbers:
+// Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+func parseIPv4Routes(content []byte) ([]*NetworkRoute, error) {
+	routes := []*NetworkRoute{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[0] == "Iface" {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flags %s", fields[3])
+		}
+		if flags&routeFlagUp == 0 {
+			continue
+		}
+
+		destination, err := parseIPv4Hex(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		gateway, err := parseIPv4Hex(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		mask, err := parseIPv4Hex(fields[7])
+		if err != nil {
+			return nil, err
+		}
+
+		metric, err := strconv.Atoi(fields[6])
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric %s", fields[6])
+		}
+
+		prefixLength, _ := net.IPMask(mask.To4()).Size()
+		route := &NetworkRoute{
+			Family:      NetworkFamilyIPv4,
+			Destination: fmt.Sprintf("%s/%d", destination, prefixLength),
+			Interface:   fields[0],
+			Metric:      metric,
+			Default:     destination.IsUnspecified() && prefixLength == 0,
+		}
+
+		if flags&routeFlagGateway != 0 {
+			route.Gateway = gateway.String()
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// parseIPv6Routes parses /proc/net/ipv6_route. The routes of the loopback interface are skipped,
+// as they are the local and unreachable entries of the kernel:
+// destination prefix source prefix next_hop metric refcnt use flags iface
+func parseIPv6Routes(content []byte) ([]*NetworkRoute, error) {
+	routes := []*NetworkRoute{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 10 || fields[9] == "lo" {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flags %s", fields[8])
+		}
+		if flags&routeFlagUp == 0 {
+			continue
+		}
+
+		destination, err := parseIPv6Hex(fields[0])
+		if err != nil {
+			return nil, err
+		}
+
+		prefixLength, err := strconv.ParseUint(fields[1], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix length %s", fields[1])
+		}
+
+		gateway, err := parseIPv6Hex(fields[4])
+		if err != nil {
+			return nil, err
+		}
+
+		metric, err := strconv.ParseUint(fields[5], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric %s", fields[5])
+		}
+
+		route := &NetworkRoute{
+			Family:      NetworkFamilyIPv6,
+			Destination: fmt.Sprintf("%s/%d", destination, prefixLength),
+			Interface:   fields[9],
+			Metric:      int(metric),
+			Default:     destination.IsUnspecified() && prefixLength == 0,
+		}
+
+		if flags&routeFlagGateway != 0 {
+			route.Gateway = gateway.String()
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+func parseIPv4Hex(value string) (net.IP, error) {
+	decoded, err := hex.DecodeString(value)
+	if err != nil || len(decoded) != net.IPv4len {
+		return nil, fmt.Errorf("invalid ipv4 address %s", value)
+	}
+
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(decoded))
+	return ip, nil
+}
+
+func parseIPv6Hex(value string) (net.IP, error) {
+	decoded, err := hex.DecodeString(value)
+	if err != nil || len(decoded) != net.IPv6len {
+		return nil, fmt.Errorf("invalid ipv6 address %s", value)
+	}
+
+	return net.IP(decoded), nil
+}
+
+// ReadResolvConf reads the nameservers, search domains and options of /etc/resolv.conf
+func ReadResolvConf(fs afero.Fs) (DNSConfig, error) {
+	dns := DNSConfig{
+		Nameservers: []string{},
+		Search:      []string{},
+		Options:     []string{},
+	}
+
+	content, err := afero.ReadFile(fs, resolvConfPath)
+	if os.IsNotExist(err) {
+		return dns, nil
+	} else if err != nil {
+		return dns, errors.Wrap(err, "error reading the dns configuration")
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+
+		switch fields[0] {
+		case "nameserver":
+			dns.Nameservers = append(dns.Nameservers, fields[1])
+		// the last domain or search entry wins
+		case "domain", "search":
+			dns.Search = fields[1:]
+		case "options":
+			dns.Options = append(dns.Options, fields[1:]...)
+		}
+	}
+
+	return dns, nil
+}
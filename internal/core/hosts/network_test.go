@@ -0,0 +1,232 @@
+package hosts
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/core/hosts/mocks"
+	"github.com/trento-project/agent/test/helpers"
+)
+
+type NetworkTestSuite struct {
+	suite.Suite
+	fs           afero.Fs
+	mockProvider *mocks.NetworkInterfacesProvider
+}
+
+func TestNetworkTestSuite(t *testing.T) {
+	suite.Run(t, new(NetworkTestSuite))
+}
+
+func (suite *NetworkTestSuite) SetupTest() {
+	suite.fs = afero.NewMemMapFs()
+	suite.mockProvider = new(mocks.NetworkInterfacesProvider)
+
+	files := map[string]string{
+		"/proc/net/route":         "route",
+		"/proc/net/ipv6_route":    "ipv6_route",
+		"/proc/net/bonding/bond0": "bond0",
+		"/proc/net/vlan/config":   "vlan_config",
+		"/etc/resolv.conf":        "resolv.conf",
+	}
+	for filePath, fixture := range files {
+		content, _ := os.ReadFile(helpers.GetFixturePath("discovery/host/network/" + fixture))
+		_ = afero.WriteFile(suite.fs, filePath, content, 0644)
+	}
+
+	for name, state := range map[string]string{"lo": "unknown", "eth0": "up", "eth1": "down", "bond0": "up"} {
+		_ = afero.WriteFile(suite.fs, "/sys/class/net/"+name+"/operstate", []byte(state+"\n"), 0644)
+	}
+}
+
+// failingOpenFs fails opening the given paths, as it happens with permission errors
+type failingOpenFs struct {
+	afero.Fs
+	paths []string
+}
+
+func (fs failingOpenFs) Open(name string) (afero.File, error) {
+	for _, failingPath := range fs.paths {
+		if name == failingPath {
+			return nil, os.ErrPermission
+		}
+	}
+	return fs.Fs.Open(name)
+}
+
+func mockInterface(index int, name string, mtu int, mac string, flags net.Flags) net.Interface {
+	hardwareAddr, _ := net.ParseMAC(mac)
+	return net.Interface{Index: index, Name: name, MTU: mtu, HardwareAddr: hardwareAddr, Flags: flags}
+}
+
+func mockAddrs(cidrs ...string) []net.Addr {
+	addrs := []net.Addr{}
+	for _, cidr := range cidrs {
+		ip, ipNet, _ := net.ParseCIDR(cidr)
+		addrs = append(addrs, &net.IPNet{IP: ip, Mask: ipNet.Mask})
+	}
+	return addrs
+}
+
+func (suite *NetworkTestSuite) mockInterfaces() {
+	lo := mockInterface(1, "lo", 65536, "", net.FlagUp|net.FlagLoopback)
+	eth0 := mockInterface(2, "eth0", 9000, "52:54:00:a1:b2:01", net.FlagUp)
+	eth1 := mockInterface(3, "eth1", 9000, "52:54:00:a1:b2:01", 0)
+	bond0 := mockInterface(4, "bond0", 9000, "52:54:00:a1:b2:01", net.FlagUp)
+	vlan := mockInterface(5, "eth2.100", 1500, "52:54:00:a1:b2:03", net.FlagUp)
+
+	suite.mockProvider.On("Interfaces").Return([]net.Interface{lo, eth0, eth1, bond0, vlan}, nil)
+	suite.mockProvider.On("Addrs", lo).Return(mockAddrs("127.0.0.1/8", "::1/128"), nil)
+	suite.mockProvider.On("Addrs", eth0).Return(mockAddrs(), nil)
+	suite.mockProvider.On("Addrs", eth1).Return(nil, errors.New("no such device"))
+	suite.mockProvider.On("Addrs", bond0).Return(
+		mockAddrs("192.168.1.20/24", "fd00::20/64", "fe80::5054:ff:fea1:b201/64"), nil)
+	suite.mockProvider.On("Addrs", vlan).Return(mockAddrs("10.10.10.5/24"), nil)
+}
+
+func (suite *NetworkTestSuite) TestNewNetwork() {
+	suite.mockInterfaces()
+
+	network, err := NewNetwork(suite.fs, suite.mockProvider)
+	suite.NoError(err)
+
+	expectedInterfaces := []*NetworkInterface{
+		{
+			Name:       "lo",
+			Index:      1,
+			MacAddress: "",
+			MTU:        65536,
+			State:      "unknown",
+			Loopback:   true,
+			Addresses: []*NetworkAddress{
+				{Address: "127.0.0.1", PrefixLength: 8, Family: "ipv4", Scope: "host"},
+				{Address: "::1", PrefixLength: 128, Family: "ipv6", Scope: "host"},
+			},
+		},
+		{
+			Name:       "eth0",
+			Index:      2,
+			MacAddress: "52:54:00:a1:b2:01",
+			MTU:        9000,
+			State:      "up",
+			Addresses:  []*NetworkAddress{},
+			Master:     "bond0",
+		},
+		{
+			Name:       "eth1",
+			Index:      3,
+			MacAddress: "52:54:00:a1:b2:01",
+			MTU:        9000,
+			State:      "down",
+			Addresses:  []*NetworkAddress{},
+			Master:     "bond0",
+		},
+		{
+			Name:       "bond0",
+			Index:      4,
+			MacAddress: "52:54:00:a1:b2:01",
+			MTU:        9000,
+			State:      "up",
+			Addresses: []*NetworkAddress{
+				{Address: "192.168.1.20", PrefixLength: 24, Family: "ipv4", Scope: "global"},
+				{Address: "fd00::20", PrefixLength: 64, Family: "ipv6", Scope: "global"},
+				{Address: "fe80::5054:ff:fea1:b201", PrefixLength: 64, Family: "ipv6", Scope: "link"},
+			},
+			Bonding: &NetworkBonding{
+				Mode:               "active-backup",
+				MIIStatus:          "up",
+				MIIPollingInterval: 100,
+				ActiveSlave:        "eth0",
+				Slaves: []*NetworkBondingSlave{
+					{Name: "eth0", MIIStatus: "up", LinkFailureCount: 0},
+					{Name: "eth1", MIIStatus: "down", LinkFailureCount: 2},
+				},
+			},
+		},
+		{
+			Name:       "eth2.100",
+			Index:      5,
+			MacAddress: "52:54:00:a1:b2:03",
+			MTU:        1500,
+			State:      "unknown",
+			Addresses: []*NetworkAddress{
+				{Address: "10.10.10.5", PrefixLength: 24, Family: "ipv4", Scope: "global"},
+			},
+			VLAN: &NetworkVLAN{ID: 100, Parent: "eth2"},
+		},
+	}
+
+	expectedRoutes := []*NetworkRoute{
+		{Family: "ipv4", Destination: "0.0.0.0/0", Gateway: "192.168.1.1", Interface: "bond0", Metric: 100, Default: true},
+		{Family: "ipv4", Destination: "192.168.1.0/24", Interface: "bond0", Metric: 100},
+		{Family: "ipv4", Destination: "10.10.10.0/24", Interface: "eth2.100", Metric: 0},
+		{Family: "ipv6", Destination: "fd00::/64", Interface: "bond0", Metric: 256},
+		{Family: "ipv6", Destination: "fe80::/64", Interface: "bond0", Metric: 256},
+		{Family: "ipv6", Destination: "::/0", Gateway: "fd00::1", Interface: "bond0", Metric: 1024, Default: true},
+	}
+
+	expectedDNS := DNSConfig{
+		Nameservers: []string{"192.168.1.10", "192.168.1.11"},
+		Search:      []string{"example.com", "prd.example.com"},
+		Options:     []string{"timeout:2", "attempts:3"},
+	}
+
+	suite.Equal(expectedInterfaces, network.Interfaces)
+	suite.Equal(expectedRoutes, network.Routes)
+	suite.Equal(expectedDNS, network.DNS)
+	suite.Equal([]*NetworkRoute{expectedRoutes[0], expectedRoutes[5]}, network.DefaultRoutes())
+	suite.Equal([]string{
+		"127.0.0.1", "::1", "192.168.1.20", "fd00::20", "fe80::5054:ff:fea1:b201", "10.10.10.5",
+	}, network.IPAddresses())
+}
+
+func (suite *NetworkTestSuite) TestNewNetworkMissingProcFiles() {
+	lo := mockInterface(1, "lo", 65536, "", net.FlagUp|net.FlagLoopback)
+	suite.mockProvider.On("Interfaces").Return([]net.Interface{lo}, nil)
+	suite.mockProvider.On("Addrs", lo).Return(mockAddrs("127.0.0.1/8"), nil)
+
+	network, err := NewNetwork(afero.NewMemMapFs(), suite.mockProvider)
+	suite.NoError(err)
+
+	suite.Len(network.Interfaces, 1)
+	suite.Equal("unknown", network.Interfaces[0].State)
+	suite.Equal([]*NetworkRoute{}, network.Routes)
+	suite.Equal(DNSConfig{Nameservers: []string{}, Search: []string{}, Options: []string{}}, network.DNS)
+}
+
+func (suite *NetworkTestSuite) TestNewNetworkErrors() {
+	suite.mockProvider.On("Interfaces").Return(nil, errors.New("netlink error"))
+
+	_, err := NewNetwork(suite.fs, suite.mockProvider)
+	suite.EqualError(err, "error listing the network interfaces: netlink error")
+}
+
+func (suite *NetworkTestSuite) TestNewNetworkOptionalDetailsErrors() {
+	suite.mockInterfaces()
+
+	invalidRoute := []byte("eth0\tXYZ\t00000000\t0001\t0\t0\t0\t00000000\t0\t0\t0\n")
+	_ = afero.WriteFile(suite.fs, "/proc/net/route", invalidRoute, 0644)
+	fs := failingOpenFs{
+		Fs:    suite.fs,
+		paths: []string{"/proc/net/bonding", "/proc/net/vlan/config", "/proc/net/ipv6_route", "/etc/resolv.conf"},
+	}
+
+	network, err := NewNetwork(fs, suite.mockProvider)
+	suite.NoError(err)
+
+	suite.Len(network.Interfaces, 5)
+	for _, networkInterface := range network.Interfaces {
+		suite.Nil(networkInterface.Bonding)
+		suite.Nil(networkInterface.VLAN)
+		suite.Empty(networkInterface.Master)
+	}
+	suite.Equal([]*NetworkRoute{}, network.Routes)
+	suite.Equal(DNSConfig{Nameservers: []string{}, Search: []string{}, Options: []string{}}, network.DNS)
+	suite.Equal([]string{
+		"127.0.0.1", "::1", "192.168.1.20", "fd00::20", "fe80::5054:ff:fea1:b201", "10.10.10.5",
+	}, network.IPAddresses())
+}
@@ -7,6 +7,9 @@ import (
 	sapcontrol "github.com/trento-project/agent/internal/core/sapsystem/sapcontrolapi"
 )
 
+// ErrWebServiceUnavailable is returned when the sapstartsrv web service calls fail, usually because it is down
+var ErrWebServiceUnavailable = errors.New("SAPControl web service error")
+
 type SAPControl struct {
 	Processes  []*sapcontrol.OSProcess
 	Instances  []*sapcontrol.SAPInstance
@@ -16,17 +19,17 @@ type SAPControl struct {
 func NewSAPControl(w sapcontrol.WebService) (*SAPControl, error) {
 	properties, err := w.GetInstanceProperties()
 	if err != nil {
-		return nil, errors.Wrap(err, "SAPControl web service error")
+		return nil, fmt.Errorf("%w: %s", ErrWebServiceUnavailable, err)
 	}
 
 	processes, err := w.GetProcessList()
 	if err != nil {
-		return nil, errors.Wrap(err, "SAPControl web service error")
+		return nil, fmt.Errorf("%w: %s", ErrWebServiceUnavailable, err)
 	}
 
 	instances, err := w.GetSystemInstanceList()
 	if err != nil {
-		return nil, errors.Wrap(err, "SAPControl web service error")
+		return nil, fmt.Errorf("%w: %s", ErrWebServiceUnavailable, err)
 	}
 
 	return &SAPControl{
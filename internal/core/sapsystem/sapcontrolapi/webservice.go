@@ -19,10 +19,16 @@ type WebService interface {
 	GetInstanceProperties() (*GetInstancePropertiesResponse, error)
 	GetProcessList() (*GetProcessListResponse, error)
 	GetSystemInstanceList() (*GetSystemInstanceListResponse, error)
+	GetVersionInfo() (*GetVersionInfoResponse, error)
+	HACheckConfig() (*HACheckConfigResponse, error)
+	HAGetFailoverConfig() (*HAGetFailoverConfigResponse, error)
+	EnqGetStatistic() (*EnqGetStatisticResponse, error)
 }
 
 type STATECOLOR string
 type STATECOLOR_CODE int
+type HAVerificationState string
+type HACheckCategory string
 
 const (
 	STATECOLOR_GRAY   STATECOLOR = "SAPControl-GRAY"
@@ -36,6 +42,15 @@ const (
 	STATECOLOR_CODE_GREEN  STATECOLOR_CODE = 2
 	STATECOLOR_CODE_YELLOW STATECOLOR_CODE = 3
 	STATECOLOR_CODE_RED    STATECOLOR_CODE = 4
+
+	HAVerificationStateSuccess HAVerificationState = "SAPControl-HA-SUCCESS"
+	HAVerificationStateWarning HAVerificationState = "SAPControl-HA-WARNING"
+	HAVerificationStateError   HAVerificationState = "SAPControl-HA-ERROR"
+
+	HACheckCategorySAPConfiguration HACheckCategory = "SAPControl-SAP-CONFIGURATION"
+	HACheckCategorySAPState         HACheckCategory = "SAPControl-SAP-STATE"
+	HACheckCategoryHAConfiguration  HACheckCategory = "SAPControl-HA-CONFIGURATION"
+	HACheckCategoryHAState          HACheckCategory = "SAPControl-HA-STATE"
 )
 
 type GetInstanceProperties struct {
@@ -65,6 +80,73 @@ type GetSystemInstanceListResponse struct {
 	Instances []*SAPInstance `xml:"instance>item,omitempty" json:"instance>item,omitempty"`
 }
 
+type GetVersionInfo struct {
+	XMLName xml.Name `xml:"urn:SAPControl GetVersionInfo"`
+}
+
+type GetVersionInfoResponse struct {
+	XMLName      xml.Name               `xml:"urn:SAPControl GetVersionInfoResponse"`
+	InstanceInfo []*InstanceVersionInfo `xml:"version>item,omitempty" json:"version>item,omitempty"`
+}
+
+type HACheckConfig struct {
+	XMLName xml.Name `xml:"urn:SAPControl HACheckConfig"`
+}
+
+type HACheckConfigResponse struct {
+	XMLName xml.Name   `xml:"urn:SAPControl HACheckConfigResponse"`
+	Checks  []*HACheck `xml:"check>item,omitempty" json:"check>item,omitempty"`
+}
+
+type HAGetFailoverConfig struct {
+	XMLName xml.Name `xml:"urn:SAPControl HAGetFailoverConfig"`
+}
+
+type HAGetFailoverConfigResponse struct {
+	XMLName               xml.Name `xml:"urn:SAPControl HAGetFailoverConfigResponse"`
+	HAActive              bool     `xml:"HAActive,omitempty" json:"HAActive,omitempty"`
+	HAProductVersion      string   `xml:"HAProductVersion,omitempty" json:"HAProductVersion,omitempty"`
+	HASAPInterfaceVersion string   `xml:"HASAPInterfaceVersion,omitempty" json:"HASAPInterfaceVersion,omitempty"`
+	HADocumentation       string   `xml:"HADocumentation,omitempty" json:"HADocumentation,omitempty"`
+	HAActiveNode          string   `xml:"HAActiveNode,omitempty" json:"HAActiveNode,omitempty"`
+	HANodes               []string `xml:"HANodes>item,omitempty" json:"HANodes>item,omitempty"`
+}
+
+type EnqGetStatistic struct {
+	XMLName xml.Name `xml:"urn:SAPControl EnqGetStatistic"`
+}
+
+type EnqGetStatisticResponse struct {
+	XMLName            xml.Name   `xml:"urn:SAPControl EnqStatistic"`
+	OwnerNow           int64      `xml:"owner-now,omitempty" json:"owner-now,omitempty"`
+	OwnerHigh          int64      `xml:"owner-high,omitempty" json:"owner-high,omitempty"`
+	OwnerMax           int64      `xml:"owner-max,omitempty" json:"owner-max,omitempty"`
+	OwnerState         STATECOLOR `xml:"owner-state,omitempty" json:"owner-state,omitempty"`
+	ArgumentsNow       int64      `xml:"arguments-now,omitempty" json:"arguments-now,omitempty"`
+	ArgumentsHigh      int64      `xml:"arguments-high,omitempty" json:"arguments-high,omitempty"`
+	ArgumentsMax       int64      `xml:"arguments-max,omitempty" json:"arguments-max,omitempty"`
+	ArgumentsState     STATECOLOR `xml:"arguments-state,omitempty" json:"arguments-state,omitempty"`
+	LocksNow           int64      `xml:"locks-now,omitempty" json:"locks-now,omitempty"`
+	LocksHigh          int64      `xml:"locks-high,omitempty" json:"locks-high,omitempty"`
+	LocksMax           int64      `xml:"locks-max,omitempty" json:"locks-max,omitempty"`
+	LocksState         STATECOLOR `xml:"locks-state,omitempty" json:"locks-state,omitempty"`
+	EnqueueRequests    int64      `xml:"enqueue-requests,omitempty" json:"enqueue-requests,omitempty"`
+	EnqueueRejects     int64      `xml:"enqueue-rejects,omitempty" json:"enqueue-rejects,omitempty"`
+	EnqueueErrors      int64      `xml:"enqueue-errors,omitempty" json:"enqueue-errors,omitempty"`
+	DequeueRequests    int64      `xml:"dequeue-requests,omitempty" json:"dequeue-requests,omitempty"`
+	DequeueErrors      int64      `xml:"dequeue-errors,omitempty" json:"dequeue-errors,omitempty"`
+	DequeueAllRequests int64      `xml:"dequeue-all-requests,omitempty" json:"dequeue-all-requests,omitempty"`
+	CleanupRequests    int64      `xml:"cleanup-requests,omitempty" json:"cleanup-requests,omitempty"`
+	BackupRequests     int64      `xml:"backup-requests,omitempty" json:"backup-requests,omitempty"`
+	ReportingRequests  int64      `xml:"reporting-requests,omitempty" json:"reporting-requests,omitempty"`
+	CompressRequests   int64      `xml:"compress-requests,omitempty" json:"compress-requests,omitempty"`
+	VerifyRequests     int64      `xml:"verify-requests,omitempty" json:"verify-requests,omitempty"`
+	LockTime           float64    `xml:"lock-time,omitempty" json:"lock-time,omitempty"`
+	LockWaitTime       float64    `xml:"lock-wait-time,omitempty" json:"lock-wait-time,omitempty"`
+	ServerTime         float64    `xml:"server-time,omitempty" json:"server-time,omitempty"`
+	ReplicationState   STATECOLOR `xml:"replication-state,omitempty" json:"replication-state,omitempty"`
+}
+
 type OSProcess struct {
 	Name        string     `xml:"name,omitempty" json:"name,omitempty"`
 	Description string     `xml:"description,omitempty" json:"description,omitempty"`
@@ -91,6 +173,19 @@ type SAPInstance struct {
 	Dispstatus    STATECOLOR `xml:"dispstatus,omitempty" json:"dispstatus,omitempty"`
 }
 
+type InstanceVersionInfo struct {
+	Filename    string `xml:"Filename,omitempty" json:"Filename,omitempty"`
+	VersionInfo string `xml:"VersionInfo,omitempty" json:"VersionInfo,omitempty"`
+	Time        string `xml:"Time,omitempty" json:"Time,omitempty"`
+}
+
+type HACheck struct {
+	State       HAVerificationState `xml:"state,omitempty" json:"state,omitempty"`
+	Category    HACheckCategory     `xml:"category,omitempty" json:"category,omitempty"`
+	Description string              `xml:"description,omitempty" json:"description,omitempty"`
+	Comment     string              `xml:"comment,omitempty" json:"comment,omitempty"`
+}
+
 type webService struct {
 	client *soap.Client
 }
@@ -163,3 +258,53 @@ func (s *webService) GetSystemInstanceList() (*GetSystemInstanceListResponse, er
 
 	return response, nil
 }
+
+// GetVersionInfo returns the version information of the instance executables.
+func (s *webService) GetVersionInfo() (*GetVersionInfoResponse, error) {
+	request := &GetVersionInfo{}
+	response := &GetVersionInfoResponse{}
+	err := s.client.Call("''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// HACheckConfig checks the high availability configuration and status of the system
+// using the HA interface of the cluster software.
+func (s *webService) HACheckConfig() (*HACheckConfigResponse, error) {
+	request := &HACheckConfig{}
+	response := &HACheckConfigResponse{}
+	err := s.client.Call("''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// HAGetFailoverConfig returns the high availability failover configuration and status
+// reported by the HA interface of the cluster software.
+func (s *webService) HAGetFailoverConfig() (*HAGetFailoverConfigResponse, error) {
+	request := &HAGetFailoverConfig{}
+	response := &HAGetFailoverConfigResponse{}
+	err := s.client.Call("''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// EnqGetStatistic returns the enqueue server statistics.
+func (s *webService) EnqGetStatistic() (*EnqGetStatisticResponse, error) {
+	request := &EnqGetStatistic{}
+	response := &EnqGetStatisticResponse{}
+	err := s.client.Call("''", request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
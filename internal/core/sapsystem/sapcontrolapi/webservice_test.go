@@ -0,0 +1,73 @@
+//nolint:exhaustruct
+package sapcontrolapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/hooklift/gowsdl/soap"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/test/helpers"
+)
+
+type WebServiceTestSuite struct {
+	suite.Suite
+}
+
+func TestWebServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WebServiceTestSuite))
+}
+
+func (suite *WebServiceTestSuite) newFakeWebService(fixture string) (*webService, func()) {
+	body, err := os.ReadFile(helpers.GetFixturePath(fixture))
+	suite.NoError(err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		_, _ = w.Write(body)
+	}))
+
+	return &webService{client: soap.NewClient(server.URL)}, server.Close
+}
+
+func (suite *WebServiceTestSuite) TestEnqGetStatistic() {
+	webService, closeServer := suite.newFakeWebService("discovery/sap_system/enq_get_statistic_response.xml")
+	defer closeServer()
+
+	response, err := webService.EnqGetStatistic()
+
+	suite.NoError(err)
+	suite.Equal("EnqStatistic", response.XMLName.Local)
+	suite.Equal(&EnqGetStatisticResponse{
+		XMLName:            response.XMLName,
+		OwnerNow:           0,
+		OwnerHigh:          8,
+		OwnerMax:           12600,
+		OwnerState:         STATECOLOR_GREEN,
+		ArgumentsNow:       0,
+		ArgumentsHigh:      41,
+		ArgumentsMax:       12600,
+		ArgumentsState:     STATECOLOR_GREEN,
+		LocksNow:           0,
+		LocksHigh:          41,
+		LocksMax:           12600,
+		LocksState:         STATECOLOR_GREEN,
+		EnqueueRequests:    2387,
+		EnqueueRejects:     3,
+		EnqueueErrors:      0,
+		DequeueRequests:    1921,
+		DequeueErrors:      0,
+		DequeueAllRequests: 1410,
+		CleanupRequests:    1,
+		BackupRequests:     12,
+		ReportingRequests:  0,
+		CompressRequests:   0,
+		VerifyRequests:     0,
+		LockTime:           0.5234,
+		LockWaitTime:       0,
+		ServerTime:         0.4179,
+		ReplicationState:   STATECOLOR_GREEN,
+	}, response)
+}
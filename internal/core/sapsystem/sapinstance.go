@@ -17,6 +17,21 @@ var (
 	databaseFeatures         = regexp.MustCompile("HDB.*")
 	applicationFeatures      = regexp.MustCompile("MESSAGESERVER.*|ENQREP|ABAP.*")
 	diagnosticsAgentFeatures = regexp.MustCompile("SMDAGENT")
+
+	// instance names used when sapstartsrv is down and the features are not available
+	databaseInstanceNames         = regexp.MustCompile("^HDB[0-9]{2}$")
+	applicationInstanceNames      = regexp.MustCompile("^(ASCS|SCS|ERS|DVEBMGS|D|J)[0-9]{2}$")
+	diagnosticsAgentInstanceNames = regexp.MustCompile("^SMDA[0-9]{2}$")
+)
+
+// State of the sapstartsrv web service of an instance
+const (
+	// WebServiceRunning means sapstartsrv answers and the instance processes are running
+	WebServiceRunning string = "running"
+	// WebServiceStopped means sapstartsrv answers but all the instance processes are stopped
+	WebServiceStopped string = "stopped"
+	// WebServiceDown means sapstartsrv doesn't answer, so the instance is discovered from its files
+	WebServiceDown string = "sapstartsrv_down"
 )
 
 type SystemReplication map[string]interface{}
@@ -28,13 +43,16 @@ type SAPInstance struct {
 	Type       SystemType
 	Host       string
 	SAPControl *SAPControl
+	// WebServiceState tells if the instance is running, stopped, or if sapstartsrv is down,
+	// in which case SAPControl is not set
+	WebServiceState string
 	// Only for Database type
 	SystemReplication SystemReplication
 	HostConfiguration HostConfiguration
 	HdbnsutilSRstate  HdbnsutilSRstate
 }
 
-func NewSAPInstance(w sapcontrolapi.WebService, executor utils.CommandExecutor) (*SAPInstance, error) {
+func NewSAPInstance(w sapcontrolapi.WebService, executor utils.CommandExecutor, sysPath string) (*SAPInstance, error) {
 	host, err := os.Hostname()
 	if err != nil {
 		return nil, err
@@ -60,25 +78,71 @@ func NewSAPInstance(w sapcontrolapi.WebService, executor utils.CommandExecutor)
 		SAPControl:        scontrol,
 		Name:              instanceName,
 		Type:              instanceType,
+		WebServiceState:   webServiceState(scontrol),
 		SystemReplication: nil,
 		HostConfiguration: nil,
 		HdbnsutilSRstate:  nil,
 	}
 
-	if instanceType == Database {
+	if instanceType == Database && sapInstance.WebServiceState == WebServiceRunning {
 		sid, err := sapInstance.SAPControl.findProperty("SAPSYSTEMNAME")
 		if err != nil {
 			return nil, errors.Wrap(err, "Error finding the SAP instance sid")
 		}
 
-		sapInstance.SystemReplication = systemReplicationStatus(executor, sid, sapInstance.Name)
-		sapInstance.HostConfiguration = landscapeHostConfiguration(executor, sid, sapInstance.Name)
-		sapInstance.HdbnsutilSRstate = hdbnsutilSrstate(executor, sid, sapInstance.Name)
+		instancePath := path.Join(sysPath, sapInstance.Name)
+		sapInstance.SystemReplication = systemReplicationStatus(executor, sid, instancePath)
+		sapInstance.HostConfiguration = landscapeHostConfiguration(executor, sid, instancePath)
+		sapInstance.HdbnsutilSRstate = hdbnsutilSrstate(executor, sid, instancePath)
 	}
 
 	return sapInstance, nil
 }
 
+// NewOfflineSAPInstance creates an instance with sapstartsrv down. The type comes from the instance
+// name, as the features are only available through the web service
+func NewOfflineSAPInstance(instanceName string) (*SAPInstance, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SAPInstance{
+		Host:              host,
+		SAPControl:        nil,
+		Name:              instanceName,
+		Type:              detectTypeFromName(instanceName),
+		WebServiceState:   WebServiceDown,
+		SystemReplication: nil,
+		HostConfiguration: nil,
+		HdbnsutilSRstate:  nil,
+	}, nil
+}
+
+// webServiceState returns stopped if none of the instance processes is running
+func webServiceState(sapControl *SAPControl) string {
+	for _, process := range sapControl.Processes {
+		if process.Dispstatus != sapcontrolapi.STATECOLOR_GRAY {
+			return WebServiceRunning
+		}
+	}
+
+	return WebServiceStopped
+}
+
+func detectTypeFromName(instanceName string) SystemType {
+	switch {
+	case databaseInstanceNames.MatchString(instanceName):
+		return Database
+	case applicationInstanceNames.MatchString(instanceName):
+		return Application
+	case diagnosticsAgentInstanceNames.MatchString(instanceName):
+		return DiagnosticsAgent
+	default:
+		return Unknown
+	}
+}
+
 func detectType(sapControl *SAPControl) (SystemType, error) {
 	sapLocalhost, err := sapControl.findProperty("SAPLOCALHOST")
 	if err != nil {
@@ -103,9 +167,9 @@ func detectType(sapControl *SAPControl) (SystemType, error) {
 	return Unknown, nil
 }
 
-func runPythonSupport(executor utils.CommandExecutor, sid, instance, script string) map[string]interface{} {
+func runPythonSupport(executor utils.CommandExecutor, sid, instancePath, script string) map[string]interface{} {
 	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
-	cmdPath := path.Join(sapInstallationPath, sid, instance, "exe/python_support", script)
+	cmdPath := path.Join(instancePath, "exe/python_support", script)
 	cmd := fmt.Sprintf("python %s --sapcontrol=1", cmdPath)
 	// Even with a error return code, some data is available
 	srData, err := executor.Exec("su", "-lc", cmd, user)
@@ -117,17 +181,17 @@ func runPythonSupport(executor utils.CommandExecutor, sid, instance, script stri
 	return dataMap
 }
 
-func systemReplicationStatus(executor utils.CommandExecutor, sid, instance string) map[string]interface{} {
-	return runPythonSupport(executor, sid, instance, "systemReplicationStatus.py")
+func systemReplicationStatus(executor utils.CommandExecutor, sid, instancePath string) map[string]interface{} {
+	return runPythonSupport(executor, sid, instancePath, "systemReplicationStatus.py")
 }
 
-func landscapeHostConfiguration(executor utils.CommandExecutor, sid, instance string) map[string]interface{} {
-	return runPythonSupport(executor, sid, instance, "landscapeHostConfiguration.py")
+func landscapeHostConfiguration(executor utils.CommandExecutor, sid, instancePath string) map[string]interface{} {
+	return runPythonSupport(executor, sid, instancePath, "landscapeHostConfiguration.py")
 }
 
-func hdbnsutilSrstate(executor utils.CommandExecutor, sid, instance string) map[string]interface{} {
+func hdbnsutilSrstate(executor utils.CommandExecutor, sid, instancePath string) map[string]interface{} {
 	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
-	cmdPath := path.Join(sapInstallationPath, sid, instance, "exe", "hdbnsutil")
+	cmdPath := path.Join(instancePath, "exe", "hdbnsutil")
 	cmd := fmt.Sprintf("%s -sr_state -sapcontrol=1", cmdPath)
 	srData, err := executor.Exec("su", "-lc", cmd, user)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"net"
 	"path"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/go-envparse"
@@ -30,10 +31,14 @@ const (
 
 const (
 	sapInstallationPath  string = "/usr/sap"
+	sapMountPath         string = "/sapmnt"
 	sapIdentifierPattern string = "^[A-Z][A-Z0-9]{2}$" // PRD, HA1, etc
 	sapInstancePattern   string = "^[A-Z]+([0-9]{2})$" // HDB00, ASCS00, ERS10, etc
 	sapDefaultProfile    string = "DEFAULT.PFL"
 	sappfparCmd          string = "sappfpar SAPSYSTEMNAME SAPGLOBALHOST SAPFQDN SAPDBHOST dbs/hdb/dbname dbs/hdb/schema rdisp/msp/msserv rdisp/msserv_internal name=%s" //nolint:lll
+
+	sapServicesFile    string = "sapservices"
+	sapServicesPattern string = `pf=(\S+)` // pf=/usr/sap/PRD/SYS/profile/PRD_ASCS00_sapprdas
 )
 
 type SAPSystemsList []*SAPSystem
@@ -56,6 +61,25 @@ type SAPSystem struct {
 
 type SAPProfile map[string]string
 
+// DiscoveryPaths configures where the SAP systems are discovered
+type DiscoveryPaths struct {
+	// Roots are the folders with a folder for each SAP system, like /usr/sap/PRD
+	Roots []string
+	// Overrides replace the paths of the system with the given SID. A system folder
+	// outside of the roots adds the system to the discovery
+	Overrides map[string]SystemPaths
+}
+
+// SystemPaths are the folders of a SAP system. The profile and global folders are
+// usually the SYS/profile and SYS/global links to the shared /sapmnt/<SID> folder
+type SystemPaths struct {
+	// SID is set by the discovery, the system folder name is not the SID when it is overridden
+	SID     string `mapstructure:"-"`
+	System  string `mapstructure:"system"`
+	Profile string `mapstructure:"profile"`
+	Global  string `mapstructure:"global"`
+}
+
 type DatabaseData struct {
 	Database  string
 	Container string
@@ -72,22 +96,33 @@ func Md5sum(data string) string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(data))) //nolint:gosec
 }
 
+func NewDefaultDiscoveryPaths() DiscoveryPaths {
+	return DiscoveryPaths{
+		Roots:     []string{sapInstallationPath},
+		Overrides: map[string]SystemPaths{},
+	}
+}
+
 func NewSAPSystemsList(
+	fs afero.Fs,
 	executor utils.CommandExecutor,
 	webService sapcontrolapi.WebServiceConnector,
+	discoveryPaths DiscoveryPaths,
 ) (SAPSystemsList, error) {
 
 	var systems = SAPSystemsList{}
 
-	appFS := afero.NewOsFs()
-	systemPaths, err := findSystems(appFS)
+	systemPaths, err := findSystems(fs, discoveryPaths)
 	if err != nil {
 		return systems, errors.Wrap(err, "Error walking the path")
 	}
 
+	sapServicesProfiles := getSAPServicesProfiles(fs, discoveryPaths.Roots)
+
 	// Find systems
-	for _, sysPath := range systemPaths {
-		system, err := NewSAPSystem(appFS, executor, webService, sysPath)
+	for _, sysPaths := range systemPaths {
+		paths := resolveSystemPaths(fs, sysPaths, discoveryPaths.Overrides[sysPaths.SID])
+		system, err := NewSAPSystem(fs, executor, webService, paths, sapServicesProfiles)
 		if err != nil {
 			log.Printf("Error discovering a SAP system: %s", err)
 			continue
@@ -98,6 +133,23 @@ func NewSAPSystemsList(
 	return systems, nil
 }
 
+// FindSystemPaths returns the resolved paths of the SAP system with the given SID,
+// looking for it in the installation roots and the overrides as the discovery does
+func FindSystemPaths(fs afero.Fs, discoveryPaths DiscoveryPaths, sid string) (SystemPaths, error) {
+	systemPaths, err := findSystems(fs, discoveryPaths)
+	if err != nil {
+		return SystemPaths{}, errors.Wrap(err, "Error walking the path")
+	}
+
+	for _, sysPaths := range systemPaths {
+		if sysPaths.SID == sid {
+			return resolveSystemPaths(fs, sysPaths, discoveryPaths.Overrides[sid]), nil
+		}
+	}
+
+	return SystemPaths{}, errors.Errorf("SAP system %s not found", sid)
+}
+
 func (sl SAPSystemsList) GetSIDsString() string {
 	sidString := []string{}
 
@@ -112,21 +164,22 @@ func NewSAPSystem(
 	fs afero.Fs,
 	executor utils.CommandExecutor,
 	webService sapcontrolapi.WebServiceConnector,
-	sysPath string,
+	paths SystemPaths,
+	sapServicesProfiles []string,
 ) (*SAPSystem, error) {
 
 	var systemType SystemType
 	instances := []*SAPInstance{}
 
-	sid := sysPath[strings.LastIndex(sysPath, "/")+1:]
-	profilePath := getProfilePath(sysPath)
+	sid := paths.SID
+	profilePath := path.Join(paths.Profile, sapDefaultProfile)
 	profile, err := getProfileData(fs, profilePath)
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
-	instPaths, err := findInstances(fs, sysPath)
+	instPaths, err := findInstances(fs, paths.System)
 	if err != nil {
 		log.Error(err)
 		return nil, err
@@ -135,17 +188,34 @@ func NewSAPSystem(
 	// Find instances
 	for _, instPath := range instPaths {
 		webService := webService.New(instPath[1])
-		instance, err := NewSAPInstance(webService, executor)
-		if err != nil {
-			log.Errorf("Error discovering a SAP instance: %s", err)
+		instance, err := NewSAPInstance(webService, executor, paths.System)
+		switch {
+		case errors.Is(err, ErrWebServiceUnavailable):
+			log.Warnf("Error discovering the SAP instance %s using sapstartsrv: %s", instPath[0], err)
+
+			if !isInstanceInstalled(fs, sapServicesProfiles, paths.Profile, sid, instPath[0]) {
+				log.Errorf("SAP instance %s is not registered in %s and has no profile", instPath[0], sapServicesFile)
+				continue
+			}
+
+			instance, err = NewOfflineSAPInstance(instPath[0])
+			if err != nil {
+				log.Errorf("Error discovering a SAP instance: %s", err)
+				continue
+			}
+		case err != nil:
+			log.Errorf("Error discovering the SAP instance %s: %s", instPath[0], err)
 			continue
 		}
 
-		systemType = instance.Type
+		// the instances with an unknown type don't change the type of the system
+		if instance.Type != Unknown {
+			systemType = instance.Type
+		}
 		instances = append(instances, instance)
 	}
 
-	systemID, err := detectSystemID(fs, executor, systemType, sid)
+	systemID, err := detectSystemID(fs, executor, systemType, sid, paths.Global)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +231,7 @@ func NewSAPSystem(
 	}
 
 	if systemType == Database {
-		databaseList, err := getDatabases(fs, sid)
+		databaseList, err := getDatabases(fs, paths.Global)
 		if err != nil {
 			log.Errorf("Error getting the database list: %s", err)
 		} else {
@@ -179,32 +249,120 @@ func NewSAPSystem(
 	return system, nil
 }
 
-// Find the installed SAP instances in the /usr/sap folder
-// It returns a list of paths where SAP system is found
-func findSystems(fs afero.Fs) ([]string, error) {
-	var systems = []string{}
+// Find the installed SAP systems in the installation roots, /usr/sap by default
+// It returns the SID and the folder of each SAP system found
+func findSystems(fs afero.Fs, discoveryPaths DiscoveryPaths) ([]SystemPaths, error) {
+	var systems = []SystemPaths{}
+	found := make(map[string]bool)
+
+	reSAPIdentifier := regexp.MustCompile(sapIdentifierPattern)
+
+	for _, root := range discoveryPaths.Roots {
+		exists, _ := afero.DirExists(fs, root)
+		if !exists {
+			log.Printf("SAP installation not found in %s", root)
+			continue
+		}
+
+		files, err := afero.ReadDir(fs, root)
+		if err != nil {
+			return nil, err
+		}
 
-	exists, _ := afero.DirExists(fs, sapInstallationPath)
-	if !exists {
-		log.Print("SAP installation not found")
-		return systems, nil
+		for _, f := range files {
+			if !reSAPIdentifier.MatchString(f.Name()) {
+				continue
+			}
+			// the same SID can't be installed twice, the first root has precedence
+			if found[f.Name()] {
+				log.Warnf("SAP system %s already found, skipping %s", f.Name(), path.Join(root, f.Name()))
+				continue
+			}
+			log.Printf("New SAP system installation found: %s", f.Name())
+			found[f.Name()] = true
+			systems = append(systems, SystemPaths{SID: f.Name(), System: path.Join(root, f.Name())})
+		}
 	}
 
-	files, err := afero.ReadDir(fs, sapInstallationPath)
-	if err != nil {
-		return nil, err
+	return overrideSystems(systems, discoveryPaths.Overrides), nil
+}
+
+// overrideSystems replaces the folder of the systems with an overridden system folder,
+// and adds the overridden systems not found in the roots
+func overrideSystems(systems []SystemPaths, overrides map[string]SystemPaths) []SystemPaths {
+	found := make(map[string]bool)
+	for index, sysPaths := range systems {
+		found[sysPaths.SID] = true
+		if override := overrides[sysPaths.SID].System; override != "" {
+			systems[index].System = override
+		}
 	}
 
-	reSAPIdentifier := regexp.MustCompile(sapIdentifierPattern)
+	sids := []string{}
+	for sid, override := range overrides {
+		if !found[sid] && override.System != "" {
+			sids = append(sids, sid)
+		}
+	}
+	sort.Strings(sids)
 
-	for _, f := range files {
-		if reSAPIdentifier.MatchString(f.Name()) {
-			log.Printf("New SAP system installation found: %s", f.Name())
-			systems = append(systems, path.Join(sapInstallationPath, f.Name()))
+	for _, sid := range sids {
+		log.Printf("New SAP system installation configured: %s", sid)
+		systems = append(systems, SystemPaths{SID: sid, System: overrides[sid].System})
+	}
+
+	return systems
+}
+
+// resolveSystemPaths returns the paths of the system found, using the overrides when set
+func resolveSystemPaths(fs afero.Fs, system SystemPaths, overrides SystemPaths) SystemPaths {
+	paths := SystemPaths{
+		SID:     system.SID,
+		System:  system.System,
+		Profile: overrides.Profile,
+		Global:  overrides.Global,
+	}
+
+	if paths.Profile == "" {
+		paths.Profile = resolveSYSLink(fs, paths.SID, paths.System, "profile")
+	}
+
+	if paths.Global == "" {
+		paths.Global = resolveSYSLink(fs, paths.SID, paths.System, "global")
+	}
+
+	return paths
+}
+
+// resolveSYSLink returns the folder the SYS/<name> link of the system points to.
+// The link is resolved through the filesystem, so absolute links keep working when
+// the filesystem is not the root one, like a host filesystem mounted in a container.
+// If the link is missing or broken, the standard /sapmnt/<SID>/<name> folder is used
+func resolveSYSLink(fs afero.Fs, sid, sysPath, name string) string {
+	linkPath := path.Join(sysPath, "SYS", name)
+
+	if reader, ok := fs.(afero.LinkReader); ok {
+		if target, err := reader.ReadlinkIfPossible(linkPath); err == nil {
+			if !path.IsAbs(target) {
+				target = path.Join(path.Dir(linkPath), target)
+			}
+			if exists, _ := afero.DirExists(fs, target); exists {
+				return target
+			}
 		}
 	}
 
-	return systems, nil
+	if exists, _ := afero.DirExists(fs, linkPath); exists {
+		return linkPath
+	}
+
+	sharedPath := path.Join(sapMountPath, sid, name)
+	if exists, _ := afero.DirExists(fs, sharedPath); exists {
+		log.Debugf("%s not found, using %s", linkPath, sharedPath)
+		return sharedPath
+	}
+
+	return linkPath
 }
 
 // Find the installed SAP instances in the /usr/sap/${SID} folder
@@ -227,8 +385,45 @@ func findInstances(fs afero.Fs, sapPath string) ([][]string, error) {
 	return instances, nil
 }
 
-func getProfilePath(sysPath string) string {
-	return path.Join(sysPath, "SYS", "profile", sapDefaultProfile)
+// getSAPServicesProfiles returns the instance profiles registered in the sapservices file
+// of the installation roots, which lists the sapstartsrv services, running or not
+func getSAPServicesProfiles(fs afero.Fs, roots []string) []string {
+	profiles := []string{}
+	reSAPServices := regexp.MustCompile(sapServicesPattern)
+
+	for _, root := range roots {
+		sapServices, err := afero.ReadFile(fs, path.Join(root, sapServicesFile))
+		if err != nil {
+			log.Debugf("Error reading the sapservices file: %s", err)
+			continue
+		}
+
+		for _, matches := range reSAPServices.FindAllStringSubmatch(string(sapServices), -1) {
+			profiles = append(profiles, matches[1])
+		}
+	}
+
+	return profiles
+}
+
+// isInstanceInstalled tells if an instance folder belongs to an installed instance, registered in the
+// sapservices file or with an instance profile, like PRD_ASCS00_sapprdas, in the profile folder
+func isInstanceInstalled(fs afero.Fs, sapServicesProfiles []string, profilePath, sid, instanceName string) bool {
+	profilePrefix := fmt.Sprintf("%s_%s_", sid, instanceName)
+
+	for _, profile := range sapServicesProfiles {
+		if strings.HasPrefix(path.Base(profile), profilePrefix) {
+			return true
+		}
+	}
+
+	profiles, err := afero.Glob(fs, path.Join(profilePath, profilePrefix+"*"))
+	if err != nil {
+		log.Debugf("Error looking for the %s instance profile: %s", instanceName, err)
+		return false
+	}
+
+	return len(profiles) > 0
 }
 
 // Get SAP profile file content
@@ -276,10 +471,15 @@ func getDBAddress(system *SAPSystem) (string, error) {
 	return "", fmt.Errorf("could not get any IPv4 address")
 }
 
-func detectSystemID(fs afero.Fs, executor utils.CommandExecutor, sType SystemType, sid string) (string, error) {
+func detectSystemID(
+	fs afero.Fs,
+	executor utils.CommandExecutor,
+	sType SystemType,
+	sid, globalPath string,
+) (string, error) {
 	switch sType {
 	case Database:
-		return getUniqueIDHana(fs, sid)
+		return getUniqueIDHana(fs, globalPath)
 	case Application:
 		return getUniqueIDApplication(executor, sid)
 	case DiagnosticsAgent:
@@ -291,9 +491,8 @@ func detectSystemID(fs afero.Fs, executor utils.CommandExecutor, sType SystemTyp
 	}
 }
 
-func getUniqueIDHana(fs afero.Fs, sid string) (string, error) {
-	nameserverConfigPath := fmt.Sprintf(
-		"/usr/sap/%s/SYS/global/hdb/custom/config/nameserver.ini", sid)
+func getUniqueIDHana(fs afero.Fs, globalPath string) (string, error) {
+	nameserverConfigPath := path.Join(globalPath, "hdb/custom/config/nameserver.ini")
 	nameserver, err := fs.Open(nameserverConfigPath)
 	if err != nil {
 		return "", errors.Wrap(err, "could not open the nameserver configuration file")
@@ -345,9 +544,8 @@ func getUniqueIDDiagnostics(fs afero.Fs) (string, error) {
 // # DATABASE:CONTAINER:USER:GROUP:USERID:GROUPID:HOST:SQLPORT:ACTIVE
 // PRD::::::hana02:30015:yes
 // DEV::::::hana02:30044:yes
-func getDatabases(fs afero.Fs, sid string) ([]*DatabaseData, error) {
-	databasesListPath := fmt.Sprintf(
-		"/usr/sap/%s/SYS/global/hdb/mdc/databases.lst", sid)
+func getDatabases(fs afero.Fs, globalPath string) ([]*DatabaseData, error) {
+	databasesListPath := path.Join(globalPath, "hdb/mdc/databases.lst")
 	databasesListFile, err := fs.Open(databasesListPath)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not open the databases list file")
@@ -2,6 +2,7 @@
 package sapsystem
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -114,7 +115,8 @@ func (suite *SAPSystemTestSuite) TestNewSAPSystem() {
 	cmd := fmt.Sprintf(sappfparCmd, "DEV")
 	mockCommand.On("Exec", "su", "-lc", cmd, "devadm").Return(mockSappfpar(), nil)
 
-	system, err := NewSAPSystem(appFS, mockCommand, mockWebServiceConnector, "/usr/sap/DEV")
+	paths := resolveSystemPaths(appFS, SystemPaths{SID: "DEV", System: "/usr/sap/DEV"}, SystemPaths{})
+	system, err := NewSAPSystem(appFS, mockCommand, mockWebServiceConnector, paths, []string{})
 
 	suite.Equal(Unknown, system.Type)
 	suite.Contains(system.Instances[0].Name, "ASCS01")
@@ -123,6 +125,161 @@ func (suite *SAPSystemTestSuite) TestNewSAPSystem() {
 	suite.NoError(err)
 }
 
+func (suite *SAPSystemTestSuite) TestNewSAPSystemSAPStartSrvDown() {
+	mockWebService := new(sapControlMocks.WebService)
+	mockWebService.On("GetInstanceProperties").Return(
+		nil, errors.New("dial unix /tmp/.sapstream50113: connect: no such file or directory"))
+
+	mockWebServiceConnector := new(sapControlMocks.WebServiceConnector)
+	mockWebServiceConnector.On("New", "01").Return(mockWebService)
+	mockWebServiceConnector.On("New", "02").Return(mockWebService)
+	mockWebServiceConnector.On("New", "99").Return(mockWebService)
+
+	mockCommand := new(utilsMocks.CommandExecutor)
+	mockCommand.On("Exec", "su", "-lc", fmt.Sprintf(sappfparCmd, "DEV"), "devadm").Return(mockSappfpar(), nil)
+
+	appFS := afero.NewMemMapFs()
+	for _, dir := range []string{"/usr/sap/DEV/ASCS01", "/usr/sap/DEV/ERS02", "/usr/sap/DEV/D99"} {
+		err := appFS.MkdirAll(dir, 0755)
+		suite.NoError(err)
+	}
+
+	profileFile, _ := os.Open(helpers.GetFixturePath("discovery/sap_system/sap_profile_default"))
+	profileContent, _ := io.ReadAll(profileFile)
+	err := afero.WriteFile(appFS, "/usr/sap/DEV/SYS/profile/DEFAULT.PFL", profileContent, 0644)
+	suite.NoError(err)
+	err = afero.WriteFile(appFS, "/usr/sap/DEV/SYS/profile/DEV_ERS02_sapdevers", []byte("SAPSYSTEM = 02\n"), 0644)
+	suite.NoError(err)
+
+	sapServices := `#!/bin/sh
+LD_LIBRARY_PATH=/usr/sap/DEV/ASCS01/exe:$LD_LIBRARY_PATH; export LD_LIBRARY_PATH; \
+/usr/sap/DEV/ASCS01/exe/sapstartsrv pf=/usr/sap/DEV/SYS/profile/DEV_ASCS01_sapdevas -D -u devadm
+`
+	err = afero.WriteFile(appFS, "/usr/sap/sapservices", []byte(sapServices), 0644)
+	suite.NoError(err)
+
+	paths := resolveSystemPaths(appFS, SystemPaths{SID: "DEV", System: "/usr/sap/DEV"}, SystemPaths{})
+	sapServicesProfiles := getSAPServicesProfiles(appFS, []string{"/usr/sap"})
+	system, err := NewSAPSystem(appFS, mockCommand, mockWebServiceConnector, paths, sapServicesProfiles)
+
+	suite.NoError(err)
+	suite.Equal(Application, system.Type)
+	suite.Equal("089d1a278481b86e821237f8e98e6de7", system.ID)
+	suite.Equal("192.168.140.12", system.DBAddress)
+	suite.Len(system.Instances, 2)
+
+	for index, name := range []string{"ASCS01", "ERS02"} {
+		suite.Equal(name, system.Instances[index].Name)
+		suite.Equal(Application, system.Instances[index].Type)
+		suite.Equal(WebServiceDown, system.Instances[index].WebServiceState)
+		suite.Nil(system.Instances[index].SAPControl)
+	}
+}
+
+func (suite *SAPSystemTestSuite) TestNewSAPSystemInstanceErrors() {
+	downWebService := new(sapControlMocks.WebService)
+	downWebService.On("GetInstanceProperties").Return(
+		nil, errors.New("dial unix /tmp/.sapstream50113: connect: no such file or directory"))
+
+	// sapstartsrv answers, but the instance name is missing
+	noNameWebService := new(sapControlMocks.WebService)
+	noNameWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+		Properties: []*sapcontrol.InstanceProperty{{Property: "SAPSYSTEMNAME", Propertytype: "string", Value: "DEV"}},
+	}, nil)
+	noNameWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{}, nil)
+	noNameWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{}, nil)
+
+	mockWebServiceConnector := new(sapControlMocks.WebServiceConnector)
+	mockWebServiceConnector.On("New", "01").Return(downWebService)
+	mockWebServiceConnector.On("New", "02").Return(noNameWebService)
+	mockWebServiceConnector.On("New", "99").Return(fakeNewWebService("W99"))
+
+	mockCommand := new(utilsMocks.CommandExecutor)
+	mockCommand.On("Exec", "su", "-lc", fmt.Sprintf(sappfparCmd, "DEV"), "devadm").Return(mockSappfpar(), nil)
+
+	appFS := afero.NewMemMapFs()
+	for _, dir := range []string{"/opt/sap/DEV/ASCS01", "/opt/sap/DEV/ERS02", "/opt/sap/DEV/W99"} {
+		err := appFS.MkdirAll(dir, 0755)
+		suite.NoError(err)
+	}
+
+	profileFile, _ := os.Open(helpers.GetFixturePath("discovery/sap_system/sap_profile_default"))
+	profileContent, _ := io.ReadAll(profileFile)
+	err := afero.WriteFile(appFS, "/opt/sap/DEV/SYS/profile/DEFAULT.PFL", profileContent, 0644)
+	suite.NoError(err)
+	err = afero.WriteFile(appFS, "/opt/sap/DEV/SYS/profile/DEV_ERS02_sapdevers", []byte("SAPSYSTEM = 02\n"), 0644)
+	suite.NoError(err)
+
+	sapServices := "/opt/sap/DEV/ASCS01/exe/sapstartsrv pf=/opt/sap/DEV/SYS/profile/DEV_ASCS01_sapdevas -D -u devadm\n"
+	err = afero.WriteFile(appFS, "/opt/sap/sapservices", []byte(sapServices), 0644)
+	suite.NoError(err)
+
+	paths := resolveSystemPaths(appFS, SystemPaths{SID: "DEV", System: "/opt/sap/DEV"}, SystemPaths{})
+	sapServicesProfiles := getSAPServicesProfiles(appFS, []string{"/usr/sap", "/opt/sap"})
+	system, err := NewSAPSystem(appFS, mockCommand, mockWebServiceConnector, paths, sapServicesProfiles)
+
+	suite.NoError(err)
+	// the unknown W99 instance, discovered the last, doesn't reset the system type
+	suite.Equal(Application, system.Type)
+	suite.Len(system.Instances, 2)
+
+	suite.Equal("ASCS01", system.Instances[0].Name)
+	suite.Equal(WebServiceDown, system.Instances[0].WebServiceState)
+
+	// ERS02 has a profile, but sapstartsrv is up, so it is not discovered as an offline instance
+	suite.Equal("W99", system.Instances[1].Name)
+	suite.Equal(Unknown, system.Instances[1].Type)
+}
+
+func (suite *SAPSystemTestSuite) TestNewSAPInstanceStopped() {
+	mockWebService := new(sapControlMocks.WebService)
+	mockWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+		Properties: []*sapcontrol.InstanceProperty{
+			{Property: "SAPSYSTEMNAME", Propertytype: "string", Value: "PRD"},
+			{Property: "INSTANCE_NAME", Propertytype: "string", Value: "HDB00"},
+			{Property: "SAPLOCALHOST", Propertytype: "string", Value: "host1"},
+		},
+	}, nil)
+	mockWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{
+		Processes: []*sapcontrol.OSProcess{
+			{Name: "hdbdaemon", Dispstatus: sapcontrol.STATECOLOR_GRAY, Textstatus: "Stopped"},
+		},
+	}, nil)
+	mockWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{
+		Instances: []*sapcontrol.SAPInstance{{Hostname: "host1", Features: "HDB|HDB_WORKER"}},
+	}, nil)
+
+	// the HANA commands are not run on a stopped database
+	mockCommand := new(utilsMocks.CommandExecutor)
+
+	sapInstance, err := NewSAPInstance(mockWebService, mockCommand, "/usr/sap/PRD")
+
+	suite.NoError(err)
+	suite.Equal(Database, sapInstance.Type)
+	suite.Equal(WebServiceStopped, sapInstance.WebServiceState)
+	suite.NotNil(sapInstance.SAPControl)
+	suite.Nil(sapInstance.SystemReplication)
+	mockCommand.AssertNotCalled(suite.T(), "Exec")
+}
+
+func (suite *SAPSystemTestSuite) TestDetectTypeFromName() {
+	cases := map[string]SystemType{
+		"HDB00":   Database,
+		"ASCS00":  Application,
+		"SCS01":   Application,
+		"ERS10":   Application,
+		"D02":     Application,
+		"DVEBMGS": Unknown,
+		"J03":     Application,
+		"SMDA98":  DiagnosticsAgent,
+		"W01":     Unknown,
+	}
+
+	for name, expectedType := range cases {
+		suite.Equal(expectedType, detectTypeFromName(name), name)
+	}
+}
+
 func mockSystemReplicationStatus() []byte {
 	sFile, err := os.Open(helpers.GetFixturePath("discovery/sap_system/system_replication_status"))
 	if err != nil {
@@ -181,7 +338,7 @@ key2 = value2
 		nameserverContent, 0644)
 	suite.NoError(err)
 
-	id, err := detectSystemID(appFS, mockCommand, Database, "DEV")
+	id, err := detectSystemID(appFS, mockCommand, Database, "DEV", "/usr/sap/DEV/SYS/global")
 
 	suite.NoError(err)
 	suite.Equal("089d1a278481b86e821237f8e98e6de7", id)
@@ -194,7 +351,7 @@ func (suite *SAPSystemTestSuite) TestDetectSystemIdApplication() {
 	cmd := fmt.Sprintf(sappfparCmd, "DEV")
 	mockCommand.On("Exec", "su", "-lc", cmd, "devadm").Return(mockSappfpar(), nil)
 
-	id, err := detectSystemID(appFS, mockCommand, Application, "DEV")
+	id, err := detectSystemID(appFS, mockCommand, Application, "DEV", "/usr/sap/DEV/SYS/global")
 
 	suite.NoError(err)
 	suite.Equal("089d1a278481b86e821237f8e98e6de7", id)
@@ -204,7 +361,7 @@ func (suite *SAPSystemTestSuite) TestSetSystemIdOther() {
 	appFS := afero.NewMemMapFs()
 	mockCommand := new(utilsMocks.CommandExecutor)
 
-	id, err := detectSystemID(appFS, mockCommand, Unknown, "DEV")
+	id, err := detectSystemID(appFS, mockCommand, Unknown, "DEV", "/usr/sap/DEV/SYS/global")
 
 	suite.NoError(err)
 	suite.Equal("-", id)
@@ -223,7 +380,7 @@ func (suite *SAPSystemTestSuite) TestDetectSystemIdDiagnostics() {
 		machineIDContent, 0644)
 	suite.NoError(err)
 
-	id, err := detectSystemID(appFS, mockCommand, DiagnosticsAgent, "DAA")
+	id, err := detectSystemID(appFS, mockCommand, DiagnosticsAgent, "DAA", "/usr/sap/DAA/SYS/global")
 
 	suite.NoError(err)
 	suite.Equal("d3d5dd5ec501127e0011a2531e3b11ff", id)
@@ -247,7 +404,7 @@ ERR:::
 		nameserverContent, 0644)
 	suite.NoError(err)
 
-	dbs, err := getDatabases(appFS, "DEV")
+	dbs, err := getDatabases(appFS, "/usr/sap/DEV/SYS/global")
 
 	expectedDbs := []*DatabaseData{
 		{
@@ -379,7 +536,7 @@ func (suite *SAPSystemTestSuite) TestNewSAPInstanceDatabase() {
 		mockHdbnsutilSrstate(), nil,
 	)
 
-	sapInstance, _ := NewSAPInstance(mockWebService, mockCommand)
+	sapInstance, _ := NewSAPInstance(mockWebService, mockCommand, "/usr/sap/PRD")
 	host, _ := os.Hostname()
 
 	expectedInstance := &SAPInstance{
@@ -450,6 +607,7 @@ func (suite *SAPSystemTestSuite) TestNewSAPInstanceDatabase() {
 				},
 			},
 		},
+		WebServiceState: WebServiceRunning,
 		SystemReplication: SystemReplication{
 			"service/hana01/30001/SHIPPED_LOG_POSITION_TIME":             "2021-06-12 12:43:13.059197",
 			"service/hana01/30001/LAST_LOG_POSITION_TIME":                "2021-06-12 12:43:13.059197",
@@ -615,7 +773,7 @@ func (suite *SAPSystemTestSuite) TestNewSAPInstanceApp() {
 		},
 	}, nil)
 
-	sapInstance, _ := NewSAPInstance(mockWebService, new(utilsMocks.CommandExecutor))
+	sapInstance, _ := NewSAPInstance(mockWebService, new(utilsMocks.CommandExecutor), "/usr/sap/DEV")
 	host, _ := os.Hostname()
 
 	expectedInstance := &SAPInstance{
@@ -686,6 +844,7 @@ func (suite *SAPSystemTestSuite) TestNewSAPInstanceApp() {
 				},
 			},
 		},
+		WebServiceState:   WebServiceRunning,
 		SystemReplication: SystemReplication(nil),
 		HostConfiguration: HostConfiguration(nil),
 		HdbnsutilSRstate:  HdbnsutilSRstate(nil),
@@ -724,9 +883,9 @@ func (suite *SAPSystemTestSuite) TestFindSystemsNotFound() {
 	err = appFS.MkdirAll("/usr/sap/DEV1/", 0755)
 	suite.NoError(err)
 
-	systems, _ := findSystems(appFS)
+	systems, _ := findSystems(appFS, NewDefaultDiscoveryPaths())
 
-	suite.Equal([]string{}, systems)
+	suite.Equal([]SystemPaths{}, systems)
 }
 
 func (suite *SAPSystemTestSuite) TestFindSystems() {
@@ -750,8 +909,213 @@ func (suite *SAPSystemTestSuite) TestFindSystems() {
 	err = appFS.MkdirAll("/usr/sap/DEV/PRD0", 0755)
 	suite.NoError(err)
 
-	systems, _ := findSystems(appFS)
-	suite.ElementsMatch([]string{"/usr/sap/PRD", "/usr/sap/DEV"}, systems)
+	systems, _ := findSystems(appFS, NewDefaultDiscoveryPaths())
+	suite.ElementsMatch([]SystemPaths{
+		{SID: "PRD", System: "/usr/sap/PRD"},
+		{SID: "DEV", System: "/usr/sap/DEV"},
+	}, systems)
+}
+
+func (suite *SAPSystemTestSuite) TestFindSystemsMultipleRoots() {
+	appFS := afero.NewMemMapFs()
+	for _, dir := range []string{
+		"/usr/sap/PRD/HDB00", "/usr/sap/DEV/ASCS02", "/opt/sap/DEV/ASCS02", "/opt/sap/QAS/ASCS01", "/data/sap/HA1/HDB10",
+	} {
+		err := appFS.MkdirAll(dir, 0755)
+		suite.NoError(err)
+	}
+
+	systems, err := findSystems(appFS, DiscoveryPaths{
+		Roots: []string{"/usr/sap", "/opt/sap", "/not/found"},
+		Overrides: map[string]SystemPaths{
+			"QAS": {System: "/data/sap/QAS"},
+			"HA1": {System: "/data/sap/HA1_instance"},
+			"PRD": {Profile: "/sapmnt/PRD/profile"},
+		},
+	})
+
+	suite.NoError(err)
+	suite.ElementsMatch([]SystemPaths{
+		{SID: "PRD", System: "/usr/sap/PRD"},
+		{SID: "DEV", System: "/usr/sap/DEV"},
+		{SID: "QAS", System: "/data/sap/QAS"},
+		{SID: "HA1", System: "/data/sap/HA1_instance"},
+	}, systems)
+}
+
+// linkFs is an in memory filesystem with symbolic links, not supported by afero.MemMapFs
+type linkFs struct {
+	afero.Fs
+	links map[string]string
+}
+
+func (fs linkFs) ReadlinkIfPossible(name string) (string, error) {
+	if target, found := fs.links[name]; found {
+		return target, nil
+	}
+	return "", &os.PathError{Op: "readlink", Path: name, Err: afero.ErrNoReadlink}
+}
+
+func (suite *SAPSystemTestSuite) TestResolveSystemPaths() {
+	appFS := linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/usr/sap/PRD/SYS/profile": "/sapmnt/PRD/profile",
+			"/usr/sap/PRD/SYS/global":  "../../../../hana/shared/PRD/global",
+			"/usr/sap/QAS/SYS/profile": "/sapmnt/broken/profile",
+		},
+	}
+	for _, dir := range []string{
+		"/usr/sap/PRD/SYS", "/sapmnt/PRD/profile", "/hana/shared/PRD/global",
+		"/usr/sap/QAS/SYS", "/sapmnt/QAS/profile", "/usr/sap/DEV/SYS/global",
+	} {
+		err := appFS.MkdirAll(dir, 0755)
+		suite.NoError(err)
+	}
+
+	suite.Equal(SystemPaths{
+		SID:     "PRD",
+		System:  "/usr/sap/PRD",
+		Profile: "/sapmnt/PRD/profile",
+		Global:  "/hana/shared/PRD/global",
+	}, resolveSystemPaths(appFS, SystemPaths{SID: "PRD", System: "/usr/sap/PRD"}, SystemPaths{}))
+
+	suite.Equal(SystemPaths{
+		SID:     "QAS",
+		System:  "/usr/sap/QAS",
+		Profile: "/sapmnt/QAS/profile",
+		Global:  "/usr/sap/QAS/SYS/global",
+	}, resolveSystemPaths(appFS, SystemPaths{SID: "QAS", System: "/usr/sap/QAS"}, SystemPaths{}))
+
+	suite.Equal(SystemPaths{
+		SID:     "DEV",
+		System:  "/usr/sap/DEV",
+		Profile: "/custom/DEV/profile",
+		Global:  "/usr/sap/DEV/SYS/global",
+	}, resolveSystemPaths(
+		appFS, SystemPaths{SID: "DEV", System: "/usr/sap/DEV"}, SystemPaths{Profile: "/custom/DEV/profile"}))
+}
+
+func (suite *SAPSystemTestSuite) TestNewSAPSystemsList() {
+	appFS := linkFs{
+		Fs: afero.NewMemMapFs(),
+		links: map[string]string{
+			"/opt/sap/PRD/SYS/profile": "/hana/shared/PRD/profile",
+			"/opt/sap/PRD/SYS/global":  "/hana/shared/PRD/global",
+		},
+	}
+	err := appFS.MkdirAll("/opt/sap/PRD/HDB00", 0755)
+	suite.NoError(err)
+	err = appFS.MkdirAll("/opt/sap/PRD/SYS", 0755)
+	suite.NoError(err)
+	err = afero.WriteFile(appFS, "/hana/shared/PRD/profile/DEFAULT.PFL", []byte("SAPSYSTEMNAME = PRD\n"), 0644)
+	suite.NoError(err)
+	err = afero.WriteFile(
+		appFS, "/hana/shared/PRD/global/hdb/custom/config/nameserver.ini", []byte("id = systemId\n"), 0644)
+	suite.NoError(err)
+	err = afero.WriteFile(
+		appFS, "/hana/shared/PRD/global/hdb/mdc/databases.lst", []byte("PRD::::::hana01:30015:yes\n"), 0644)
+	suite.NoError(err)
+
+	mockWebService := new(sapControlMocks.WebService)
+	mockWebService.On("GetInstanceProperties").Return(&sapcontrol.GetInstancePropertiesResponse{
+		Properties: []*sapcontrol.InstanceProperty{
+			{Property: "SAPSYSTEMNAME", Propertytype: "string", Value: "PRD"},
+			{Property: "INSTANCE_NAME", Propertytype: "string", Value: "HDB00"},
+			{Property: "SAPLOCALHOST", Propertytype: "string", Value: "host"},
+		},
+	}, nil)
+	mockWebService.On("GetProcessList").Return(&sapcontrol.GetProcessListResponse{
+		Processes: []*sapcontrol.OSProcess{{Name: "hdbdaemon", Dispstatus: sapcontrol.STATECOLOR_GREEN}},
+	}, nil)
+	mockWebService.On("GetSystemInstanceList").Return(&sapcontrol.GetSystemInstanceListResponse{
+		Instances: []*sapcontrol.SAPInstance{{Hostname: "host", InstanceNr: 0, Features: "HDB|HDB_WORKER"}},
+	}, nil)
+
+	mockWebServiceConnector := new(sapControlMocks.WebServiceConnector)
+	mockWebServiceConnector.On("New", "00").Return(mockWebService)
+
+	mockCommand := new(utilsMocks.CommandExecutor)
+	mockCommand.On("Exec", "su", "-lc",
+		"python /opt/sap/PRD/HDB00/exe/python_support/systemReplicationStatus.py --sapcontrol=1", "prdadm").Return(
+		[]byte(""), nil)
+	mockCommand.On("Exec", "su", "-lc",
+		"python /opt/sap/PRD/HDB00/exe/python_support/landscapeHostConfiguration.py --sapcontrol=1", "prdadm").Return(
+		[]byte(""), nil)
+	mockCommand.On("Exec", "su", "-lc", "/opt/sap/PRD/HDB00/exe/hdbnsutil -sr_state -sapcontrol=1", "prdadm").Return(
+		[]byte(""), nil)
+
+	systems, err := NewSAPSystemsList(appFS, mockCommand, mockWebServiceConnector, DiscoveryPaths{
+		Roots:     []string{"/usr/sap", "/opt/sap"},
+		Overrides: map[string]SystemPaths{},
+	})
+
+	suite.NoError(err)
+	suite.Len(systems, 1)
+	suite.Equal("PRD", systems[0].SID)
+	suite.Equal(Database, systems[0].Type)
+	suite.Equal("089d1a278481b86e821237f8e98e6de7", systems[0].ID)
+	suite.Equal(SAPProfile{"SAPSYSTEMNAME": "PRD"}, systems[0].Profile)
+	suite.Len(systems[0].Databases, 1)
+	suite.Equal("HDB00", systems[0].Instances[0].Name)
+	mockCommand.AssertExpectations(suite.T())
+}
+
+func (suite *SAPSystemTestSuite) TestNewSAPSystemsListOverriddenSystemFolder() {
+	appFS := afero.NewMemMapFs()
+	err := appFS.MkdirAll("/data/PRD_inst", 0755)
+	suite.NoError(err)
+	err = afero.WriteFile(appFS, "/custom/PRD/profile/DEFAULT.PFL", []byte("SAPSYSTEMNAME = PRD\n"), 0644)
+	suite.NoError(err)
+
+	systems, err := NewSAPSystemsList(
+		appFS, new(utilsMocks.CommandExecutor), new(sapControlMocks.WebServiceConnector), DiscoveryPaths{
+			Roots: []string{"/usr/sap"},
+			Overrides: map[string]SystemPaths{
+				"PRD": {System: "/data/PRD_inst", Profile: "/custom/PRD/profile"},
+			},
+		})
+
+	suite.NoError(err)
+	suite.Len(systems, 1)
+	suite.Equal("PRD", systems[0].SID)
+	suite.Equal(SAPProfile{"SAPSYSTEMNAME": "PRD"}, systems[0].Profile)
+}
+
+func (suite *SAPSystemTestSuite) TestFindSystemPaths() {
+	appFS := afero.NewMemMapFs()
+	for _, dir := range []string{"/usr/sap/PRD/SYS/profile", "/data/QAS_inst", "/sapmnt/QAS/profile"} {
+		err := appFS.MkdirAll(dir, 0755)
+		suite.NoError(err)
+	}
+
+	discoveryPaths := DiscoveryPaths{
+		Roots: []string{"/usr/sap"},
+		Overrides: map[string]SystemPaths{
+			"QAS": {System: "/data/QAS_inst", Global: "/custom/QAS/global"},
+		},
+	}
+
+	paths, err := FindSystemPaths(appFS, discoveryPaths, "PRD")
+	suite.NoError(err)
+	suite.Equal(SystemPaths{
+		SID:     "PRD",
+		System:  "/usr/sap/PRD",
+		Profile: "/usr/sap/PRD/SYS/profile",
+		Global:  "/usr/sap/PRD/SYS/global",
+	}, paths)
+
+	paths, err = FindSystemPaths(appFS, discoveryPaths, "QAS")
+	suite.NoError(err)
+	suite.Equal(SystemPaths{
+		SID:     "QAS",
+		System:  "/data/QAS_inst",
+		Profile: "/sapmnt/QAS/profile",
+		Global:  "/custom/QAS/global",
+	}, paths)
+
+	_, err = FindSystemPaths(appFS, discoveryPaths, "DEV")
+	suite.EqualError(err, "SAP system DEV not found")
 }
 
 func (suite *SAPSystemTestSuite) TestFindInstancesNotFound() {
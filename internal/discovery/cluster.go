@@ -1,22 +1,30 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/agent/internal/core/cluster"
+	"github.com/trento-project/agent/internal/core/cluster/cib"
 	"github.com/trento-project/agent/internal/discovery/collector"
 )
 
 const ClusterDiscoveryID string = "ha_cluster_discovery"
 const ClusterDiscoveryMinPeriod time.Duration = 1 * time.Second
 
+// ClusterEventsCheckPeriod is the period to compare the CIB version in the event driven mode
+const ClusterEventsCheckPeriod time.Duration = 1 * time.Second
+
 // This Discover handles any Pacemaker Cluster type
 type ClusterDiscovery struct {
 	id              string
 	collectorClient collector.Client
 	interval        time.Duration
+	eventsEnabled   bool
+	eventsPeriod    time.Duration
+	cibVersion      func() (cib.Version, error)
 }
 
 func NewClusterDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
@@ -24,6 +32,9 @@ func NewClusterDiscovery(collectorClient collector.Client, config DiscoveriesCon
 		collectorClient: collectorClient,
 		id:              ClusterDiscoveryID,
 		interval:        config.DiscoveriesPeriodsConfig.Cluster,
+		eventsEnabled:   config.ClusterEventsEnabled,
+		eventsPeriod:    ClusterEventsCheckPeriod,
+		cibVersion:      cluster.CIBVersion,
 	}
 }
 
@@ -51,3 +62,45 @@ func (c ClusterDiscovery) Discover() (string, error) {
 
 	return fmt.Sprintf("Cluster with name: %s successfully discovered", cluster.Name), nil
 }
+
+// Watch notifies a change every time the admin_epoch, epoch or num_updates of the CIB change,
+// so failovers and configuration changes are published right away. The periodic discovery
+// keeps running, so nothing is lost if the CIB is not available or the events mode is disabled
+func (c ClusterDiscovery) Watch(ctx context.Context, changes chan<- struct{}) {
+	if !c.eventsEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(c.eventsPeriod)
+	defer ticker.Stop()
+
+	var lastVersion cib.Version
+	// the first version is not a change, the discovery runs when the loop starts
+	available, first := false, true
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		version, err := c.cibVersion()
+		if err != nil {
+			log.Debugf("Error getting the CIB version: %s", err)
+			available, first = false, false
+			continue
+		}
+
+		if !first && (!available || version != lastVersion) {
+			log.Debugf("CIB changed from version %s to %s", lastVersion, version)
+			// a pending change is not queued twice, the discovery publishes the latest state anyway
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+
+		lastVersion, available, first = version, true, false
+	}
+}
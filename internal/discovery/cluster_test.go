@@ -0,0 +1,113 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/core/cluster/cib"
+)
+
+type ClusterDiscoveryTestSuite struct {
+	suite.Suite
+}
+
+func TestClusterDiscoveryTestSuite(t *testing.T) {
+	suite.Run(t, new(ClusterDiscoveryTestSuite))
+}
+
+// fakeCIBVersions returns the given versions in order, and keeps returning the last one
+type fakeCIBVersions struct {
+	lock     sync.Mutex
+	versions []interface{}
+}
+
+func (f *fakeCIBVersions) next() (cib.Version, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	current := f.versions[0]
+	if len(f.versions) > 1 {
+		f.versions = f.versions[1:]
+	}
+
+	if err, ok := current.(error); ok {
+		return cib.Version{}, err
+	}
+	return current.(cib.Version), nil //nolint:forcetypeassert
+}
+
+func (suite *ClusterDiscoveryTestSuite) watch(versions ...interface{}) int {
+	fake := &fakeCIBVersions{versions: versions}
+	discovery := ClusterDiscovery{
+		eventsEnabled: true,
+		eventsPeriod:  time.Millisecond,
+		cibVersion:    fake.next,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// big enough to not drop any notification, the agent coalesces them using a single slot
+	changes := make(chan struct{}, len(versions))
+	done := make(chan struct{})
+
+	go func() {
+		discovery.Watch(ctx, changes)
+		close(done)
+	}()
+
+	notified := 0
+	timeout := time.After(100 * time.Millisecond)
+	for {
+		select {
+		case <-changes:
+			notified++
+		case <-timeout:
+			cancel()
+			<-done
+			return notified
+		}
+	}
+}
+
+func (suite *ClusterDiscoveryTestSuite) TestWatchNotifiesChanges() {
+	notified := suite.watch(
+		cib.Version{Epoch: 10, NumUpdates: 1},
+		cib.Version{Epoch: 10, NumUpdates: 1},
+		cib.Version{Epoch: 10, NumUpdates: 2},
+		cib.Version{Epoch: 11, NumUpdates: 0},
+		cib.Version{AdminEpoch: 1, Epoch: 11, NumUpdates: 0},
+	)
+
+	suite.Equal(3, notified)
+}
+
+func (suite *ClusterDiscoveryTestSuite) TestWatchNotifiesRecoveredCIB() {
+	notified := suite.watch(
+		errors.New("connection refused"),
+		cib.Version{Epoch: 10, NumUpdates: 1},
+	)
+
+	suite.Equal(1, notified)
+}
+
+func (suite *ClusterDiscoveryTestSuite) TestWatchNoChanges() {
+	suite.Equal(0, suite.watch(cib.Version{Epoch: 10}))
+	suite.Equal(0, suite.watch(errors.New("connection refused")))
+}
+
+func (suite *ClusterDiscoveryTestSuite) TestWatchDisabled() {
+	discovery := ClusterDiscovery{
+		eventsEnabled: false,
+		eventsPeriod:  time.Millisecond,
+		cibVersion: func() (cib.Version, error) {
+			suite.Fail("the CIB version must not be queried")
+			return cib.Version{}, nil
+		},
+	}
+
+	// Watch returns right away, so the discovery is only periodic
+	discovery.Watch(context.Background(), make(chan struct{}))
+}
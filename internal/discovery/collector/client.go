@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/agent/internal/redaction"
 )
 
 type Client interface {
@@ -17,6 +18,7 @@ type Client interface {
 type Collector struct {
 	config     *Config
 	httpClient *http.Client
+	redactor   *redaction.Redactor
 }
 
 type Config struct {
@@ -25,20 +27,26 @@ type Config struct {
 	APIKey    string
 }
 
-func NewCollectorClient(config *Config) *Collector {
+func NewCollectorClient(config *Config, redactor *redaction.Redactor) *Collector {
 	return &Collector{
 		config:     config,
 		httpClient: http.DefaultClient,
+		redactor:   redactor,
 	}
 }
 
 func (c *Collector) Publish(discoveryType string, payload interface{}) error {
 	log.Debugf("Sending %s to data collector", discoveryType)
 
+	redactedPayload, err := c.redactor.Redact(discoveryType, payload)
+	if err != nil {
+		return err
+	}
+
 	requestBody, err := json.Marshal(map[string]interface{}{
 		"agent_id":       c.config.AgentID,
 		"discovery_type": discoveryType,
-		"payload":        payload,
+		"payload":        redactedPayload,
 	})
 	if err != nil {
 		return err
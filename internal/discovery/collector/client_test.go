@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/core/cluster"
+	"github.com/trento-project/agent/internal/redaction"
 	"github.com/trento-project/agent/test/helpers"
 )
 
@@ -29,7 +31,7 @@ func (suite *CollectorClientTestSuite) TestCollectorClientPublishingSuccess() {
 			AgentID:   DummyAgentID,
 			ServerURL: "https://localhost",
 			APIKey:    "some-api-key",
-		})
+		}, redaction.NewDefaultRedactor())
 
 	discoveredDataPayload := struct {
 		FieldA string
@@ -62,13 +64,102 @@ func (suite *CollectorClientTestSuite) TestCollectorClientPublishingSuccess() {
 	suite.NoError(err)
 }
 
+func (suite *CollectorClientTestSuite) TestCollectorClientPublishingRedacted() {
+	collectorClient := NewCollectorClient(
+		&Config{
+			AgentID:   DummyAgentID,
+			ServerURL: "https://localhost",
+			APIKey:    "some-api-key",
+		}, redaction.NewDefaultRedactor())
+
+	discoveredDataPayload := struct {
+		FieldA   string
+		Password string
+	}{
+		FieldA:   "some discovered field",
+		Password: "some password",
+	}
+
+	discoveryType := "the_discovery_type"
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		bodyBytes, _ := io.ReadAll(req.Body)
+
+		suite.JSONEq(`{
+			"agent_id": "779cdd70-e9e2-58ca-b18a-bf3eb3f71244",
+			"discovery_type": "the_discovery_type",
+			"payload": {"FieldA": "some discovered field", "Password": "********"}
+		}`, string(bodyBytes))
+
+		return &http.Response{ //nolint
+			StatusCode: 202,
+		}
+	})
+
+	err := collectorClient.Publish(discoveryType, discoveredDataPayload)
+
+	suite.NoError(err)
+}
+
+func (suite *CollectorClientTestSuite) TestCollectorClientPublishingFencingPasswordHashed() {
+	redactor, err := redaction.NewRedactor(redaction.Config{
+		KeyPatterns:   redaction.DefaultKeyPatterns,
+		ValuePatterns: redaction.DefaultValuePatterns,
+		Mode:          redaction.HashMode,
+		Salt:          "some-salt",
+	})
+	suite.NoError(err)
+
+	collectorClient := NewCollectorClient(
+		&Config{
+			AgentID:   DummyAgentID,
+			ServerURL: "https://localhost",
+			APIKey:    "some-api-key",
+		}, redactor)
+
+	discoveredDataPayload := cluster.Fencing{
+		Resources: []cluster.FencingResource{
+			{
+				ID:    "rsc_st_azure",
+				Agent: "fence_azure_arm",
+				Parameters: map[string]string{
+					"login":  "b5c6d7e8-aaaa-bbbb-cccc-ddddeeeeffff",
+					"passwd": "my-secret",
+				},
+			},
+		},
+	}
+
+	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
+		var body struct {
+			Payload cluster.Fencing `json:"payload"`
+		}
+		bodyBytes, _ := io.ReadAll(req.Body)
+		suite.NoError(json.Unmarshal(bodyBytes, &body))
+
+		// the hash is computed from the password, not from an already redacted value
+		suite.Equal(map[string]string{
+			"login":  "b5c6d7e8-aaaa-bbbb-cccc-ddddeeeeffff",
+			"passwd": "sha256:0ff9740fa11aec6e6234896aab7cbf266a332ffcedb648e6aa2b946e1adf2d81",
+		}, body.Payload.Resources[0].Parameters)
+
+		return &http.Response{ //nolint
+			StatusCode: 202,
+		}
+	})
+
+	err = collectorClient.Publish("ha_cluster_discovery", discoveredDataPayload)
+
+	suite.NoError(err)
+}
+
 func (suite *CollectorClientTestSuite) TestCollectorClientPublishingFailure() {
 	collectorClient := NewCollectorClient(
 		&Config{
 			AgentID:   DummyAgentID,
 			ServerURL: "http://localhost",
 			APIKey:    "some-api-key",
-		})
+		}, redaction.NewDefaultRedactor())
 
 	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
 		suite.Equal(req.URL.String(), "http://localhost/api/collect")
@@ -88,7 +179,7 @@ func (suite *CollectorClientTestSuite) TestCollectorClientHeartbeat() {
 			AgentID:   DummyAgentID,
 			ServerURL: "https://localhost",
 			APIKey:    "some-api-key",
-		})
+		}, redaction.NewDefaultRedactor())
 
 	collectorClient.httpClient.Transport = helpers.RoundTripFunc(func(req *http.Request) *http.Response {
 		suite.Equal(req.URL.String(), fmt.Sprintf("https://localhost/api/hosts/%s/heartbeat", DummyAgentID))
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/agent/internal/discovery/mocks"
+	"github.com/trento-project/agent/internal/redaction"
 	"github.com/trento-project/agent/test/helpers"
 )
 
@@ -32,7 +33,7 @@ func (suite *PublishingTestSuite) SetupSuite() {
 			AgentID:   DummyAgentID,
 			ServerURL: "https://localhost",
 			APIKey:    "some-api-key",
-		})
+		}, redaction.NewDefaultRedactor())
 
 	suite.configuredClient = collectorClient
 }
@@ -1,8 +1,10 @@
 package discovery
 
 import (
+	"context"
 	"time"
 
+	"github.com/trento-project/agent/internal/core/sapsystem"
 	"github.com/trento-project/agent/internal/discovery/collector"
 )
 
@@ -18,6 +20,9 @@ type DiscoveriesConfig struct {
 	SSHAddress               string
 	DiscoveriesPeriodsConfig *DiscoveriesPeriodConfig
 	CollectorConfig          *collector.Config
+	// ClusterEventsEnabled runs the cluster discovery as soon as the CIB changes, on top of the periodic one
+	ClusterEventsEnabled bool
+	SAPSystemPaths       sapsystem.DiscoveryPaths
 }
 
 type Discovery interface {
@@ -28,3 +33,12 @@ type Discovery interface {
 	// Get interval
 	GetInterval() time.Duration
 }
+
+// WatchableDiscovery is a Discovery able to notify the changes of the discovered data,
+// so it is executed as soon as something changes, besides the periodic execution
+type WatchableDiscovery interface {
+	Discovery
+	// Send a notification to the changes channel every time the discovered data changes,
+	// until the context is done
+	Watch(ctx context.Context, changes chan<- struct{})
+}
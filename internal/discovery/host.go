@@ -2,7 +2,6 @@ package discovery
 
 import (
 	"fmt"
-	"net"
 	"strconv"
 	"time"
 
@@ -10,6 +9,7 @@ import (
 	"github.com/shirou/gopsutil/host"
 	"github.com/shirou/gopsutil/mem"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/trento-project/agent/internal/core/hosts"
 	"github.com/trento-project/agent/internal/discovery/collector"
 	"github.com/trento-project/agent/version"
@@ -46,15 +46,20 @@ func (d HostDiscovery) GetInterval() time.Duration {
 
 // Execute one iteration of a discovery and publish to the collector
 func (d HostDiscovery) Discover() (string, error) {
-	ipAddresses, err := getHostIPAddresses()
+	ipAddresses := []string{}
+	network, err := hosts.NewNetwork(afero.NewOsFs(), hosts.NetlinkInterfacesProvider{})
 	if err != nil {
-		return "", err
+		// the rest of the host details are still published
+		log.Errorf("Error discovering the host network: %s", err)
+	} else {
+		ipAddresses = network.IPAddresses()
 	}
 
 	host := hosts.DiscoveredHost{
 		SSHAddress:         d.sshAddress,
 		OSVersion:          getOSVersion(),
 		HostIPAddresses:    ipAddresses,
+		Network:            network,
 		HostName:           d.host,
 		CPUCount:           getLogicalCPUs(),
 		SocketCount:        getCPUSocketCount(),
@@ -72,29 +77,6 @@ func (d HostDiscovery) Discover() (string, error) {
 	return fmt.Sprintf("Host with name: %s successfully discovered", d.host), nil
 }
 
-func getHostIPAddresses() ([]string, error) {
-	interfaces, err := net.Interfaces()
-	if err != nil {
-		return nil, err
-	}
-
-	ipAddrList := make([]string, 0)
-
-	for _, inter := range interfaces {
-		addrs, err := inter.Addrs()
-		if err != nil {
-			continue
-		}
-
-		for _, ipaddr := range addrs {
-			ipv4Addr, _, _ := net.ParseCIDR(ipaddr.String())
-			ipAddrList = append(ipAddrList, ipv4Addr.String())
-		}
-	}
-
-	return ipAddrList, nil
-}
-
 func getOSVersion() string {
 	infoStat, err := host.Info()
 	if err != nil {
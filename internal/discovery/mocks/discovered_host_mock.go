@@ -4,9 +4,33 @@ import "github.com/trento-project/agent/internal/core/hosts"
 
 func NewDiscoveredHostMock() hosts.DiscoveredHost {
 	return hosts.DiscoveredHost{
-		SSHAddress:         "10.2.2.22",
-		OSVersion:          "15-SP2",
-		HostIPAddresses:    []string{"10.1.1.4", "10.1.1.5", "10.1.1.6"},
+		SSHAddress:      "10.2.2.22",
+		OSVersion:       "15-SP2",
+		HostIPAddresses: []string{"10.1.1.4", "10.1.1.5", "10.1.1.6"},
+		Network: &hosts.Network{
+			Interfaces: []*hosts.NetworkInterface{
+				{
+					Name:       "eth0",
+					Index:      2,
+					MacAddress: "52:54:00:a1:b2:01",
+					MTU:        1500,
+					State:      "up",
+					Addresses: []*hosts.NetworkAddress{
+						{Address: "10.1.1.4", PrefixLength: 24, Family: "ipv4", Scope: "global"},
+						{Address: "10.1.1.5", PrefixLength: 24, Family: "ipv4", Scope: "global"},
+						{Address: "10.1.1.6", PrefixLength: 24, Family: "ipv4", Scope: "global"},
+					},
+				},
+			},
+			Routes: []*hosts.NetworkRoute{
+				{Family: "ipv4", Destination: "0.0.0.0/0", Gateway: "10.1.1.1", Interface: "eth0", Metric: 100, Default: true},
+			},
+			DNS: hosts.DNSConfig{
+				Nameservers: []string{"10.1.1.2"},
+				Search:      []string{"example.com"},
+				Options:     []string{},
+			},
+		},
 		HostName:           "thehostnamewherethediscoveryhappened",
 		CPUCount:           2,
 		SocketCount:        1,
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/trento-project/agent/internal/core/sapsystem"
 	"github.com/trento-project/agent/internal/core/sapsystem/sapcontrolapi"
 	"github.com/trento-project/agent/internal/discovery/collector"
@@ -18,6 +19,8 @@ type SAPSystemsDiscovery struct {
 	id              string
 	collectorClient collector.Client
 	interval        time.Duration
+	fs              afero.Fs
+	paths           sapsystem.DiscoveryPaths
 }
 
 func NewSAPSystemsDiscovery(collectorClient collector.Client, config DiscoveriesConfig) Discovery {
@@ -25,6 +28,8 @@ func NewSAPSystemsDiscovery(collectorClient collector.Client, config Discoveries
 		id:              SAPDiscoveryID,
 		collectorClient: collectorClient,
 		interval:        config.DiscoveriesPeriodsConfig.SAPSystem,
+		fs:              afero.NewOsFs(),
+		paths:           config.SAPSystemPaths,
 	}
 }
 
@@ -37,7 +42,7 @@ func (d SAPSystemsDiscovery) GetInterval() time.Duration {
 }
 
 func (d SAPSystemsDiscovery) Discover() (string, error) {
-	systems, err := sapsystem.NewSAPSystemsList(utils.Executor{}, sapcontrolapi.WebServiceUnix{})
+	systems, err := sapsystem.NewSAPSystemsList(d.fs, utils.Executor{}, sapcontrolapi.WebServiceUnix{}, d.paths)
 
 	if err != nil {
 		return "", err
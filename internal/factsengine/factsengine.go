@@ -7,6 +7,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/agent/internal/factsengine/adapters"
 	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/internal/redaction"
 )
 
 const (
@@ -21,14 +22,20 @@ type FactsEngine struct {
 	factsEngineService  string
 	gathererRegistry    gatherers.Registry
 	factsServiceAdapter adapters.Adapter
+	redactor            *redaction.Redactor
 }
 
-func NewFactsEngine(agentID, factsEngineService string, registry gatherers.Registry) *FactsEngine {
+func NewFactsEngine(
+	agentID, factsEngineService string,
+	registry gatherers.Registry,
+	redactor *redaction.Redactor,
+) *FactsEngine {
 	return &FactsEngine{
 		agentID:             agentID,
 		factsEngineService:  factsEngineService,
 		factsServiceAdapter: nil,
 		gathererRegistry:    registry,
+		redactor:            redactor,
 	}
 }
 
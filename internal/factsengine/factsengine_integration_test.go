@@ -11,6 +11,7 @@ import (
 
 	"github.com/trento-project/agent/internal/factsengine/adapters"
 	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/internal/redaction"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
 	"github.com/trento-project/contracts/go/pkg/events"
 )
@@ -86,7 +87,7 @@ func (suite *FactsEngineIntegrationTestSuite) TestFactsEngineIntegration() {
 		"integration": NewFactsEngineIntegrationTestGatherer(),
 	})
 
-	engine := NewFactsEngine(agentID, suite.factsEngineService, *gathererRegistry)
+	engine := NewFactsEngine(agentID, suite.factsEngineService, *gathererRegistry, redaction.NewDefaultRedactor())
 
 	err := engine.Subscribe()
 	if err != nil {
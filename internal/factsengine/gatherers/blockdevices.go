@@ -0,0 +1,553 @@
+package gatherers
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	BlockDevicesGathererName = "block_devices"
+	sysBlockPath             = "/sys/block"
+	lsblkColumns             = "NAME,KNAME,PATH,MAJ:MIN,TYPE,SIZE,MODEL,SERIAL,WWN,FSTYPE,PKNAME,ROTA"
+	multipathDeviceType      = "mpath"
+)
+
+var (
+	multipathMapCompiled       = regexp.MustCompile(`^(\S+)\s+(?:\((\S+)\)\s+)?(dm-\d+)\s*(.*)$`)
+	multipathAttributeCompiled = regexp.MustCompile(`^size=(\S+)\s+features='([^']*)'\s+hwhandler='([^']*)'\s+wp=(\S+)`)
+	multipathGroupCompiled     = regexp.MustCompile(`policy='([^']*)'\s+prio=(-?\d+)\s+status=(\S+)`)
+	multipathPathCompiled      = regexp.MustCompile(
+		`(\d+:\d+:\d+:\d+|#:#:#:#)\s+(\S+)\s+(\d+:\d+)\s+(\S+)\s+(\S+)\s+(\S+)`)
+	persistentDevicePrefixes = []string{ //nolint:gochecknoglobals
+		"/dev/disk/by-id/", "/dev/disk/by-uuid/", "/dev/disk/by-path/",
+		"/dev/disk/by-label/", "/dev/disk/by-partuuid/", "/dev/mapper/",
+	}
+	queueSettings = []string{ //nolint:gochecknoglobals
+		"nr_requests", "read_ahead_kb", "max_sectors_kb", "rotational",
+		"logical_block_size", "physical_block_size",
+	}
+)
+
+// nolint:gochecknoglobals
+var (
+	BlockDevicesCommandError = entities.FactGatheringError{
+		Type:    "block-devices-command-error",
+		Message: "error running block devices command",
+	}
+
+	BlockDevicesDecodingError = entities.FactGatheringError{
+		Type:    "block-devices-decoding-error",
+		Message: "error decoding block devices data",
+	}
+
+	BlockDevicesMountInfoError = entities.FactGatheringError{
+		Type:    "block-devices-mountinfo-error",
+		Message: "error reading the mountinfo file",
+	}
+
+	BlockDevicesNotFoundError = entities.FactGatheringError{
+		Type:    "block-devices-not-found",
+		Message: "requested block device or mount point not found",
+	}
+)
+
+type BlockDevicesGatherer struct {
+	executor utils.CommandExecutor
+	fs       afero.Fs
+}
+
+type blockDevice struct {
+	Name       string
+	KName      string
+	Path       string
+	MajorMinor string
+	Type       string
+	Size       string
+	Model      string
+	Serial     string
+	WWN        string
+	FSType     string
+	Rotational bool
+	Parents    []string
+}
+
+type multipathMap struct {
+	Name       string
+	WWID       string
+	DMDevice   string
+	Vendor     string
+	Size       string
+	Features   string
+	HWHandler  string
+	WP         string
+	PathGroups []multipathPathGroup
+}
+
+type multipathPathGroup struct {
+	Policy   string
+	Priority int
+	Status   string
+	Paths    []multipathPath
+}
+
+type multipathPath struct {
+	HCTL        string
+	Device      string
+	MajorMinor  string
+	DMState     string
+	PathState   string
+	OnlineState string
+}
+
+func NewDefaultBlockDevicesGatherer() *BlockDevicesGatherer {
+	return NewBlockDevicesGatherer(utils.Executor{}, afero.NewOsFs())
+}
+
+func NewBlockDevicesGatherer(executor utils.CommandExecutor, fs afero.Fs) *BlockDevicesGatherer {
+	return &BlockDevicesGatherer{
+		executor: executor,
+		fs:       fs,
+	}
+}
+
+// Gather returns the block device details for every requested device path or mount point.
+// The device path can be any path resolved by udev, like /dev/sda, /dev/mapper/mpatha
+// or /dev/disk/by-id/scsi-36001405...
+func (g *BlockDevicesGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", BlockDevicesGathererName)
+
+	lsblkOutput, err := g.executor.Exec(
+		"lsblk", "--json", "--bytes", "--list", "--output", lsblkColumns)
+	if err != nil {
+		return nil, BlockDevicesCommandError.Wrap(err.Error())
+	}
+
+	devices, err := parseLsblk(lsblkOutput)
+	if err != nil {
+		return nil, BlockDevicesDecodingError.Wrap(err.Error())
+	}
+
+	mounts, err := readMountInfo(g.fs, MountInfoPath)
+	if err != nil {
+		return nil, BlockDevicesMountInfoError.Wrap(err.Error())
+	}
+
+	// multipath is not installed in every system, so the maps list is just empty in that case
+	multipathOutput, err := g.executor.Exec("multipath", "-ll")
+	if err != nil {
+		log.Debugf("Error getting the multipath maps: %s", err)
+	}
+	multipathMaps := parseMultipath(multipathOutput)
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		device, gatheringError := g.findDevice(factReq.Argument, devices, mounts)
+		if gatheringError != nil {
+			log.Error(gatheringError)
+			fact = entities.NewFactGatheredWithError(factReq, gatheringError)
+		} else {
+			value := g.blockDeviceToFactValue(factReq.Argument, device, devices, mounts, multipathMaps)
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		}
+
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", BlockDevicesGathererName)
+	return facts, nil
+}
+
+func (g *BlockDevicesGatherer) findDevice(
+	argument string,
+	devices map[string]*blockDevice,
+	mounts []mountInfoEntry,
+) (*blockDevice, *entities.FactGatheringError) {
+	if argument == "" {
+		return nil, BlockDevicesNotFoundError.Wrap("empty argument")
+	}
+
+	// mount points are resolved using the device major and minor numbers
+	if !strings.HasPrefix(argument, "/dev/") {
+		mountPoint := path.Clean(argument)
+		for _, mount := range mounts {
+			if mount.MountPoint != mountPoint {
+				continue
+			}
+			for _, device := range devices {
+				if device.MajorMinor == mount.MajorMinor {
+					return device, nil
+				}
+			}
+			return nil, BlockDevicesNotFoundError.Wrap(
+				fmt.Sprintf("%s is not mounted from a block device", argument))
+		}
+		return nil, BlockDevicesNotFoundError.Wrap(argument)
+	}
+
+	kname, err := g.executor.Exec("udevadm", "info", "--query=name", "--name="+argument)
+	if err != nil {
+		return nil, BlockDevicesNotFoundError.Wrap(argument)
+	}
+
+	device, found := devices[strings.TrimSpace(string(kname))]
+	if !found {
+		return nil, BlockDevicesNotFoundError.Wrap(argument)
+	}
+
+	return device, nil
+}
+
+func (g *BlockDevicesGatherer) blockDeviceToFactValue(
+	argument string,
+	device *blockDevice,
+	devices map[string]*blockDevice,
+	mounts []mountInfoEntry,
+	multipathMaps []multipathMap,
+) entities.FactValue {
+	links := g.udevLinks(device.KName)
+	byID := []string{}
+	for _, link := range links {
+		if strings.HasPrefix(link, "/dev/disk/by-id/") {
+			byID = append(byID, link)
+		}
+	}
+
+	persistentName := false
+	for _, prefix := range persistentDevicePrefixes {
+		if strings.HasPrefix(argument, prefix) {
+			persistentName = true
+			break
+		}
+	}
+
+	value := map[string]entities.FactValue{
+		"name":            &entities.FactValueString{Value: device.Name},
+		"kname":           &entities.FactValueString{Value: device.KName},
+		"path":            &entities.FactValueString{Value: device.Path},
+		"maj_min":         &entities.FactValueString{Value: device.MajorMinor},
+		"type":            &entities.FactValueString{Value: device.Type},
+		"size":            entities.ParseStringToFactValue(device.Size),
+		"model":           &entities.FactValueString{Value: device.Model},
+		"serial":          &entities.FactValueString{Value: device.Serial},
+		"wwn":             &entities.FactValueString{Value: device.WWN},
+		"fstype":          &entities.FactValueString{Value: device.FSType},
+		"rotational":      &entities.FactValueBool{Value: device.Rotational},
+		"parents":         stringsToFactValueList(device.Parents),
+		"links":           stringsToFactValueList(links),
+		"by_id":           stringsToFactValueList(byID),
+		"persistent_name": &entities.FactValueBool{Value: persistentName},
+		"queue":           g.queueSettings(device, devices),
+	}
+
+	mountsList := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, mount := range mounts {
+		if mount.MajorMinor != device.MajorMinor {
+			continue
+		}
+		mountsList.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"mount_point":   &entities.FactValueString{Value: mount.MountPoint},
+			"root":          &entities.FactValueString{Value: mount.Root},
+			"source":        &entities.FactValueString{Value: mount.Source},
+			"fstype":        &entities.FactValueString{Value: mount.FSType},
+			"options":       stringsToFactValueList(mount.Options),
+			"super_options": stringsToFactValueList(mount.SuperOptions),
+		}})
+	}
+	value["mounts"] = mountsList
+
+	multipathName := findMultipathAncestor(device, devices)
+	value["is_multipath"] = &entities.FactValueBool{Value: multipathName != ""}
+	value["multipath_member"] = &entities.FactValueBool{Value: false}
+
+	for _, multipath := range multipathMaps {
+		if multipath.Name == multipathName {
+			value["multipath"] = multipath.toFactValue()
+			break
+		}
+		if multipathName == "" && multipath.hasPath(device.KName) {
+			value["multipath"] = multipath.toFactValue()
+			value["multipath_member"] = &entities.FactValueBool{Value: true}
+			break
+		}
+	}
+
+	return &entities.FactValueMap{Value: value}
+}
+
+// udevLinks returns the udev symlinks of a device, which include the persistent names
+func (g *BlockDevicesGatherer) udevLinks(kname string) []string {
+	links := []string{}
+	output, err := g.executor.Exec("udevadm", "info", "--query=symlink", "--name=/dev/"+kname)
+	if err != nil {
+		log.Debugf("Error getting the udev links of %s: %s", kname, err)
+		return links
+	}
+
+	for _, link := range strings.Fields(string(output)) {
+		links = append(links, path.Join("/dev", link))
+	}
+
+	return links
+}
+
+// queueSettings reads the device queue settings from sysfs. Partitions don't have
+// a queue, so the settings of the parent device are used
+func (g *BlockDevicesGatherer) queueSettings(device *blockDevice, devices map[string]*blockDevice) entities.FactValue {
+	queue := make(map[string]entities.FactValue)
+
+	kname := device.KName
+	if exists, _ := afero.DirExists(g.fs, path.Join(sysBlockPath, kname)); !exists && len(device.Parents) > 0 {
+		if parent, found := devices[device.Parents[0]]; found {
+			kname = parent.KName
+		}
+	}
+
+	queuePath := path.Join(sysBlockPath, kname, "queue")
+
+	if scheduler, err := afero.ReadFile(g.fs, path.Join(queuePath, "scheduler")); err == nil {
+		available := []string{}
+		for _, item := range strings.Fields(string(scheduler)) {
+			if strings.HasPrefix(item, "[") && strings.HasSuffix(item, "]") {
+				item = strings.Trim(item, "[]")
+				queue["scheduler"] = &entities.FactValueString{Value: item}
+			}
+			available = append(available, item)
+		}
+		queue["available_schedulers"] = stringsToFactValueList(available)
+	}
+
+	for _, setting := range queueSettings {
+		content, err := afero.ReadFile(g.fs, path.Join(queuePath, setting))
+		if err != nil {
+			continue
+		}
+		queue[setting] = entities.ParseStringToFactValue(strings.TrimSpace(string(content)))
+	}
+
+	return &entities.FactValueMap{Value: queue}
+}
+
+// findMultipathAncestor returns the name of the multipath map containing the device.
+// The device itself or any of its parents, like for partitions or LVM volumes, can be the map
+func findMultipathAncestor(device *blockDevice, devices map[string]*blockDevice) string {
+	visited := make(map[string]bool)
+	pending := []*blockDevice{device}
+
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		if visited[current.KName] {
+			continue
+		}
+		visited[current.KName] = true
+
+		if current.Type == multipathDeviceType {
+			return current.Name
+		}
+
+		for _, parent := range current.Parents {
+			if parentDevice, found := devices[parent]; found {
+				pending = append(pending, parentDevice)
+			}
+		}
+	}
+
+	return ""
+}
+
+// parseLsblk decodes the lsblk json output in list mode. Devices with more than one parent,
+// like multipath maps, are listed once per parent, so they are merged by kernel name
+func parseLsblk(output []byte) (map[string]*blockDevice, error) {
+	var lsblk struct {
+		BlockDevices []map[string]interface{} `json:"blockdevices"`
+	}
+
+	if err := json.Unmarshal(output, &lsblk); err != nil {
+		return nil, err
+	}
+
+	// lsblk versions differ in the json types of some fields, so all of them are handled as strings
+	asString := func(entry map[string]interface{}, key string) string {
+		value, found := entry[key]
+		if !found || value == nil {
+			return ""
+		}
+		if number, ok := value.(float64); ok {
+			return strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		return strings.TrimSpace(fmt.Sprint(value))
+	}
+
+	devices := make(map[string]*blockDevice)
+	for _, entry := range lsblk.BlockDevices {
+		kname := asString(entry, "kname")
+		if kname == "" {
+			return nil, fmt.Errorf("block device without kernel name found")
+		}
+
+		device, found := devices[kname]
+		if !found {
+			rotational := asString(entry, "rota")
+			device = &blockDevice{
+				Name:       asString(entry, "name"),
+				KName:      kname,
+				Path:       asString(entry, "path"),
+				MajorMinor: asString(entry, "maj:min"),
+				Type:       asString(entry, "type"),
+				Size:       asString(entry, "size"),
+				Model:      asString(entry, "model"),
+				Serial:     asString(entry, "serial"),
+				WWN:        asString(entry, "wwn"),
+				FSType:     asString(entry, "fstype"),
+				Rotational: rotational == "true" || rotational == "1",
+				Parents:    []string{},
+			}
+			devices[kname] = device
+		}
+
+		if parent := asString(entry, "pkname"); parent != "" {
+			device.Parents = append(device.Parents, parent)
+		}
+	}
+
+	return devices, nil
+}
+
+// parseMultipath parses the multipath -ll output. Example:
+// mpatha (3600140584b7a7b6e0a44ab5a6c4ea8b3) dm-0 LIO-ORG,sbd
+// size=10M features='0' hwhandler='1 alua' wp=rw
+// `-+- policy='service-time 0' prio=50 status=active
+//
+//	|- 2:0:0:0 sda 8:0  active ready running
+//	`- 3:0:0:0 sdb 8:16 active ready running
+func parseMultipath(output []byte) []multipathMap {
+	maps := []multipathMap{}
+	var current *multipathMap
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// map header lines are the only ones without indentation nor tree characters
+		if !strings.ContainsAny(line[:1], " |`") && !strings.HasPrefix(line, "size=") {
+			match := multipathMapCompiled.FindStringSubmatch(line)
+			if match == nil {
+				current = nil
+				continue
+			}
+			wwid := match[2]
+			if wwid == "" {
+				wwid = match[1]
+			}
+			maps = append(maps, multipathMap{
+				Name:       match[1],
+				WWID:       wwid,
+				DMDevice:   match[3],
+				Vendor:     match[4],
+				PathGroups: []multipathPathGroup{},
+			})
+			current = &maps[len(maps)-1]
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if match := multipathAttributeCompiled.FindStringSubmatch(line); match != nil {
+			current.Size, current.Features, current.HWHandler, current.WP = match[1], match[2], match[3], match[4]
+			continue
+		}
+
+		if match := multipathGroupCompiled.FindStringSubmatch(line); match != nil {
+			priority, _ := strconv.Atoi(match[2])
+			current.PathGroups = append(current.PathGroups, multipathPathGroup{
+				Policy:   match[1],
+				Priority: priority,
+				Status:   match[3],
+				Paths:    []multipathPath{},
+			})
+			continue
+		}
+
+		if match := multipathPathCompiled.FindStringSubmatch(line); match != nil && len(current.PathGroups) > 0 {
+			group := &current.PathGroups[len(current.PathGroups)-1]
+			group.Paths = append(group.Paths, multipathPath{
+				HCTL:        match[1],
+				Device:      match[2],
+				MajorMinor:  match[3],
+				DMState:     match[4],
+				PathState:   match[5],
+				OnlineState: match[6],
+			})
+		}
+	}
+
+	return maps
+}
+
+func (m multipathMap) hasPath(kname string) bool {
+	for _, group := range m.PathGroups {
+		for _, path := range group.Paths {
+			if path.Device == kname {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m multipathMap) toFactValue() entities.FactValue {
+	groups := &entities.FactValueList{Value: []entities.FactValue{}}
+	activePaths := 0
+
+	for _, group := range m.PathGroups {
+		paths := &entities.FactValueList{Value: []entities.FactValue{}}
+		for _, path := range group.Paths {
+			if path.DMState == "active" && path.PathState == "ready" {
+				activePaths++
+			}
+			paths.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+				"hctl":         &entities.FactValueString{Value: path.HCTL},
+				"device":       &entities.FactValueString{Value: path.Device},
+				"maj_min":      &entities.FactValueString{Value: path.MajorMinor},
+				"dm_state":     &entities.FactValueString{Value: path.DMState},
+				"path_state":   &entities.FactValueString{Value: path.PathState},
+				"online_state": &entities.FactValueString{Value: path.OnlineState},
+			}})
+		}
+
+		groups.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"policy":   &entities.FactValueString{Value: group.Policy},
+			"priority": &entities.FactValueInt{Value: group.Priority},
+			"status":   &entities.FactValueString{Value: group.Status},
+			"paths":    paths,
+		}})
+	}
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"name":         &entities.FactValueString{Value: m.Name},
+		"wwid":         &entities.FactValueString{Value: m.WWID},
+		"dm_device":    &entities.FactValueString{Value: m.DMDevice},
+		"vendor":       &entities.FactValueString{Value: m.Vendor},
+		"size":         &entities.FactValueString{Value: m.Size},
+		"features":     &entities.FactValueString{Value: m.Features},
+		"hwhandler":    &entities.FactValueString{Value: m.HWHandler},
+		"wp":           &entities.FactValueString{Value: m.WP},
+		"path_groups":  groups,
+		"active_paths": &entities.FactValueInt{Value: activePaths},
+	}}
+}
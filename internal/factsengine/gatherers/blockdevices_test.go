@@ -0,0 +1,320 @@
+package gatherers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+)
+
+type BlockDevicesTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+	fs           afero.Fs
+}
+
+func TestBlockDevicesTestSuite(t *testing.T) {
+	suite.Run(t, new(BlockDevicesTestSuite))
+}
+
+func (suite *BlockDevicesTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+	suite.fs = afero.NewMemMapFs()
+
+	_ = afero.WriteFile(suite.fs, "/proc/self/mountinfo", readFixture("gatherers/mountinfo"), 0644)
+	_ = afero.WriteFile(suite.fs, "/sys/block/dm-0/queue/scheduler", []byte("[none] mq-deadline\n"), 0644)
+	_ = afero.WriteFile(suite.fs, "/sys/block/dm-0/queue/nr_requests", []byte("256\n"), 0644)
+	_ = afero.WriteFile(suite.fs, "/sys/block/dm-0/queue/rotational", []byte("1\n"), 0644)
+	_ = afero.WriteFile(suite.fs, "/sys/block/vda/queue/scheduler", []byte("none [mq-deadline] kyber bfq\n"), 0644)
+	_ = afero.WriteFile(suite.fs, "/sys/block/vda/queue/read_ahead_kb", []byte("4096\n"), 0644)
+	_ = afero.WriteFile(suite.fs, "/sys/block/dm-1/queue/scheduler", []byte("none\n"), 0644)
+
+	suite.mockExecutor.On("Exec", "lsblk", "--json", "--bytes", "--list", "--output",
+		"NAME,KNAME,PATH,MAJ:MIN,TYPE,SIZE,MODEL,SERIAL,WWN,FSTYPE,PKNAME,ROTA").Return(
+		readFixture("gatherers/lsblk.output"), nil)
+	suite.mockExecutor.On("Exec", "multipath", "-ll").Return(
+		readFixture("gatherers/multipath-ll.output"), nil)
+}
+
+func (suite *BlockDevicesTestSuite) TestBlockDevicesGatherMultipath() {
+	byIDPath := "/dev/disk/by-id/dm-uuid-mpath-3600140584b7a7b6e0a44ab5a6c4ea8b3"
+
+	suite.mockExecutor.On("Exec", "udevadm", "info", "--query=name", "--name="+byIDPath).Return(
+		[]byte("dm-0\n"), nil)
+	suite.mockExecutor.On("Exec", "udevadm", "info", "--query=symlink", "--name=/dev/dm-0").Return(
+		[]byte("disk/by-id/dm-name-mpatha disk/by-id/dm-uuid-mpath-3600140584b7a7b6e0a44ab5a6c4ea8b3 mapper/mpatha\n"), nil)
+
+	g := gatherers.NewBlockDevicesGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "sbd_device",
+			Gatherer: "block_devices",
+			Argument: byIDPath,
+			CheckID:  "check1",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "sbd_device",
+			CheckID: "check1",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"name":       &entities.FactValueString{Value: "mpatha"},
+				"kname":      &entities.FactValueString{Value: "dm-0"},
+				"path":       &entities.FactValueString{Value: "/dev/mapper/mpatha"},
+				"maj_min":    &entities.FactValueString{Value: "253:0"},
+				"type":       &entities.FactValueString{Value: "mpath"},
+				"size":       &entities.FactValueInt{Value: 10485760},
+				"model":      &entities.FactValueString{Value: ""},
+				"serial":     &entities.FactValueString{Value: ""},
+				"wwn":        &entities.FactValueString{Value: ""},
+				"fstype":     &entities.FactValueString{Value: ""},
+				"rotational": &entities.FactValueBool{Value: true},
+				"parents": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "sda"},
+					&entities.FactValueString{Value: "sdb"},
+				}},
+				"links": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "/dev/disk/by-id/dm-name-mpatha"},
+					&entities.FactValueString{Value: byIDPath},
+					&entities.FactValueString{Value: "/dev/mapper/mpatha"},
+				}},
+				"by_id": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "/dev/disk/by-id/dm-name-mpatha"},
+					&entities.FactValueString{Value: byIDPath},
+				}},
+				"persistent_name": &entities.FactValueBool{Value: true},
+				"queue": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"scheduler": &entities.FactValueString{Value: "none"},
+					"available_schedulers": &entities.FactValueList{Value: []entities.FactValue{
+						&entities.FactValueString{Value: "none"},
+						&entities.FactValueString{Value: "mq-deadline"},
+					}},
+					"nr_requests": &entities.FactValueInt{Value: 256},
+					"rotational":  &entities.FactValueInt{Value: 1},
+				}},
+				"mounts":           &entities.FactValueList{Value: []entities.FactValue{}},
+				"is_multipath":     &entities.FactValueBool{Value: true},
+				"multipath_member": &entities.FactValueBool{Value: false},
+				"multipath": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"name":      &entities.FactValueString{Value: "mpatha"},
+					"wwid":      &entities.FactValueString{Value: "3600140584b7a7b6e0a44ab5a6c4ea8b3"},
+					"dm_device": &entities.FactValueString{Value: "dm-0"},
+					"vendor":    &entities.FactValueString{Value: "LIO-ORG,sbd"},
+					"size":      &entities.FactValueString{Value: "10M"},
+					"features":  &entities.FactValueString{Value: "0"},
+					"hwhandler": &entities.FactValueString{Value: "1 alua"},
+					"wp":        &entities.FactValueString{Value: "rw"},
+					"path_groups": &entities.FactValueList{Value: []entities.FactValue{
+						&entities.FactValueMap{Value: map[string]entities.FactValue{
+							"policy":   &entities.FactValueString{Value: "service-time 0"},
+							"priority": &entities.FactValueInt{Value: 50},
+							"status":   &entities.FactValueString{Value: "active"},
+							"paths": &entities.FactValueList{Value: []entities.FactValue{
+								&entities.FactValueMap{Value: map[string]entities.FactValue{
+									"hctl":         &entities.FactValueString{Value: "2:0:0:0"},
+									"device":       &entities.FactValueString{Value: "sda"},
+									"maj_min":      &entities.FactValueString{Value: "8:0"},
+									"dm_state":     &entities.FactValueString{Value: "active"},
+									"path_state":   &entities.FactValueString{Value: "ready"},
+									"online_state": &entities.FactValueString{Value: "running"},
+								}},
+								&entities.FactValueMap{Value: map[string]entities.FactValue{
+									"hctl":         &entities.FactValueString{Value: "3:0:0:0"},
+									"device":       &entities.FactValueString{Value: "sdb"},
+									"maj_min":      &entities.FactValueString{Value: "8:16"},
+									"dm_state":     &entities.FactValueString{Value: "failed"},
+									"path_state":   &entities.FactValueString{Value: "faulty"},
+									"online_state": &entities.FactValueString{Value: "running"},
+								}},
+							}},
+						}},
+					}},
+					"active_paths": &entities.FactValueInt{Value: 1},
+				}},
+			}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *BlockDevicesTestSuite) TestBlockDevicesGatherMountPointAndPartition() {
+	suite.mockExecutor.On("Exec", "udevadm", "info", "--query=symlink", "--name=/dev/dm-1").Return(
+		[]byte("disk/by-id/dm-name-vg_hana-lv_data mapper/vg_hana-lv_data vg_hana/lv_data\n"), nil)
+	suite.mockExecutor.On("Exec", "udevadm", "info", "--query=name", "--name=/dev/vda1").Return(
+		[]byte("vda1\n"), nil)
+	suite.mockExecutor.On("Exec", "udevadm", "info", "--query=symlink", "--name=/dev/vda1").Return(
+		nil, errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "udevadm", "info", "--query=name", "--name=/dev/sda").Return(
+		[]byte("sda\n"), nil)
+	suite.mockExecutor.On("Exec", "udevadm", "info", "--query=symlink", "--name=/dev/sda").Return(
+		[]byte("disk/by-path/ip-10.0.0.1:3260-iscsi-iqn.2003-01.org.linux-iscsi-lun-0\n"), nil)
+
+	g := gatherers.NewBlockDevicesGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "hana_data",
+			Gatherer: "block_devices",
+			Argument: "/hana/data/",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "root_partition",
+			Gatherer: "block_devices",
+			Argument: "/dev/vda1",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "multipath_path",
+			Gatherer: "block_devices",
+			Argument: "/dev/sda",
+			CheckID:  "check3",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+	suite.NoError(err)
+	suite.Len(factResults, 3)
+
+	hanaData, ok := factResults[0].Value.(*entities.FactValueMap)
+	suite.True(ok)
+	suite.Equal(&entities.FactValueString{Value: "dm-1"}, hanaData.Value["kname"])
+	suite.Equal(&entities.FactValueString{Value: "lvm"}, hanaData.Value["type"])
+	suite.Equal(&entities.FactValueBool{Value: false}, hanaData.Value["is_multipath"])
+	suite.Equal(&entities.FactValueBool{Value: false}, hanaData.Value["persistent_name"])
+	suite.NotContains(hanaData.Value, "multipath")
+	suite.Equal(&entities.FactValueList{Value: []entities.FactValue{
+		&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"mount_point": &entities.FactValueString{Value: "/hana/data"},
+			"root":        &entities.FactValueString{Value: "/"},
+			"source":      &entities.FactValueString{Value: "/dev/mapper/vg_hana-lv_data"},
+			"fstype":      &entities.FactValueString{Value: "xfs"},
+			"options": &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "rw"},
+				&entities.FactValueString{Value: "noatime"},
+			}},
+			"super_options": &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "rw"},
+				&entities.FactValueString{Value: "attr2"},
+				&entities.FactValueString{Value: "inode64"},
+				&entities.FactValueString{Value: "logbufs=8"},
+				&entities.FactValueString{Value: "logbsize=32k"},
+				&entities.FactValueString{Value: "noquota"},
+			}},
+		}},
+	}}, hanaData.Value["mounts"])
+
+	rootPartition, ok := factResults[1].Value.(*entities.FactValueMap)
+	suite.True(ok)
+	suite.Equal(&entities.FactValueMap{Value: map[string]entities.FactValue{
+		"scheduler": &entities.FactValueString{Value: "mq-deadline"},
+		"available_schedulers": &entities.FactValueList{Value: []entities.FactValue{
+			&entities.FactValueString{Value: "none"},
+			&entities.FactValueString{Value: "mq-deadline"},
+			&entities.FactValueString{Value: "kyber"},
+			&entities.FactValueString{Value: "bfq"},
+		}},
+		"read_ahead_kb": &entities.FactValueInt{Value: 4096},
+	}}, rootPartition.Value["queue"])
+	suite.Equal(&entities.FactValueList{Value: []entities.FactValue{}}, rootPartition.Value["links"])
+
+	multipathPath, ok := factResults[2].Value.(*entities.FactValueMap)
+	suite.True(ok)
+	suite.Equal(&entities.FactValueBool{Value: false}, multipathPath.Value["is_multipath"])
+	suite.Equal(&entities.FactValueBool{Value: true}, multipathPath.Value["multipath_member"])
+	multipathName, gatheringErr := multipathPath.GetValue("multipath.name")
+	suite.Nil(gatheringErr)
+	suite.Equal(&entities.FactValueString{Value: "mpatha"}, multipathName)
+}
+
+func (suite *BlockDevicesTestSuite) TestBlockDevicesGatherNotFound() {
+	suite.mockExecutor.On("Exec", "udevadm", "info", "--query=name", "--name=/dev/sdz").Return(
+		nil, errors.New("exit status 4"))
+
+	g := gatherers.NewBlockDevicesGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "nfs_mount",
+			Gatherer: "block_devices",
+			Argument: "/hana/shared",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "unknown_mount",
+			Gatherer: "block_devices",
+			Argument: "/hana/log",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "unknown_device",
+			Gatherer: "block_devices",
+			Argument: "/dev/sdz",
+			CheckID:  "check3",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "nfs_mount",
+			CheckID: "check1",
+			Error: &entities.FactGatheringError{
+				Type: "block-devices-not-found",
+				Message: "requested block device or mount point not found: " +
+					"/hana/shared is not mounted from a block device",
+			},
+		},
+		{
+			Name:    "unknown_mount",
+			CheckID: "check2",
+			Error: &entities.FactGatheringError{
+				Type:    "block-devices-not-found",
+				Message: "requested block device or mount point not found: /hana/log",
+			},
+		},
+		{
+			Name:    "unknown_device",
+			CheckID: "check3",
+			Error: &entities.FactGatheringError{
+				Type:    "block-devices-not-found",
+				Message: "requested block device or mount point not found: /dev/sdz",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *BlockDevicesTestSuite) TestBlockDevicesGatherLsblkError() {
+	mockExecutor := new(utilsMocks.CommandExecutor)
+	mockExecutor.On("Exec", "lsblk", "--json", "--bytes", "--list", "--output",
+		"NAME,KNAME,PATH,MAJ:MIN,TYPE,SIZE,MODEL,SERIAL,WWN,FSTYPE,PKNAME,ROTA").Return(
+		nil, errors.New("exit status 32"))
+
+	g := gatherers.NewBlockDevicesGatherer(mockExecutor, suite.fs)
+
+	factResults, err := g.Gather([]entities.FactRequest{
+		{
+			Name:     "sbd_device",
+			Gatherer: "block_devices",
+			Argument: "/dev/sda",
+		},
+	})
+
+	suite.EqualError(err, "fact gathering error: block-devices-command-error - "+
+		"error running block devices command: exit status 32")
+	suite.Empty(factResults)
+}
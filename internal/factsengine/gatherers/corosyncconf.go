@@ -4,7 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"regexp"
+	"strings"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -14,12 +14,12 @@ import (
 const (
 	CorosyncConfGathererName = "corosync.conf"
 	CorosyncConfPath         = "/etc/corosync/corosync.conf"
-)
 
-var (
-	sectionStartPatternCompiled = regexp.MustCompile(`^\s*(\w+)\s*{.*`)
-	sectionEndPatternCompiled   = regexp.MustCompile(`^\s*}.*`)
-	valuePatternCompiled        = regexp.MustCompile(`^\s*(\w+)\s*:\s*(\S+).*`)
+	// the arguments with this prefix return the comments of the section or value in the path
+	corosyncCommentsArgumentPrefix = "comments:"
+	// the keys of the comments facts start with #, so they never collide with the corosync names
+	corosyncCommentsKey         = "#comments"
+	corosyncDisabledSettingsKey = "#disabled"
 )
 
 // nolint:gochecknoglobals
@@ -58,17 +58,23 @@ func (s *CorosyncConfGatherer) Gather(factsRequests []entities.FactRequest) ([]e
 		return nil, CorosyncConfFileError.Wrap(err.Error())
 	}
 
-	elementsToList := map[string]bool{"interface": true, "node": true}
-
-	corosyncMap, err := corosyncConfToMap(corosyncConfile, elementsToList)
+	corosyncConf, err := parseCorosyncConf(tokenizeCorosyncConf(corosyncConfile))
 	if err != nil {
 		return nil, CorosyncConfDecodingError.Wrap(err.Error())
 	}
 
+	corosyncMap := corosyncConf.toFactValueMap()
+	commentsMap := corosyncConf.toCommentsFactValueMap()
+
 	for _, factReq := range factsRequests {
 		var fact entities.Fact
 
-		if value, err := corosyncMap.GetValue(factReq.Argument); err == nil {
+		valuesMap, argument := corosyncMap, factReq.Argument
+		if strings.HasPrefix(argument, corosyncCommentsArgumentPrefix) {
+			valuesMap, argument = commentsMap, strings.TrimPrefix(argument, corosyncCommentsArgumentPrefix)
+		}
+
+		if value, err := valuesMap.GetValue(argument); err == nil {
 			fact = entities.NewFactGatheredWithRequest(factReq, value)
 
 		} else {
@@ -101,58 +107,319 @@ func readCorosyncConfFileByLines(filePath string) ([]string, error) {
 	return fileLines, nil
 }
 
-func corosyncConfToMap(lines []string, elementsToList map[string]bool) (*entities.FactValueMap, error) {
-	var cm = make(map[string]entities.FactValue)
-	var sections int
+type corosyncTokenKind int
+
+const (
+	corosyncComment corosyncTokenKind = iota
+	corosyncSectionStart
+	corosyncSectionEnd
+	corosyncKeyValue
+	corosyncInvalid
+)
+
+type corosyncToken struct {
+	kind  corosyncTokenKind
+	line  int
+	key   string
+	value string
+}
+
+type corosyncValue struct {
+	key      string
+	value    string
+	line     int
+	comments []string
+}
+
+type corosyncSection struct {
+	name     string
+	line     int
+	comments []string
+	values   []*corosyncValue
+	sections []*corosyncSection
+	// disabled are the settings commented out, like # bindnetaddr: 192.168.1.1
+	disabled []*corosyncValue
+}
+
+// nolint:gochecknoglobals
+// corosyncListSections are the sections that can be repeated, always represented as a list,
+// even if they appear only once. The other sections are merged if repeated, as corosync does
+var corosyncListSections = map[string]bool{
+	"interface":     true,
+	"node":          true,
+	"logger_subsys": true,
+}
+
+// tokenizeCorosyncConf splits the corosync.conf lines in tokens, following the
+// grammar used by corosync itself: comments start with #, sections are opened
+// with "<name> {" and closed with "}", and values are set with "<key>: <value>",
+// where the value is the remaining text of the line
+func tokenizeCorosyncConf(lines []string) []corosyncToken {
+	tokens := []corosyncToken{}
 
 	for index, line := range lines {
-		if start := sectionStartPatternCompiled.FindStringSubmatch(line); start != nil {
-			if sections == 0 {
-				sectionKey := start[1]
-				_, found := cm[sectionKey]
-				if !found && elementsToList[sectionKey] {
-					cm[sectionKey] = &entities.FactValueList{Value: []entities.FactValue{}}
-				}
-
-				children, _ := corosyncConfToMap(lines[index+1:], elementsToList)
-
-				if elementsToList[sectionKey] {
-					factList, ok := cm[sectionKey].(*entities.FactValueList)
-					if !ok {
-						return nil, fmt.Errorf("error asserting to list type for key: %s", sectionKey)
-					}
-					factList.AppendValue(children)
-				} else {
-					cm[sectionKey] = children
-				}
-			}
-			sections++
+		lineNumber := index + 1
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
 			continue
+		case strings.HasPrefix(trimmed, "#"):
+			tokens = append(tokens, corosyncToken{
+				kind:  corosyncComment,
+				line:  lineNumber,
+				value: strings.TrimSpace(strings.TrimPrefix(trimmed, "#")),
+			})
+		case isCorosyncKeyValue(trimmed):
+			separator := strings.Index(trimmed, ":")
+			key := strings.TrimSpace(trimmed[:separator])
+			kind := corosyncKeyValue
+			if key == "" || strings.ContainsAny(key, " \t") {
+				kind = corosyncInvalid
+			}
+			tokens = append(tokens, corosyncToken{
+				kind:  kind,
+				line:  lineNumber,
+				key:   key,
+				value: strings.TrimSpace(trimmed[separator+1:]),
+			})
+			if kind == corosyncInvalid {
+				tokens[len(tokens)-1].value = trimmed
+			}
+		case strings.Contains(trimmed, "{"):
+			name := strings.TrimSpace(trimmed[:strings.Index(trimmed, "{")])
+			kind := corosyncSectionStart
+			if name == "" || strings.ContainsAny(name, " \t:") {
+				kind = corosyncInvalid
+			}
+			tokens = append(tokens, corosyncToken{kind: kind, line: lineNumber, key: name, value: trimmed})
+		case isCorosyncSectionEnd(trimmed):
+			tokens = append(tokens, corosyncToken{kind: corosyncSectionEnd, line: lineNumber})
+		default:
+			tokens = append(tokens, corosyncToken{kind: corosyncInvalid, line: lineNumber, value: trimmed})
 		}
+	}
+
+	return tokens
+}
 
-		if end := sectionEndPatternCompiled.FindStringSubmatch(line); end != nil {
-			if sections == 0 {
-				return &entities.FactValueMap{
-					Value: cm,
-				}, nil
+// isCorosyncSectionEnd tells if the line closes a section, optionally followed by a comment
+func isCorosyncSectionEnd(line string) bool {
+	if !strings.HasPrefix(line, "}") {
+		return false
+	}
+
+	rest := strings.TrimSpace(strings.TrimPrefix(line, "}"))
+	return rest == "" || strings.HasPrefix(rest, "#")
+}
+
+// disabledCorosyncSetting returns the key and value of a commented out setting, like
+// "bindnetaddr: 192.168.1.1". Only single word values are considered, so sentences like
+// "interface: define at least one interface" are kept as comments
+func disabledCorosyncSetting(comment string) (string, string, bool) {
+	if !isCorosyncKeyValue(comment) {
+		return "", "", false
+	}
+
+	separator := strings.Index(comment, ":")
+	key := strings.TrimSpace(comment[:separator])
+	value := strings.TrimSpace(comment[separator+1:])
+	if key == "" || value == "" || strings.ContainsAny(key, " \t") || strings.ContainsAny(value, " \t") {
+		return "", "", false
+	}
+
+	return key, value, true
+}
+
+// isCorosyncKeyValue tells if the line sets a value, checking the "<key>:" prefix before
+// looking for a section start, as values like ring0_addr: {addr} might contain a {
+func isCorosyncKeyValue(line string) bool {
+	separator := strings.Index(line, ":")
+	if separator <= 0 {
+		return false
+	}
+
+	return !strings.ContainsAny(line[:separator], "{}")
+}
+
+// parseCorosyncConf builds the corosync.conf sections tree from the tokens.
+// Comments are attached to the value or section that follows them. The comments
+// preceding a commented out setting belong to it, not to the next value
+func parseCorosyncConf(tokens []corosyncToken) (*corosyncSection, error) {
+	root := &corosyncSection{
+		name:     "",
+		line:     0,
+		comments: []string{},
+		values:   []*corosyncValue{},
+		sections: []*corosyncSection{},
+		disabled: []*corosyncValue{},
+	}
+	stack := []*corosyncSection{root}
+	comments := []string{}
+
+	for _, token := range tokens {
+		current := stack[len(stack)-1]
+
+		switch token.kind {
+		case corosyncComment:
+			if key, value, ok := disabledCorosyncSetting(token.value); ok {
+				current.disabled = append(current.disabled, &corosyncValue{
+					key:      key,
+					value:    value,
+					line:     token.line,
+					comments: comments,
+				})
+				comments = []string{}
+				continue
 			}
-			sections--
-			continue
+			comments = append(comments, token.value)
+		case corosyncSectionStart:
+			section := &corosyncSection{
+				name:     token.key,
+				line:     token.line,
+				comments: comments,
+				values:   []*corosyncValue{},
+				sections: []*corosyncSection{},
+				disabled: []*corosyncValue{},
+			}
+			current.sections = append(current.sections, section)
+			stack = append(stack, section)
+			comments = []string{}
+		case corosyncSectionEnd:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("line %d: unexpected section end", token.line)
+			}
+			stack = stack[:len(stack)-1]
+			comments = []string{}
+		case corosyncKeyValue:
+			current.values = append(current.values, &corosyncValue{
+				key:      token.key,
+				value:    token.value,
+				line:     token.line,
+				comments: comments,
+			})
+			comments = []string{}
+		case corosyncInvalid:
+			return nil, fmt.Errorf("line %d: invalid entry: %s", token.line, token.value)
 		}
+	}
 
-		if value := valuePatternCompiled.FindStringSubmatch(line); value != nil && sections == 0 {
-			cm[value[1]] = entities.ParseStringToFactValue(value[2])
-			continue
+	if len(stack) > 1 {
+		unclosed := stack[len(stack)-1]
+		return nil, fmt.Errorf("line %d: section %s is not closed properly", unclosed.line, unclosed.name)
+	}
+
+	return root, nil
+}
+
+// toFactValueMap converts the section to a FactValueMap. Repeated values keep the
+// last occurrence, as corosync does, and the list sections are converted to lists
+func (s *corosyncSection) toFactValueMap() *entities.FactValueMap {
+	sectionMap := make(map[string]entities.FactValue)
+
+	for _, value := range s.values {
+		sectionMap[value.key] = entities.ParseStringToFactValue(value.value)
+	}
+
+	for _, group := range groupCorosyncSections(s.sections) {
+		sectionMap[group.name] = group.toFactValue(func(section *corosyncSection) entities.FactValue {
+			return section.toFactValueMap()
+		})
+	}
+
+	return &entities.FactValueMap{Value: sectionMap}
+}
+
+// toCommentsFactValueMap returns the comments of the section with the same shape as toFactValueMap.
+// Each value has the list of comments preceding it, the comments of the section itself are in
+// the #comments key and the commented out settings in the #disabled key
+func (s *corosyncSection) toCommentsFactValueMap() *entities.FactValueMap {
+	commentsMap := map[string]entities.FactValue{
+		corosyncCommentsKey:         stringsToFactValueList(s.comments),
+		corosyncDisabledSettingsKey: &entities.FactValueMap{Value: map[string]entities.FactValue{}},
+	}
+
+	for _, value := range s.values {
+		commentsMap[value.key] = stringsToFactValueList(value.comments)
+	}
+
+	disabled := commentsMap[corosyncDisabledSettingsKey].(*entities.FactValueMap)
+	for _, value := range s.disabled {
+		disabled.Value[value.key] = entities.ParseStringToFactValue(value.value)
+	}
+
+	for _, group := range groupCorosyncSections(s.sections) {
+		commentsMap[group.name] = group.toFactValue(func(section *corosyncSection) entities.FactValue {
+			return section.toCommentsFactValueMap()
+		})
+	}
+
+	return &entities.FactValueMap{Value: commentsMap}
+}
+
+type corosyncSectionGroup struct {
+	name     string
+	isList   bool
+	sections []*corosyncSection
+}
+
+// toFactValue converts the group to a list for the list sections, or to the merged section
+func (g *corosyncSectionGroup) toFactValue(convert func(*corosyncSection) entities.FactValue) entities.FactValue {
+	if !g.isList {
+		return convert(mergeCorosyncSections(g.sections))
+	}
+
+	list := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, section := range g.sections {
+		list.AppendValue(convert(section))
+	}
+	return list
+}
+
+// groupCorosyncSections groups the sections by name, keeping the order of their first appearance
+func groupCorosyncSections(sections []*corosyncSection) []*corosyncSectionGroup {
+	groups := []*corosyncSectionGroup{}
+	groupsByName := make(map[string]*corosyncSectionGroup)
+
+	for _, section := range sections {
+		group, found := groupsByName[section.name]
+		if !found {
+			group = &corosyncSectionGroup{
+				name:     section.name,
+				isList:   corosyncListSections[section.name],
+				sections: []*corosyncSection{},
+			}
+			groupsByName[section.name] = group
+			groups = append(groups, group)
 		}
+		group.sections = append(group.sections, section)
+	}
+
+	return groups
+}
+
+// mergeCorosyncSections merges repeated sections in one, the values of the last sections
+// overriding the previous ones
+func mergeCorosyncSections(sections []*corosyncSection) *corosyncSection {
+	if len(sections) == 1 {
+		return sections[0]
 	}
 
-	corosyncMap := &entities.FactValueMap{
-		Value: cm,
+	merged := &corosyncSection{
+		name:     sections[0].name,
+		line:     sections[0].line,
+		comments: []string{},
+		values:   []*corosyncValue{},
+		sections: []*corosyncSection{},
+		disabled: []*corosyncValue{},
 	}
 
-	if sections != 0 {
-		return corosyncMap, fmt.Errorf("invalid corosync file structure. some section is not closed properly")
+	for _, section := range sections {
+		merged.comments = append(merged.comments, section.comments...)
+		merged.values = append(merged.values, section.values...)
+		merged.sections = append(merged.sections, section.sections...)
+		merged.disabled = append(merged.disabled, section.disabled...)
 	}
 
-	return corosyncMap, nil
+	return merged
 }
@@ -238,11 +238,284 @@ func (suite *CorosyncConfTestSuite) TestCorosyncConfInvalid() {
 	factsGathered, err := c.Gather(factsRequest)
 
 	expectedError := &entities.FactGatheringError{
-		Message: "error decoding corosync.conf file: invalid corosync file structure. " +
-			"some section is not closed properly",
-		Type: "corosync-conf-decoding-error",
+		Message: "error decoding corosync.conf file: line 110: section quorum is not closed properly",
+		Type:    "corosync-conf-decoding-error",
 	}
 
 	suite.EqualError(err, expectedError.Error())
 	suite.Empty(factsGathered)
 }
+
+func (suite *CorosyncConfTestSuite) TestCorosyncConfRepeatedSections() {
+	c := NewCorosyncConfGatherer(helpers.GetFixturePath("gatherers/corosync.conf.repeated"))
+
+	factsRequest := []entities.FactRequest{
+		{
+			Name:     "corosync_cluster_name",
+			Gatherer: "corosync.conf",
+			Argument: "totem.cluster_name",
+		},
+		{
+			Name:     "corosync_second_link_priority",
+			Gatherer: "corosync.conf",
+			Argument: "totem.interface.1.knet_link_priority",
+		},
+		{
+			Name:     "corosync_logger_subsys",
+			Gatherer: "corosync.conf",
+			Argument: "logging.logger_subsys",
+		},
+		{
+			Name:     "corosync_qdevice_host",
+			Gatherer: "corosync.conf",
+			Argument: "quorum.device.net.host",
+		},
+		{
+			Name:     "corosync_system",
+			Gatherer: "corosync.conf",
+			Argument: "system",
+		},
+		{
+			Name:     "corosync_node_name",
+			Gatherer: "corosync.conf",
+			Argument: "nodelist.node.1.name",
+		},
+	}
+
+	factsGathered, err := c.Gather(factsRequest)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "corosync_cluster_name",
+			Value: &entities.FactValueString{Value: "hana cluster"},
+			Error: nil,
+		},
+		{
+			Name:  "corosync_second_link_priority",
+			Value: &entities.FactValueInt{Value: 5},
+			Error: nil,
+		},
+		{
+			Name: "corosync_logger_subsys",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"subsys": &entities.FactValueString{Value: "QUORUM"},
+					"debug":  &entities.FactValueString{Value: "off"},
+				}},
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"subsys": &entities.FactValueString{Value: "KNET"},
+					"debug":  &entities.FactValueString{Value: "trace"},
+				}},
+			}},
+			Error: nil,
+		},
+		{
+			Name:  "corosync_qdevice_host",
+			Value: &entities.FactValueString{Value: "qnetd.example.com"},
+			Error: nil,
+		},
+		{
+			Name: "corosync_system",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"move_to_root_cgroup": &entities.FactValueString{Value: "auto"},
+				"sched_rr":            &entities.FactValueString{Value: "yes"},
+			}},
+			Error: nil,
+		},
+		{
+			Name:  "corosync_node_name",
+			Value: &entities.FactValueString{Value: "hana02"},
+			Error: nil,
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factsGathered)
+}
+
+func (suite *CorosyncConfTestSuite) TestCorosyncConfInvalidEntry() {
+	c := NewCorosyncConfGatherer(helpers.GetFixturePath("gatherers/corosync.conf.invalid_entry"))
+
+	factsGathered, err := c.Gather([]entities.FactRequest{})
+
+	expectedError := &entities.FactGatheringError{
+		Message: "error decoding corosync.conf file: line 3: invalid entry: token 5000",
+		Type:    "corosync-conf-decoding-error",
+	}
+
+	suite.EqualError(err, expectedError.Error())
+	suite.Empty(factsGathered)
+}
+
+func (suite *CorosyncConfTestSuite) TestCorosyncConfUnexpectedSectionEnd() {
+	c := NewCorosyncConfGatherer(helpers.GetFixturePath("gatherers/corosync.conf.unexpected_end"))
+
+	factsGathered, err := c.Gather([]entities.FactRequest{})
+
+	expectedError := &entities.FactGatheringError{
+		Message: "error decoding corosync.conf file: line 4: unexpected section end",
+		Type:    "corosync-conf-decoding-error",
+	}
+
+	suite.EqualError(err, expectedError.Error())
+	suite.Empty(factsGathered)
+}
+
+func (suite *CorosyncConfTestSuite) TestCorosyncConfComments() {
+	c := NewCorosyncConfGatherer(helpers.GetFixturePath("gatherers/corosync.conf.basic"))
+
+	factsRequest := []entities.FactRequest{
+		{
+			Name:     "corosync_interface_comments",
+			Gatherer: "corosync.conf",
+			Argument: "comments:totem.interface.0.#comments",
+		},
+		{
+			Name:     "corosync_mcastport_comments",
+			Gatherer: "corosync.conf",
+			Argument: "comments:totem.interface.0.mcastport",
+		},
+		{
+			Name:     "corosync_disabled_settings",
+			Gatherer: "corosync.conf",
+			Argument: "comments:totem.interface.0.#disabled",
+		},
+		{
+			Name:     "corosync_interface",
+			Gatherer: "corosync.conf",
+			Argument: "totem.interface.0",
+		},
+	}
+
+	factsGathered, err := c.Gather(factsRequest)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "corosync_interface_comments",
+			Value: corosyncComments(
+				"interface: define at least one interface to communicate",
+				"over. If you define more than one interface stanza, you must",
+				"also set rrp_mode.",
+			),
+			Error: nil,
+		},
+		{
+			Name: "corosync_mcastport_comments",
+			Value: corosyncComments(
+				"Corosync uses the port you specify here for UDP",
+				"messaging, and also the immediately preceding",
+				"port. Thus if you set this to 5405, Corosync sends",
+				"messages over UDP ports 5405 and 5404.",
+			),
+			Error: nil,
+		},
+		{
+			Name: "corosync_disabled_settings",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"bindnetaddr": &entities.FactValueString{Value: "192.168.1.1"},
+				"mcastaddr":   &entities.FactValueString{Value: "239.255.1.1"},
+			}},
+			Error: nil,
+		},
+		{
+			Name: "corosync_interface",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"ringnumber":  &entities.FactValueInt{Value: 0},
+				"bindnetaddr": &entities.FactValueString{Value: "192.168.1.0"},
+				"mcastport":   &entities.FactValueInt{Value: 5405},
+				"ttl":         &entities.FactValueInt{Value: 1},
+			}},
+			Error: nil,
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factsGathered)
+}
+
+func (suite *CorosyncConfTestSuite) TestCorosyncConfCommentsTree() {
+	lines := []string{
+		"# main section",
+		"totem {",
+		"\t# token timeout in ms",
+		"\ttoken: 5000",
+		"\tcluster_name: hana",
+		"\tinterface {",
+		"\t\t# braces in a value",
+		"\t\tbindnetaddr: {192.168.1.0}",
+		"\t} # end of interface",
+		"}",
+	}
+
+	corosyncConf, err := parseCorosyncConf(tokenizeCorosyncConf(lines))
+
+	suite.NoError(err)
+	suite.Equal(&entities.FactValueMap{Value: map[string]entities.FactValue{
+		"totem": &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"token":        &entities.FactValueInt{Value: 5000},
+			"cluster_name": &entities.FactValueString{Value: "hana"},
+			"interface": &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"bindnetaddr": &entities.FactValueString{Value: "{192.168.1.0}"},
+				}},
+			}},
+		}},
+	}}, corosyncConf.toFactValueMap())
+
+	emptyDisabled := func() *entities.FactValueMap {
+		return &entities.FactValueMap{Value: map[string]entities.FactValue{}}
+	}
+	suite.Equal(&entities.FactValueMap{Value: map[string]entities.FactValue{
+		"#comments": corosyncComments(),
+		"#disabled": emptyDisabled(),
+		"totem": &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"#comments":    corosyncComments("main section"),
+			"#disabled":    emptyDisabled(),
+			"token":        corosyncComments("token timeout in ms"),
+			"cluster_name": corosyncComments(),
+			"interface": &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"#comments":   corosyncComments(),
+					"#disabled":   emptyDisabled(),
+					"bindnetaddr": corosyncComments("braces in a value"),
+				}},
+			}},
+		}},
+	}}, corosyncConf.toCommentsFactValueMap())
+}
+
+func corosyncComments(comments ...string) *entities.FactValueList {
+	list := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, comment := range comments {
+		list.AppendValue(&entities.FactValueString{Value: comment})
+	}
+	return list
+}
+
+func (suite *CorosyncConfTestSuite) TestParseCorosyncConfComments() {
+	lines := []string{
+		"# main section",
+		"totem {",
+		"\t# token timeout in ms",
+		"\ttoken: 5000 # not a comment",
+		"}",
+	}
+
+	corosyncConf, err := parseCorosyncConf(tokenizeCorosyncConf(lines))
+
+	suite.NoError(err)
+	suite.Len(corosyncConf.sections, 1)
+
+	totem := corosyncConf.sections[0]
+	suite.Equal("totem", totem.name)
+	suite.Equal(2, totem.line)
+	suite.Equal([]string{"main section"}, totem.comments)
+	suite.Equal([]*corosyncValue{
+		{
+			key:      "token",
+			value:    "5000 # not a comment",
+			line:     4,
+			comments: []string{"token timeout in ms"},
+		},
+	}, totem.values)
+}
@@ -0,0 +1,354 @@
+package gatherers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	CorosyncStatusGathererName = "corosync_status"
+	corosyncStatusQuorumKey    = "quorum"
+	corosyncStatusLinksKey     = "links"
+)
+
+var (
+	quorumFieldCompiled      = regexp.MustCompile(`^([A-Za-z][A-Za-z ]*):\s+(.*)$`)
+	cfgtoolLocalNodeCompiled = regexp.MustCompile(`^Local node ID (\d+)(?:, transport (\S+))?`)
+	cfgtoolLinkCompiled      = regexp.MustCompile(`^(?:LINK|RING) ID (\d+)\s*(\S*)`)
+	cfgtoolAddressCompiled   = regexp.MustCompile(`^(?:addr|id)\s*=\s*(\S+)`)
+	cfgtoolRingStatus        = regexp.MustCompile(`^status\s*=\s*(.+)$`)
+	cfgtoolNodeCompiled      = regexp.MustCompile(`^nodeid:?\s*(\d+):?\s+(.+)$`)
+)
+
+// nolint:gochecknoglobals
+var (
+	CorosyncStatusCommandError = entities.FactGatheringError{
+		Type:    "corosync-status-command-error",
+		Message: "error executing corosync status command",
+	}
+
+	CorosyncStatusDecodingError = entities.FactGatheringError{
+		Type:    "corosync-status-decoding-error",
+		Message: "error decoding corosync status output",
+	}
+
+	CorosyncStatusUnknownArgumentError = entities.FactGatheringError{
+		Type:    "corosync-status-unknown-argument",
+		Message: "the requested argument is not supported",
+	}
+)
+
+type CorosyncStatusGatherer struct {
+	executor utils.CommandExecutor
+}
+
+func NewDefaultCorosyncStatusGatherer() *CorosyncStatusGatherer {
+	return NewCorosyncStatusGatherer(utils.Executor{})
+}
+
+func NewCorosyncStatusGatherer(executor utils.CommandExecutor) *CorosyncStatusGatherer {
+	return &CorosyncStatusGatherer{
+		executor: executor,
+	}
+}
+
+// Gather returns the runtime quorum and link status of corosync.
+// The argument starts with quorum (corosync-quorumtool -s) or links (corosync-cfgtool -s),
+// and the dot access format can be used to get inner values, like quorum.quorate
+func (g *CorosyncStatusGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", CorosyncStatusGathererName)
+
+	statuses := make(map[string]*entities.FactValueMap)
+	statusErrors := make(map[string]*entities.FactGatheringError)
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		key := strings.SplitN(factReq.Argument, ".", 2)[0]
+
+		status, found := statuses[key]
+		gatheringError := statusErrors[key]
+		if !found && gatheringError == nil {
+			status, gatheringError = g.getStatus(key)
+			statuses[key] = status
+			statusErrors[key] = gatheringError
+		}
+
+		if gatheringError != nil {
+			log.Error(gatheringError)
+			facts = append(facts, entities.NewFactGatheredWithError(factReq, gatheringError))
+			continue
+		}
+
+		statusMap := &entities.FactValueMap{Value: map[string]entities.FactValue{key: status}}
+		if value, err := statusMap.GetValue(factReq.Argument); err == nil {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		} else {
+			log.Error(err)
+			fact = entities.NewFactGatheredWithError(factReq, err)
+		}
+
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", CorosyncStatusGathererName)
+	return facts, nil
+}
+
+func (g *CorosyncStatusGatherer) getStatus(key string) (*entities.FactValueMap, *entities.FactGatheringError) {
+	switch key {
+	case corosyncStatusQuorumKey:
+		// corosync-quorumtool returns an error code if the node is not quorate,
+		// but the output is printed anyway
+		output, err := g.executor.Exec("corosync-quorumtool", "-s")
+		if err != nil && len(output) == 0 {
+			return nil, CorosyncStatusCommandError.Wrap(err.Error())
+		}
+		status, err := parseQuorumtoolStatus(string(output))
+		if err != nil {
+			return nil, CorosyncStatusDecodingError.Wrap(err.Error())
+		}
+		return status, nil
+	case corosyncStatusLinksKey:
+		output, err := g.executor.Exec("corosync-cfgtool", "-s")
+		if err != nil && len(output) == 0 {
+			return nil, CorosyncStatusCommandError.Wrap(err.Error())
+		}
+		status, err := parseCfgtoolStatus(string(output))
+		if err != nil {
+			return nil, CorosyncStatusDecodingError.Wrap(err.Error())
+		}
+		return status, nil
+	default:
+		return nil, CorosyncStatusUnknownArgumentError.Wrap(key)
+	}
+}
+
+// parseQuorumtoolStatus parses the corosync-quorumtool -s output. Example:
+// Quorum provider:  corosync_votequorum
+// Nodes:            2
+// Quorate:          Yes
+// ...
+// Membership information
+// ----------------------
+//
+//	Nodeid      Votes Name
+//	     1          1 hana01 (local)
+//	     2          1 hana02
+func parseQuorumtoolStatus(output string) (*entities.FactValueMap, error) {
+	status := make(map[string]entities.FactValue)
+	members := &entities.FactValueList{Value: []entities.FactValue{}}
+	inMembership := false
+	hasQdeviceColumn := false
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "---") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "Membership information") {
+			inMembership = true
+			continue
+		}
+
+		if inMembership {
+			fields := strings.Fields(trimmed)
+			if fields[0] == "Nodeid" {
+				hasQdeviceColumn = strings.Contains(trimmed, "Qdevice")
+				continue
+			}
+			member, err := parseQuorumtoolMember(fields, hasQdeviceColumn)
+			if err != nil {
+				return nil, err
+			}
+			members.AppendValue(member)
+			continue
+		}
+
+		match := quorumFieldCompiled.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(match[1])), " ", "_")
+		value := strings.TrimSpace(match[2])
+
+		switch key {
+		case "quorate":
+			status[key] = &entities.FactValueBool{Value: strings.EqualFold(value, "yes")}
+		case "flags":
+			status[key] = stringsToFactValueList(strings.Fields(value))
+		case "quorum":
+			// the quorum value is followed by "Activity blocked" when the node is not quorate
+			fields := strings.Fields(value)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("empty quorum value")
+			}
+			status[key] = entities.ParseStringToFactValue(fields[0])
+			status["activity_blocked"] = &entities.FactValueBool{Value: strings.Contains(value, "Activity blocked")}
+		case "ring_id", "date":
+			status[key] = &entities.FactValueString{Value: value}
+		default:
+			status[key] = entities.ParseStringToFactValue(value)
+		}
+	}
+
+	if _, found := status["quorate"]; !found {
+		return nil, fmt.Errorf("quorate field not found")
+	}
+
+	status["members"] = members
+
+	return &entities.FactValueMap{Value: status}, nil
+}
+
+func parseQuorumtoolMember(fields []string, hasQdeviceColumn bool) (entities.FactValue, error) {
+	minFields := 2
+	if hasQdeviceColumn {
+		minFields = 3
+	}
+	if len(fields) < minFields {
+		return nil, fmt.Errorf("invalid membership line: %s", strings.Join(fields, " "))
+	}
+
+	nodeID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid node id: %s", fields[0])
+	}
+
+	votes, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid votes value: %s", fields[1])
+	}
+
+	member := map[string]entities.FactValue{
+		"node_id": &entities.FactValueInt{Value: nodeID},
+		"votes":   &entities.FactValueInt{Value: votes},
+	}
+
+	rest := fields[2:]
+	if hasQdeviceColumn {
+		// the qdevice line itself doesn't have a state column, just the Qdevice name
+		if rest[0] != "Qdevice" {
+			member["qdevice"] = &entities.FactValueString{Value: rest[0]}
+			rest = rest[1:]
+		}
+	}
+
+	local := false
+	if len(rest) > 0 && rest[len(rest)-1] == "(local)" {
+		local = true
+		rest = rest[:len(rest)-1]
+	}
+
+	member["name"] = &entities.FactValueString{Value: strings.Join(rest, " ")}
+	member["local"] = &entities.FactValueBool{Value: local}
+
+	return &entities.FactValueMap{Value: member}, nil
+}
+
+// parseCfgtoolStatus parses the corosync-cfgtool -s output, in the knet format used by
+// corosync 3, and in the ring format used by corosync 2. Example:
+// Local node ID 1, transport knet
+// LINK ID 0 udp
+//
+//	addr	= 10.0.0.1
+//	status:
+//		nodeid:          1:	localhost
+//		nodeid:          2:	connected
+func parseCfgtoolStatus(output string) (*entities.FactValueMap, error) {
+	status := make(map[string]entities.FactValue)
+	links := &entities.FactValueList{Value: []entities.FactValue{}}
+	var currentLink map[string]entities.FactValue
+	var currentNodes *entities.FactValueList
+
+	closeLink := func() {
+		if currentLink == nil {
+			return
+		}
+		connected, found := currentLink["connected"]
+		if !found {
+			connected = &entities.FactValueBool{Value: true}
+			for _, node := range currentNodes.Value {
+				nodeMap, _ := node.(*entities.FactValueMap)
+				if nodeConnected, _ := nodeMap.Value["connected"].(*entities.FactValueBool); !nodeConnected.Value {
+					connected = &entities.FactValueBool{Value: false}
+				}
+			}
+		}
+		currentLink["connected"] = connected
+		currentLink["nodes"] = currentNodes
+		links.AppendValue(&entities.FactValueMap{Value: currentLink})
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if match := cfgtoolLocalNodeCompiled.FindStringSubmatch(trimmed); match != nil {
+			status["local_node_id"] = entities.ParseStringToFactValue(match[1])
+			if match[2] != "" {
+				status["transport"] = &entities.FactValueString{Value: match[2]}
+			}
+			continue
+		}
+
+		if match := cfgtoolLinkCompiled.FindStringSubmatch(trimmed); match != nil {
+			closeLink()
+			linkID, _ := strconv.Atoi(match[1])
+			currentLink = map[string]entities.FactValue{
+				"id": &entities.FactValueInt{Value: linkID},
+			}
+			if match[2] != "" {
+				currentLink["protocol"] = &entities.FactValueString{Value: match[2]}
+			}
+			currentNodes = &entities.FactValueList{Value: []entities.FactValue{}}
+			continue
+		}
+
+		if currentLink == nil {
+			continue
+		}
+
+		if match := cfgtoolAddressCompiled.FindStringSubmatch(trimmed); match != nil {
+			currentLink["address"] = &entities.FactValueString{Value: match[1]}
+			continue
+		}
+
+		if match := cfgtoolRingStatus.FindStringSubmatch(trimmed); match != nil {
+			ringStatus := strings.TrimSpace(match[1])
+			currentLink["status"] = &entities.FactValueString{Value: ringStatus}
+			currentLink["connected"] = &entities.FactValueBool{
+				Value: !strings.Contains(strings.ToUpper(ringStatus), "FAULTY"),
+			}
+			continue
+		}
+
+		if match := cfgtoolNodeCompiled.FindStringSubmatch(trimmed); match != nil {
+			nodeID, _ := strconv.Atoi(match[1])
+			nodeStatus := strings.TrimSpace(match[2])
+			currentNodes.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+				"node_id":   &entities.FactValueInt{Value: nodeID},
+				"status":    &entities.FactValueString{Value: nodeStatus},
+				"connected": &entities.FactValueBool{Value: nodeStatus == "connected" || nodeStatus == "localhost"},
+			}})
+		}
+	}
+
+	closeLink()
+
+	if _, found := status["local_node_id"]; !found {
+		return nil, fmt.Errorf("local node id not found")
+	}
+
+	status["links"] = links
+
+	return &entities.FactValueMap{Value: status}, nil
+}
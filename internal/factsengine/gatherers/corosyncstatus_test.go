@@ -0,0 +1,344 @@
+package gatherers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+)
+
+type CorosyncStatusTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+}
+
+func TestCorosyncStatusTestSuite(t *testing.T) {
+	suite.Run(t, new(CorosyncStatusTestSuite))
+}
+
+func (suite *CorosyncStatusTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+}
+
+func (suite *CorosyncStatusTestSuite) TestCorosyncStatusGatherQuorum() {
+	suite.mockExecutor.On("Exec", "corosync-quorumtool", "-s").Return(
+		readFixture("gatherers/corosync-quorumtool.output"), nil).Once()
+
+	c := gatherers.NewCorosyncStatusGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "quorate",
+			Gatherer: "corosync_status",
+			Argument: "quorum.quorate",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "expected_votes",
+			Gatherer: "corosync_status",
+			Argument: "quorum.expected_votes",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "quorum",
+			Gatherer: "corosync_status",
+			Argument: "quorum",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "quorate",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check1",
+		},
+		{
+			Name:    "expected_votes",
+			Value:   &entities.FactValueInt{Value: 2},
+			CheckID: "check1",
+		},
+		{
+			Name: "quorum",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"date":             &entities.FactValueString{Value: "Thu Oct 13 09:12:43 2022"},
+				"quorum_provider":  &entities.FactValueString{Value: "corosync_votequorum"},
+				"nodes":            &entities.FactValueInt{Value: 2},
+				"node_id":          &entities.FactValueInt{Value: 1},
+				"ring_id":          &entities.FactValueString{Value: "1.2c"},
+				"quorate":          &entities.FactValueBool{Value: true},
+				"expected_votes":   &entities.FactValueInt{Value: 2},
+				"highest_expected": &entities.FactValueInt{Value: 2},
+				"total_votes":      &entities.FactValueInt{Value: 2},
+				"quorum":           &entities.FactValueInt{Value: 1},
+				"activity_blocked": &entities.FactValueBool{Value: false},
+				"flags": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "2Node"},
+					&entities.FactValueString{Value: "Quorate"},
+					&entities.FactValueString{Value: "WaitForAll"},
+				}},
+				"members": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"node_id": &entities.FactValueInt{Value: 1},
+						"votes":   &entities.FactValueInt{Value: 1},
+						"name":    &entities.FactValueString{Value: "node01"},
+						"local":   &entities.FactValueBool{Value: true},
+					}},
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"node_id": &entities.FactValueInt{Value: 2},
+						"votes":   &entities.FactValueInt{Value: 1},
+						"name":    &entities.FactValueString{Value: "node02"},
+						"local":   &entities.FactValueBool{Value: false},
+					}},
+				}},
+			}},
+			CheckID: "check2",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockExecutor.AssertNumberOfCalls(suite.T(), "Exec", 1)
+}
+
+func (suite *CorosyncStatusTestSuite) TestCorosyncStatusGatherQuorumNotQuorate() {
+	suite.mockExecutor.On("Exec", "corosync-quorumtool", "-s").Return(
+		readFixture("gatherers/corosync-quorumtool-qdevice.output"), errors.New("exit status 2"))
+
+	c := gatherers.NewCorosyncStatusGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "quorate",
+			Gatherer: "corosync_status",
+			Argument: "quorum.quorate",
+		},
+		{
+			Name:     "activity_blocked",
+			Gatherer: "corosync_status",
+			Argument: "quorum.activity_blocked",
+		},
+		{
+			Name:     "members",
+			Gatherer: "corosync_status",
+			Argument: "quorum.members",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "quorate",
+			Value: &entities.FactValueBool{Value: false},
+		},
+		{
+			Name:  "activity_blocked",
+			Value: &entities.FactValueBool{Value: true},
+		},
+		{
+			Name: "members",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"node_id": &entities.FactValueInt{Value: 2},
+					"votes":   &entities.FactValueInt{Value: 1},
+					"qdevice": &entities.FactValueString{Value: "A,NV,NMW"},
+					"name":    &entities.FactValueString{Value: "node02"},
+					"local":   &entities.FactValueBool{Value: true},
+				}},
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"node_id": &entities.FactValueInt{Value: 0},
+					"votes":   &entities.FactValueInt{Value: 1},
+					"name":    &entities.FactValueString{Value: "Qdevice"},
+					"local":   &entities.FactValueBool{Value: false},
+				}},
+			}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *CorosyncStatusTestSuite) TestCorosyncStatusGatherLinks() {
+	suite.mockExecutor.On("Exec", "corosync-cfgtool", "-s").Return(
+		readFixture("gatherers/corosync-cfgtool.output"), nil)
+
+	c := gatherers.NewCorosyncStatusGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "links",
+			Gatherer: "corosync_status",
+			Argument: "links",
+		},
+		{
+			Name:     "second_link_connected",
+			Gatherer: "corosync_status",
+			Argument: "links.links.1.connected",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "links",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"local_node_id": &entities.FactValueInt{Value: 1},
+				"transport":     &entities.FactValueString{Value: "knet"},
+				"links": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"id":        &entities.FactValueInt{Value: 0},
+						"protocol":  &entities.FactValueString{Value: "udp"},
+						"address":   &entities.FactValueString{Value: "10.0.0.1"},
+						"connected": &entities.FactValueBool{Value: true},
+						"nodes": &entities.FactValueList{Value: []entities.FactValue{
+							&entities.FactValueMap{Value: map[string]entities.FactValue{
+								"node_id":   &entities.FactValueInt{Value: 1},
+								"status":    &entities.FactValueString{Value: "localhost"},
+								"connected": &entities.FactValueBool{Value: true},
+							}},
+							&entities.FactValueMap{Value: map[string]entities.FactValue{
+								"node_id":   &entities.FactValueInt{Value: 2},
+								"status":    &entities.FactValueString{Value: "connected"},
+								"connected": &entities.FactValueBool{Value: true},
+							}},
+						}},
+					}},
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"id":        &entities.FactValueInt{Value: 1},
+						"protocol":  &entities.FactValueString{Value: "udp"},
+						"address":   &entities.FactValueString{Value: "10.0.1.1"},
+						"connected": &entities.FactValueBool{Value: false},
+						"nodes": &entities.FactValueList{Value: []entities.FactValue{
+							&entities.FactValueMap{Value: map[string]entities.FactValue{
+								"node_id":   &entities.FactValueInt{Value: 1},
+								"status":    &entities.FactValueString{Value: "localhost"},
+								"connected": &entities.FactValueBool{Value: true},
+							}},
+							&entities.FactValueMap{Value: map[string]entities.FactValue{
+								"node_id":   &entities.FactValueInt{Value: 2},
+								"status":    &entities.FactValueString{Value: "disconnected"},
+								"connected": &entities.FactValueBool{Value: false},
+							}},
+						}},
+					}},
+				}},
+			}},
+		},
+		{
+			Name:  "second_link_connected",
+			Value: &entities.FactValueBool{Value: false},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *CorosyncStatusTestSuite) TestCorosyncStatusGatherRings() {
+	suite.mockExecutor.On("Exec", "corosync-cfgtool", "-s").Return(
+		readFixture("gatherers/corosync-cfgtool-rings.output"), nil)
+
+	c := gatherers.NewCorosyncStatusGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "links",
+			Gatherer: "corosync_status",
+			Argument: "links.links",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "links",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"id":        &entities.FactValueInt{Value: 0},
+					"address":   &entities.FactValueString{Value: "10.0.0.1"},
+					"status":    &entities.FactValueString{Value: "ring 0 active with no faults"},
+					"connected": &entities.FactValueBool{Value: true},
+					"nodes":     &entities.FactValueList{Value: []entities.FactValue{}},
+				}},
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"id":        &entities.FactValueInt{Value: 1},
+					"address":   &entities.FactValueString{Value: "10.0.1.1"},
+					"status":    &entities.FactValueString{Value: "Marking ringid 1 interface 10.0.1.1 FAULTY"},
+					"connected": &entities.FactValueBool{Value: false},
+					"nodes":     &entities.FactValueList{Value: []entities.FactValue{}},
+				}},
+			}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *CorosyncStatusTestSuite) TestCorosyncStatusGatherErrors() {
+	suite.mockExecutor.On("Exec", "corosync-quorumtool", "-s").Return(
+		[]byte{}, errors.New("corosync not running"))
+	suite.mockExecutor.On("Exec", "corosync-cfgtool", "-s").Return(
+		[]byte("Could not initialize corosync configuration API error 2\n"), errors.New("exit status 1"))
+
+	c := gatherers.NewCorosyncStatusGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "quorate",
+			Gatherer: "corosync_status",
+			Argument: "quorum.quorate",
+		},
+		{
+			Name:     "links",
+			Gatherer: "corosync_status",
+			Argument: "links",
+		},
+		{
+			Name:     "unknown",
+			Gatherer: "corosync_status",
+			Argument: "other",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "quorate",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error executing corosync status command: corosync not running",
+				Type:    "corosync-status-command-error",
+			},
+		},
+		{
+			Name:  "links",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error decoding corosync status output: local node id not found",
+				Type:    "corosync-status-decoding-error",
+			},
+		},
+		{
+			Name:  "unknown",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "the requested argument is not supported: other",
+				Type:    "corosync-status-unknown-argument",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
@@ -1,14 +1,19 @@
 package gatherers
 
 import (
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/internal/core/sapsystem"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
 )
 
 type FactGatherer interface {
 	Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error)
 }
 
-func StandardGatherers() map[string]FactGatherer {
+// StandardGatherers returns the built-in gatherers. The SAP gatherers look for the systems
+// in the given installation roots and overrides, like the SAP system discovery
+func StandardGatherers(sapSystemPaths sapsystem.DiscoveryPaths) map[string]FactGatherer {
 	return map[string]FactGatherer{
 		CibAdminGathererName:        NewDefaultCibAdminGatherer(),
 		CorosyncCmapCtlGathererName: NewDefaultCorosyncCmapctlGatherer(),
@@ -17,5 +22,27 @@ func StandardGatherers() map[string]FactGatherer {
 		SystemDGathererName:         NewDefaultSystemDGatherer(),
 		PackageVersionGathererName:  NewDefaultPackageVersionGatherer(),
 		SBDConfigGathererName:       NewDefaultSBDGatherer(),
+		SaptuneGathererName:         NewDefaultSaptuneGatherer(),
+		BlockDevicesGathererName:    NewDefaultBlockDevicesGatherer(),
+		CorosyncStatusGathererName:  NewDefaultCorosyncStatusGatherer(),
+		HanaSRGathererName:          NewHanaSRGatherer(utils.Executor{}, afero.NewOsFs(), sapSystemPaths),
+		SapProfileGathererName:      NewSapProfileGatherer(afero.NewOsFs(), sapSystemPaths),
+		SapControlGathererName:      NewDefaultSapControlGatherer(),
+		MountsGathererName:          NewDefaultMountsGatherer(),
+		NetworkGathererName:         NewDefaultNetworkGatherer(),
+		TimeSyncGathererName:        NewDefaultTimeSyncGatherer(),
+		WatchdogGathererName:        NewDefaultWatchdogGatherer(),
+		SBDDevicesGathererName:      NewDefaultSBDDevicesGatherer(),
+		ResourceAgentGathererName:   NewDefaultResourceAgentGatherer(),
 	}
 }
+
+// stringsToFactValueList converts a list of strings to a FactValueList,
+// without parsing the strings to other types
+func stringsToFactValueList(values []string) *entities.FactValueList {
+	list := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, value := range values {
+		list.AppendValue(&entities.FactValueString{Value: value})
+	}
+	return list
+}
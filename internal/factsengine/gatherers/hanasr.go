@@ -0,0 +1,393 @@
+package gatherers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/internal/core/sapsystem"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	HanaSRGathererName    = "hana_sr"
+	sapcontrolOutputBegin = "SAPCONTROL-OK: <begin>"
+	sapcontrolOutputEnd   = "SAPCONTROL-OK: <end>"
+	hanaSRTimestampLayout = "2006-01-02 15:04:05.999999"
+	hanaSRServicesKey     = "services"
+	hanaSRSitesKey        = "sites"
+	hanaSRServicePrefix   = "service/"
+	hanaSRSitePrefix      = "site/"
+	hanaSRMappingPrefix   = "mapping/"
+)
+
+var (
+	hanaSRArgumentCompiled = regexp.MustCompile(`^([A-Z][A-Z0-9]{2})\.(\d{2})(?:\.(.+))?$`)
+	// siteTier/Site1=1 like hdbnsutil entries, with the site attributes
+	hanaSRSiteAttributeCompiled = regexp.MustCompile(`^site(Tier|ReplicationMode|OperationMode|Mapping)/(.+)$`)
+)
+
+// nolint:gochecknoglobals
+var (
+	HanaSRInvalidArgumentError = entities.FactGatheringError{
+		Type:    "hana-sr-invalid-argument",
+		Message: "invalid argument, the format is <SID>.<instance number>",
+	}
+
+	HanaSRSystemNotFoundError = entities.FactGatheringError{
+		Type:    "hana-sr-system-not-found",
+		Message: "hana system not found",
+	}
+
+	HanaSRCommandError = entities.FactGatheringError{
+		Type:    "hana-sr-command-error",
+		Message: "error executing hana system replication command",
+	}
+
+	HanaSRDecodingError = entities.FactGatheringError{
+		Type:    "hana-sr-decoding-error",
+		Message: "error decoding hana system replication output",
+	}
+)
+
+type HanaSRGatherer struct {
+	executor       utils.CommandExecutor
+	fs             afero.Fs
+	sapSystemPaths sapsystem.DiscoveryPaths
+}
+
+func NewDefaultHanaSRGatherer() *HanaSRGatherer {
+	return NewHanaSRGatherer(utils.Executor{}, afero.NewOsFs(), sapsystem.NewDefaultDiscoveryPaths())
+}
+
+// NewHanaSRGatherer returns a gatherer running the HANA commands of the instance folder,
+// found in the same SAP installation roots and overrides as the discovery
+func NewHanaSRGatherer(
+	executor utils.CommandExecutor,
+	fs afero.Fs,
+	sapSystemPaths sapsystem.DiscoveryPaths,
+) *HanaSRGatherer {
+	return &HanaSRGatherer{
+		executor:       executor,
+		fs:             fs,
+		sapSystemPaths: sapSystemPaths,
+	}
+}
+
+// Gather returns the HANA system replication state of the requested instance.
+// The argument format is <SID>.<instance number>, followed optionally by the dot access
+// path of the requested value, like PRD.00.sites.Site2.replication_status
+func (g *HanaSRGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", HanaSRGathererName)
+
+	states := make(map[string]*entities.FactValueMap)
+	stateErrors := make(map[string]*entities.FactGatheringError)
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		match := hanaSRArgumentCompiled.FindStringSubmatch(factReq.Argument)
+		if match == nil {
+			gatheringError := HanaSRInvalidArgumentError.Wrap(factReq.Argument)
+			log.Error(gatheringError)
+			facts = append(facts, entities.NewFactGatheredWithError(factReq, gatheringError))
+			continue
+		}
+
+		sid, instanceNumber, valuePath := match[1], match[2], match[3]
+		instanceKey := fmt.Sprintf("%s.%s", sid, instanceNumber)
+
+		state, found := states[instanceKey]
+		gatheringError := stateErrors[instanceKey]
+		if !found && gatheringError == nil {
+			state, gatheringError = g.getSystemReplicationState(sid, instanceNumber)
+			states[instanceKey] = state
+			stateErrors[instanceKey] = gatheringError
+		}
+
+		switch {
+		case gatheringError != nil:
+			log.Error(gatheringError)
+			fact = entities.NewFactGatheredWithError(factReq, gatheringError)
+		case valuePath == "":
+			fact = entities.NewFactGatheredWithRequest(factReq, state)
+		default:
+			if value, err := state.GetValue(valuePath); err == nil {
+				fact = entities.NewFactGatheredWithRequest(factReq, value)
+			} else {
+				log.Error(err)
+				fact = entities.NewFactGatheredWithError(factReq, err)
+			}
+		}
+
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", HanaSRGathererName)
+	return facts, nil
+}
+
+func (g *HanaSRGatherer) getSystemReplicationState(
+	sid, instanceNumber string,
+) (*entities.FactValueMap, *entities.FactGatheringError) {
+	user := fmt.Sprintf("%sadm", strings.ToLower(sid))
+	systemPaths, err := sapsystem.FindSystemPaths(g.fs, g.sapSystemPaths, sid)
+	if err != nil {
+		return nil, HanaSRSystemNotFoundError.Wrap(err.Error())
+	}
+	instancePath := fmt.Sprintf("%s/HDB%s", systemPaths.System, instanceNumber)
+
+	// systemReplicationStatus.py uses the return code to tell the replication status,
+	// so the output is used even if the command returns an error
+	statusCmd := fmt.Sprintf("python %s/exe/python_support/systemReplicationStatus.py --sapcontrol=1", instancePath)
+	statusOutput, err := g.executor.Exec("su", "-lc", statusCmd, user)
+	if err != nil && len(statusOutput) == 0 {
+		return nil, HanaSRCommandError.Wrap(err.Error())
+	}
+
+	srStateCmd := fmt.Sprintf("%s/exe/hdbnsutil -sr_state -sapcontrol=1", instancePath)
+	srStateOutput, err := g.executor.Exec("su", "-lc", srStateCmd, user)
+	if err != nil && len(srStateOutput) == 0 {
+		return nil, HanaSRCommandError.Wrap(err.Error())
+	}
+
+	status, err := parseSapcontrolOutput(string(statusOutput))
+	if err != nil {
+		return nil, HanaSRDecodingError.Wrap(fmt.Sprintf("systemReplicationStatus.py: %s", err))
+	}
+
+	srState, err := parseSapcontrolOutput(string(srStateOutput))
+	if err != nil {
+		return nil, HanaSRDecodingError.Wrap(fmt.Sprintf("hdbnsutil: %s", err))
+	}
+
+	return buildHanaSRState(status, srState), nil
+}
+
+type sapcontrolEntry struct {
+	key   string
+	value string
+}
+
+// parseSapcontrolOutput returns the key=value entries found between the
+// sapcontrol begin and end markers, keeping the original order
+func parseSapcontrolOutput(output string) ([]sapcontrolEntry, error) {
+	entries := []sapcontrolEntry{}
+	inside := false
+	found := false
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == sapcontrolOutputBegin:
+			inside = true
+			found = true
+		case trimmed == sapcontrolOutputEnd:
+			inside = false
+		case inside && trimmed != "":
+			parts := strings.SplitN(trimmed, "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid entry: %s", trimmed)
+			}
+			entries = append(entries, sapcontrolEntry{key: parts[0], value: parts[1]})
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("sapcontrol output markers not found")
+	}
+
+	return entries, nil
+}
+
+// buildHanaSRState combines the systemReplicationStatus.py and hdbnsutil -sr_state outputs.
+// The sites are indexed by name, and the replication services are added to the
+// secondary site they replicate to
+func buildHanaSRState(status []sapcontrolEntry, srState []sapcontrolEntry) *entities.FactValueMap {
+	state := make(map[string]entities.FactValue)
+	sites := make(map[string]map[string]entities.FactValue)
+	siteNames := make(map[string]string)
+	services := []map[string]entities.FactValue{}
+	servicesByPath := make(map[string]map[string]entities.FactValue)
+	mappings := make(map[string]entities.FactValue)
+
+	getSite := func(name string) map[string]entities.FactValue {
+		site, found := sites[name]
+		if !found {
+			site = map[string]entities.FactValue{
+				"name":            &entities.FactValueString{Value: name},
+				hanaSRServicesKey: &entities.FactValueList{Value: []entities.FactValue{}},
+			}
+			sites[name] = site
+		}
+		return site
+	}
+
+	siteEntries := make(map[string]map[string]string)
+	siteIDs := []string{}
+	for _, entry := range status {
+		switch {
+		case strings.HasPrefix(entry.key, hanaSRServicePrefix):
+			// service/<host>/<port>/<KEY>
+			parts := strings.SplitN(strings.TrimPrefix(entry.key, hanaSRServicePrefix), "/", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			servicePath := fmt.Sprintf("%s/%s", parts[0], parts[1])
+			service, found := servicesByPath[servicePath]
+			if !found {
+				service = map[string]entities.FactValue{
+					"host": &entities.FactValueString{Value: parts[0]},
+				}
+				servicesByPath[servicePath] = service
+				services = append(services, service)
+			}
+			service[hanaKeyToSnakeCase(parts[2])] = hanaSRValueToFactValue(entry.value)
+		case strings.HasPrefix(entry.key, hanaSRSitePrefix):
+			// site/<id>/<KEY>
+			parts := strings.SplitN(strings.TrimPrefix(entry.key, hanaSRSitePrefix), "/", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if _, found := siteEntries[parts[0]]; !found {
+				siteEntries[parts[0]] = make(map[string]string)
+				siteIDs = append(siteIDs, parts[0])
+			}
+			siteEntries[parts[0]][parts[1]] = entry.value
+		default:
+			state[hanaKeyToSnakeCase(entry.key)] = hanaSRValueToFactValue(entry.value)
+		}
+	}
+
+	for _, siteID := range siteIDs {
+		entries := siteEntries[siteID]
+		name, found := entries["SITE_NAME"]
+		if !found {
+			name = siteID
+		}
+		siteNames[siteID] = name
+		site := getSite(name)
+		site["id"] = hanaSRValueToFactValue(siteID)
+		for key, value := range entries {
+			if key == "SITE_NAME" {
+				continue
+			}
+			site[hanaKeyToSnakeCase(key)] = hanaSRValueToFactValue(value)
+		}
+	}
+
+	for _, entry := range srState {
+		if match := hanaSRSiteAttributeCompiled.FindStringSubmatch(entry.key); match != nil {
+			// the systemReplicationStatus.py values have precedence, as they are upper case
+			site := getSite(match[2])
+			key := hanaKeyToSnakeCase(match[1])
+			if _, found := site[key]; !found {
+				site[key] = hanaSRValueToFactValue(entry.value)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(entry.key, hanaSRMappingPrefix) {
+			host := strings.TrimPrefix(entry.key, hanaSRMappingPrefix)
+			hostMappings, found := mappings[host].(*entities.FactValueList)
+			if !found {
+				hostMappings = &entities.FactValueList{Value: []entities.FactValue{}}
+				mappings[host] = hostMappings
+			}
+			hostMappings.AppendValue(&entities.FactValueString{Value: entry.value})
+			continue
+		}
+
+		state[hanaKeyToSnakeCase(entry.key)] = hanaSRValueToFactValue(entry.value)
+	}
+
+	for _, service := range services {
+		addServiceShippingDelay(service)
+
+		siteName := ""
+		if secondary, ok := service["secondary_site_name"].(*entities.FactValueString); ok {
+			siteName = secondary.Value
+		} else if siteID, ok := service["secondary_site_id"]; ok {
+			siteName = siteNames[fmt.Sprint(siteID.AsInterface())]
+		}
+
+		if siteName == "" {
+			continue
+		}
+
+		siteServices, _ := getSite(siteName)[hanaSRServicesKey].(*entities.FactValueList)
+		siteServices.AppendValue(&entities.FactValueMap{Value: service})
+	}
+
+	sitesMap := make(map[string]entities.FactValue)
+	for name, site := range sites {
+		sitesMap[name] = &entities.FactValueMap{Value: site}
+	}
+
+	state[hanaSRSitesKey] = &entities.FactValueMap{Value: sitesMap}
+	state["mappings"] = &entities.FactValueMap{Value: mappings}
+
+	return &entities.FactValueMap{Value: state}
+}
+
+// addServiceShippingDelay adds the log shipping delay of the service, in seconds and
+// in log positions, as it is shown by the systemReplicationStatus.py human readable output
+func addServiceShippingDelay(service map[string]entities.FactValue) {
+	lastTime, lastFound := service["last_log_position_time"].(*entities.FactValueString)
+	shippedTime, shippedFound := service["shipped_log_position_time"].(*entities.FactValueString)
+	if lastFound && shippedFound {
+		last, lastErr := time.Parse(hanaSRTimestampLayout, lastTime.Value)
+		shipped, shippedErr := time.Parse(hanaSRTimestampLayout, shippedTime.Value)
+		if lastErr == nil && shippedErr == nil {
+			service["shipping_delay"] = &entities.FactValueFloat{Value: last.Sub(shipped).Seconds()}
+		}
+	}
+
+	lastPosition, lastFound := service["last_log_position"].(*entities.FactValueInt)
+	shippedPosition, shippedFound := service["shipped_log_position"].(*entities.FactValueInt)
+	if lastFound && shippedFound {
+		service["log_position_delay"] = &entities.FactValueInt{Value: lastPosition.Value - shippedPosition.Value}
+	}
+}
+
+// hanaSRValueToFactValue converts the HANA values to the best matching type.
+// Boolean values are only used by hdbnsutil, which uses true and false
+func hanaSRValueToFactValue(value string) entities.FactValue {
+	if value == "true" || value == "false" {
+		return &entities.FactValueBool{Value: value == "true"}
+	}
+	return entities.ParseStringToFactValue(value)
+}
+
+// hanaKeyToSnakeCase converts the HANA output keys, which use upper case (REPLICATION_MODE),
+// camel case (isSource) or spaces (operation mode), to snake case
+func hanaKeyToSnakeCase(key string) string {
+	var builder strings.Builder
+	previousLower := false
+
+	for _, char := range key {
+		switch {
+		case char == ' ' || char == '-':
+			builder.WriteRune('_')
+			previousLower = false
+		case unicode.IsUpper(char):
+			if previousLower {
+				builder.WriteRune('_')
+			}
+			builder.WriteRune(unicode.ToLower(char))
+			previousLower = false
+		default:
+			builder.WriteRune(char)
+			previousLower = unicode.IsLower(char) || unicode.IsDigit(char)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,407 @@
+package gatherers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/core/sapsystem"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+)
+
+const (
+	hanaSRStatusCmd = "python /usr/sap/PRD/HDB00/exe/python_support/systemReplicationStatus.py --sapcontrol=1"
+	hanaSRStateCmd  = "/usr/sap/PRD/HDB00/exe/hdbnsutil -sr_state -sapcontrol=1"
+)
+
+type HanaSRTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+	fs           afero.Fs
+}
+
+func TestHanaSRTestSuite(t *testing.T) {
+	suite.Run(t, new(HanaSRTestSuite))
+}
+
+func (suite *HanaSRTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+	suite.fs = afero.NewMemMapFs()
+	_ = suite.fs.MkdirAll("/usr/sap/PRD/HDB00", 0755)
+	_ = suite.fs.MkdirAll("/usr/sap/QAS/HDB10", 0755)
+}
+
+func (suite *HanaSRTestSuite) TestHanaSRGather() {
+	// systemReplicationStatus.py returns 11 when the replication status is ERROR
+	suite.mockExecutor.On("Exec", "su", "-lc", hanaSRStatusCmd, "prdadm").Return(
+		readFixture("gatherers/hana-sr-status.output"), errors.New("exit status 11")).Once()
+	suite.mockExecutor.On("Exec", "su", "-lc", hanaSRStateCmd, "prdadm").Return(
+		readFixture("gatherers/hana-sr-state.output"), nil).Once()
+
+	c := gatherers.NewHanaSRGatherer(suite.mockExecutor, suite.fs, sapsystem.NewDefaultDiscoveryPaths())
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "overall_status",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.overall_replication_status",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "takeover_active",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.is_takeover_active",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "primary_site",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site1",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "secondary_replication_mode",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site2.replication_mode",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "secondary_operation_mode",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site2.operation_mode",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "nameserver_service",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site2.services.0",
+			CheckID:  "check3",
+		},
+		{
+			Name:     "xsengine_secondary_active",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site2.services.1.secondary_active_status",
+			CheckID:  "check3",
+		},
+		{
+			Name:     "mappings",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.mappings.hana01",
+			CheckID:  "check4",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "overall_status",
+			Value:   &entities.FactValueString{Value: "ERROR"},
+			CheckID: "check1",
+		},
+		{
+			Name:    "takeover_active",
+			Value:   &entities.FactValueBool{Value: false},
+			CheckID: "check1",
+		},
+		{
+			Name: "primary_site",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"id":               &entities.FactValueInt{Value: 1},
+				"name":             &entities.FactValueString{Value: "Site1"},
+				"replication_mode": &entities.FactValueString{Value: "PRIMARY"},
+				"operation_mode":   &entities.FactValueString{Value: "primary"},
+				"tier":             &entities.FactValueInt{Value: 1},
+				"mapping":          &entities.FactValueString{Value: "Site2"},
+				"services":         &entities.FactValueList{Value: []entities.FactValue{}},
+			}},
+			CheckID: "check2",
+		},
+		{
+			Name:    "secondary_replication_mode",
+			Value:   &entities.FactValueString{Value: "SYNC"},
+			CheckID: "check2",
+		},
+		{
+			Name:    "secondary_operation_mode",
+			Value:   &entities.FactValueString{Value: "logreplay"},
+			CheckID: "check2",
+		},
+		{
+			Name: "nameserver_service",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"host":                       &entities.FactValueString{Value: "hana01"},
+				"port":                       &entities.FactValueInt{Value: 30001},
+				"service_name":               &entities.FactValueString{Value: "nameserver"},
+				"volume_id":                  &entities.FactValueInt{Value: 1},
+				"site_id":                    &entities.FactValueInt{Value: 1},
+				"site_name":                  &entities.FactValueString{Value: "Site1"},
+				"secondary_site_id":          &entities.FactValueInt{Value: 2},
+				"secondary_site_name":        &entities.FactValueString{Value: "Site2"},
+				"secondary_host":             &entities.FactValueString{Value: "hana02"},
+				"secondary_port":             &entities.FactValueInt{Value: 30001},
+				"secondary_active_status":    &entities.FactValueString{Value: "YES"},
+				"replication_mode":           &entities.FactValueString{Value: "SYNC"},
+				"operation_mode":             &entities.FactValueString{Value: "logreplay"},
+				"replication_status":         &entities.FactValueString{Value: "ACTIVE"},
+				"replication_status_details": &entities.FactValueString{Value: ""},
+				"last_log_position":          &entities.FactValueInt{Value: 37624000},
+				"last_log_position_time":     &entities.FactValueString{Value: "2022-10-20 12:43:13.559197"},
+				"shipped_log_position":       &entities.FactValueInt{Value: 37623500},
+				"shipped_log_position_time":  &entities.FactValueString{Value: "2022-10-20 12:43:10.059197"},
+				"shipping_delay":             &entities.FactValueFloat{Value: 3.5},
+				"log_position_delay":         &entities.FactValueInt{Value: 500},
+			}},
+			CheckID: "check3",
+		},
+		{
+			Name:    "xsengine_secondary_active",
+			Value:   &entities.FactValueString{Value: "NO"},
+			CheckID: "check3",
+		},
+		{
+			Name: "mappings",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "Site2/hana02"},
+				&entities.FactValueString{Value: "Site1/hana01"},
+			}},
+			CheckID: "check4",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *HanaSRTestSuite) TestHanaSRGatherWithoutSecondarySiteName() {
+	suite.mockExecutor.On("Exec", "su", "-lc", hanaSRStatusCmd, "prdadm").Return(
+		readFixture("gatherers/hana-sr-status-no-site-name.output"), errors.New("exit status 11"))
+	suite.mockExecutor.On("Exec", "su", "-lc", hanaSRStateCmd, "prdadm").Return(
+		readFixture("gatherers/hana-sr-state.output"), nil)
+
+	c := gatherers.NewHanaSRGatherer(suite.mockExecutor, suite.fs, sapsystem.NewDefaultDiscoveryPaths())
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "primary_services",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site1.services",
+		},
+		{
+			Name:     "secondary_nameserver",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site2.services.0.service_name",
+		},
+		{
+			Name:     "secondary_xsengine",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site2.services.1.service_name",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "primary_services",
+			Value: &entities.FactValueList{Value: []entities.FactValue{}},
+		},
+		{
+			Name:  "secondary_nameserver",
+			Value: &entities.FactValueString{Value: "nameserver"},
+		},
+		{
+			Name:  "secondary_xsengine",
+			Value: &entities.FactValueString{Value: "xsengine"},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *HanaSRTestSuite) TestHanaSRGatherLocalState() {
+	suite.mockExecutor.On("Exec", "su", "-lc", hanaSRStatusCmd, "prdadm").Return(
+		readFixture("gatherers/hana-sr-status.output"), errors.New("exit status 11"))
+	suite.mockExecutor.On("Exec", "su", "-lc", hanaSRStateCmd, "prdadm").Return(
+		readFixture("gatherers/hana-sr-state.output"), nil)
+
+	c := gatherers.NewHanaSRGatherer(suite.mockExecutor, suite.fs, sapsystem.NewDefaultDiscoveryPaths())
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "mode",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.mode",
+		},
+		{
+			Name:     "site_name",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.site_name",
+		},
+		{
+			Name:     "local_site_id",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.local_site_id",
+		},
+		{
+			Name:     "online",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.online",
+		},
+		{
+			Name:     "not_found",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.sites.Site3",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "mode",
+			Value: &entities.FactValueString{Value: "primary"},
+		},
+		{
+			Name:  "site_name",
+			Value: &entities.FactValueString{Value: "Site1"},
+		},
+		{
+			Name:  "local_site_id",
+			Value: &entities.FactValueInt{Value: 1},
+		},
+		{
+			Name:  "online",
+			Value: &entities.FactValueBool{Value: true},
+		},
+		{
+			Name:  "not_found",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error getting value: requested field value not found: sites.Site3",
+				Type:    "value-not-found",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockExecutor.AssertNumberOfCalls(suite.T(), "Exec", 2)
+}
+
+func (suite *HanaSRTestSuite) TestHanaSRGatherErrors() {
+	suite.mockExecutor.On("Exec", "su", "-lc", hanaSRStatusCmd, "prdadm").Return(
+		[]byte{}, errors.New("user prdadm does not exist"))
+	suite.mockExecutor.On(
+		"Exec", "su", "-lc",
+		"python /usr/sap/QAS/HDB10/exe/python_support/systemReplicationStatus.py --sapcontrol=1", "qasadm").Return(
+		[]byte("Traceback (most recent call last):\n"), errors.New("exit status 1"))
+	suite.mockExecutor.On(
+		"Exec", "su", "-lc", "/usr/sap/QAS/HDB10/exe/hdbnsutil -sr_state -sapcontrol=1", "qasadm").Return(
+		readFixture("gatherers/hana-sr-state.output"), nil)
+
+	c := gatherers.NewHanaSRGatherer(suite.mockExecutor, suite.fs, sapsystem.NewDefaultDiscoveryPaths())
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "invalid",
+			Gatherer: "hana_sr",
+			Argument: "prd.0",
+		},
+		{
+			Name:     "command_error",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00",
+		},
+		{
+			Name:     "decoding_error",
+			Gatherer: "hana_sr",
+			Argument: "QAS.10.overall_replication_status",
+		},
+		{
+			Name:     "not_found",
+			Gatherer: "hana_sr",
+			Argument: "DEV.00",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "invalid",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "invalid argument, the format is <SID>.<instance number>: prd.0",
+				Type:    "hana-sr-invalid-argument",
+			},
+		},
+		{
+			Name:  "command_error",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error executing hana system replication command: user prdadm does not exist",
+				Type:    "hana-sr-command-error",
+			},
+		},
+		{
+			Name:  "decoding_error",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error decoding hana system replication output: " +
+					"systemReplicationStatus.py: sapcontrol output markers not found",
+				Type: "hana-sr-decoding-error",
+			},
+		},
+		{
+			Name:  "not_found",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "hana system not found: SAP system DEV not found",
+				Type:    "hana-sr-system-not-found",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *HanaSRTestSuite) TestHanaSRGatherOverriddenSystemPath() {
+	_ = suite.fs.MkdirAll("/data/PRD_inst/HDB00", 0755)
+	suite.mockExecutor.On(
+		"Exec", "su", "-lc",
+		"python /data/PRD_inst/HDB00/exe/python_support/systemReplicationStatus.py --sapcontrol=1", "prdadm").Return(
+		readFixture("gatherers/hana-sr-status.output"), errors.New("exit status 11"))
+	suite.mockExecutor.On(
+		"Exec", "su", "-lc", "/data/PRD_inst/HDB00/exe/hdbnsutil -sr_state -sapcontrol=1", "prdadm").Return(
+		readFixture("gatherers/hana-sr-state.output"), nil)
+
+	c := gatherers.NewHanaSRGatherer(suite.mockExecutor, suite.fs, sapsystem.DiscoveryPaths{
+		Roots: []string{"/usr/sap"},
+		Overrides: map[string]sapsystem.SystemPaths{
+			"PRD": {System: "/data/PRD_inst"},
+		},
+	})
+
+	factResults, err := c.Gather([]entities.FactRequest{
+		{
+			Name:     "mode",
+			Gatherer: "hana_sr",
+			Argument: "PRD.00.mode",
+		},
+	})
+
+	suite.NoError(err)
+	suite.Equal([]entities.Fact{
+		{
+			Name:  "mode",
+			Value: &entities.FactValueString{Value: "primary"},
+		},
+	}, factResults)
+	suite.mockExecutor.AssertExpectations(suite.T())
+}
@@ -2,25 +2,43 @@ package gatherers
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"regexp"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
 )
 
 const (
 	HostsFileGathererName = "hosts"
 	HostsFilePath         = "/etc/hosts"
+	NSSwitchFilePath      = "/etc/nsswitch.conf"
 	ipMatchGroup          = "ip"
 	hostnamesMatchGroup   = "hostnames"
 	hostsParsingRegexp    = `(?m)(?P<` + ipMatchGroup + `>\S+)\s+(?P<` + hostnamesMatchGroup + `>.+)`
+
+	hostsReversePrefix = "reverse:"
+	hostsResolvePrefix = "resolve:"
+	nssSourceFiles     = "files"
+	nssStatusSuccess   = "success"
+	nssStatusNotFound  = "notfound"
+	nssStatusUnavail   = "unavail"
+	nssActionReturn    = "return"
+	nssActionContinue  = "continue"
+	// nssDefaultHostsSources is used when nsswitch.conf doesn't have a hosts database entry
+	nssDefaultHostsSources = "files dns"
+	// getent exits with this code when the key is not found
+	getentNotFoundExitCode = 2
 )
 
 var (
 	hostsEntryCompiled = regexp.MustCompile(hostsParsingRegexp)
+	nssActionsCompiled = regexp.MustCompile(`^\[(.+)\]$`)
 )
 
 // nolint:gochecknoglobals
@@ -39,20 +57,41 @@ var (
 		Type:    "hosts-file-value-not-found",
 		Message: "requested field value not found in /etc/hosts file",
 	}
+
+	HostsFileNSSwitchError = entities.FactGatheringError{
+		Type:    "hosts-file-nsswitch-error",
+		Message: "error reading the hosts database sources from nsswitch.conf",
+	}
 )
 
 type HostsFileGatherer struct {
-	hostsFilePath string
+	hostsFilePath    string
+	nsswitchFilePath string
+	executor         utils.CommandExecutor
+}
+
+// nssSource is a source of the nsswitch.conf hosts database, with the action to take for each lookup status
+type nssSource struct {
+	name    string
+	actions map[string]string
 }
 
 func NewDefaultHostsFileGatherer() *HostsFileGatherer {
-	return NewHostsFileGatherer(HostsFilePath)
+	return NewHostsFileGatherer(HostsFilePath, NSSwitchFilePath, utils.Executor{})
 }
 
-func NewHostsFileGatherer(hostsFile string) *HostsFileGatherer {
-	return &HostsFileGatherer{hostsFilePath: hostsFile}
+func NewHostsFileGatherer(hostsFile, nsswitchFile string, executor utils.CommandExecutor) *HostsFileGatherer {
+	return &HostsFileGatherer{
+		hostsFilePath:    hostsFile,
+		nsswitchFilePath: nsswitchFile,
+		executor:         executor,
+	}
 }
 
+// Gather returns the addresses of the host names in the hosts file. Besides the plain host name argument,
+// the reverse:<ip> argument returns the host names of an address, and resolve:<host name or ip> resolves
+// the host name, or the ip to its host names, following the sources order of the hosts database in
+// nsswitch.conf. The files source is looked up in the hosts file, and the rest of the sources with getent
 func (s *HostsFileGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
 	facts := []entities.Fact{}
 	log.Infof("Starting /etc/hosts file facts gathering process")
@@ -67,8 +106,35 @@ func (s *HostsFileGatherer) Gather(factsRequests []entities.FactRequest) ([]enti
 		return nil, HostsFileDecodingError.Wrap(err.Error())
 	}
 
+	var nssSources []nssSource
+	var nssSourcesErr error
+
 	for _, factReq := range factsRequests {
 		var fact entities.Fact
+
+		if strings.HasPrefix(factReq.Argument, hostsReversePrefix) {
+			fact = reverseHostsFileFact(factReq, hostsFile)
+			facts = append(facts, fact)
+			continue
+		}
+
+		if strings.HasPrefix(factReq.Argument, hostsResolvePrefix) {
+			if nssSources == nil && nssSourcesErr == nil {
+				nssSources, nssSourcesErr = readNSSwitchHostsSources(s.nsswitchFilePath)
+			}
+
+			if nssSourcesErr != nil {
+				gatheringError := HostsFileNSSwitchError.Wrap(nssSourcesErr.Error())
+				log.Error(gatheringError)
+				fact = entities.NewFactGatheredWithError(factReq, gatheringError)
+			} else {
+				key := strings.TrimPrefix(factReq.Argument, hostsResolvePrefix)
+				fact = entities.NewFactGatheredWithRequest(factReq, s.resolve(key, hostsFile, nssSources))
+			}
+			facts = append(facts, fact)
+			continue
+		}
+
 		if factReq.Argument == "" {
 			fact = entities.NewFactGatheredWithRequest(factReq, hostsFileMap)
 			facts = append(facts, fact)
@@ -152,3 +218,281 @@ func hostsFileToMap(lines []string) (*entities.FactValueMap, error) {
 
 	return &entities.FactValueMap{Value: hostsFileMap}, nil
 }
+
+// hostsFileToReverseMap maps the ips to their host names, in the hosts file order
+func hostsFileToReverseMap(lines []string) map[string]*entities.FactValueList {
+	reverseMap := make(map[string]*entities.FactValueList)
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		ip := fields[0]
+		if _, found := reverseMap[ip]; !found {
+			reverseMap[ip] = &entities.FactValueList{Value: []entities.FactValue{}}
+		}
+		for _, hostname := range fields[1:] {
+			reverseMap[ip].AppendValue(&entities.FactValueString{Value: hostname})
+		}
+	}
+
+	return reverseMap
+}
+
+func reverseHostsFileFact(factReq entities.FactRequest, lines []string) entities.Fact {
+	reverseMap := hostsFileToReverseMap(lines)
+	ip := strings.TrimPrefix(factReq.Argument, hostsReversePrefix)
+
+	if ip == "" {
+		reverseFactMap := &entities.FactValueMap{Value: make(map[string]entities.FactValue)}
+		for ip, hostnames := range reverseMap {
+			reverseFactMap.Value[ip] = hostnames
+		}
+		return entities.NewFactGatheredWithRequest(factReq, reverseFactMap)
+	}
+
+	if hostnames, found := reverseMap[ip]; found {
+		return entities.NewFactGatheredWithRequest(factReq, hostnames)
+	}
+
+	gatheringError := HostsFileEntryNotFoundError.Wrap(factReq.Argument)
+	log.Error(gatheringError)
+	return entities.NewFactGatheredWithError(factReq, gatheringError)
+}
+
+// resolve looks up the host name, or the ip, in the nsswitch.conf sources until one of them
+// resolves it, or the action for the lookup status is to return. The sources that can't be
+// queried are reported in unavailable_sources
+func (s *HostsFileGatherer) resolve(key string, hostsFile []string, sources []nssSource) *entities.FactValueMap {
+	resolution := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"resolved":            &entities.FactValueBool{Value: false},
+		"source":              &entities.FactValueString{Value: ""},
+		"addresses":           &entities.FactValueList{Value: []entities.FactValue{}},
+		"hostnames":           &entities.FactValueList{Value: []entities.FactValue{}},
+		"unavailable_sources": &entities.FactValueList{Value: []entities.FactValue{}},
+	}}
+
+	// the ips are resolved to their host names, and the host names to their addresses
+	resultKey := "addresses"
+	if net.ParseIP(key) != nil {
+		resultKey = "hostnames"
+	}
+
+	for _, source := range sources {
+		var results []string
+		var err error
+
+		if source.name == nssSourceFiles {
+			results = lookupHostsFile(hostsFile, key)
+		} else {
+			results, err = s.lookupNSSSource(source.name, key)
+		}
+
+		status := nssStatusSuccess
+		switch {
+		case err != nil:
+			log.Warnf("Error resolving %s with the %s nsswitch source: %s", key, source.name, err)
+			resolution.Value["unavailable_sources"].(*entities.FactValueList).AppendValue(
+				&entities.FactValueString{Value: source.name})
+			status = nssStatusUnavail
+		case len(results) == 0:
+			status = nssStatusNotFound
+		default:
+			resolution.Value["resolved"] = &entities.FactValueBool{Value: true}
+			resolution.Value["source"] = &entities.FactValueString{Value: source.name}
+			resolution.Value[resultKey] = stringsToFactValueList(results)
+		}
+
+		if source.action(status) == nssActionReturn {
+			break
+		}
+	}
+
+	return resolution
+}
+
+// lookupHostsFile returns the addresses of a host name, or the host names of an ip, in the hosts file.
+// The host names are matched case insensitively, as the files nsswitch source does
+func lookupHostsFile(hostsFile []string, key string) []string {
+	results := []string{}
+	ip := net.ParseIP(key)
+
+	for _, line := range hostsFile {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		if ip != nil {
+			if ip.Equal(net.ParseIP(fields[0])) {
+				results = appendUnique(results, fields[1:]...)
+			}
+			continue
+		}
+
+		for _, hostname := range fields[1:] {
+			if strings.EqualFold(hostname, key) {
+				results = appendUnique(results, fields[0])
+			}
+		}
+	}
+
+	return results
+}
+
+// lookupNSSSource resolves the host name, or the ip, with a single nsswitch source using getent.
+// The host names are resolved with ahosts, like getaddrinfo does, and the ips with hosts.
+// getent doesn't tell the temporary failures apart, so a failed lookup is reported as not found
+func (s *HostsFileGatherer) lookupNSSSource(source, key string) ([]string, error) {
+	database := "ahosts"
+	if net.ParseIP(key) != nil {
+		database = "hosts"
+	}
+
+	output, err := s.executor.Exec("getent", "-s", "hosts:"+source, database, key)
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == getentNotFoundExitCode {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	results := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// ahosts prints one line per address and socket type: 192.168.1.12 STREAM node02
+		// hosts prints the address followed by the host names: 192.168.1.12 node02 node02.example.com
+		if database == "ahosts" {
+			results = appendUnique(results, fields[0])
+		} else {
+			results = appendUnique(results, fields[1:]...)
+		}
+	}
+
+	return results, nil
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+func (s nssSource) action(status string) string {
+	if action, found := s.actions[status]; found {
+		return action
+	}
+	if status == nssStatusSuccess {
+		return nssActionReturn
+	}
+	return nssActionContinue
+}
+
+// readNSSwitchHostsSources reads the sources of the hosts database, like:
+// hosts: files mdns_minimal [NOTFOUND=return] dns
+func readNSSwitchHostsSources(filePath string) ([]nssSource, error) {
+	hostsSources := nssDefaultHostsSources
+
+	content, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		database, sources, found := strings.Cut(line, ":")
+		if found && strings.TrimSpace(database) == "hosts" {
+			hostsSources = sources
+		}
+	}
+
+	return parseNSSSources(hostsSources)
+}
+
+func parseNSSSources(line string) ([]nssSource, error) {
+	sources := []nssSource{}
+
+	fields, err := tokenizeNSSSources(line)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		match := nssActionsCompiled.FindStringSubmatch(field)
+		if match == nil {
+			sources = append(sources, nssSource{name: field, actions: make(map[string]string)})
+			continue
+		}
+
+		if len(sources) == 0 {
+			return nil, fmt.Errorf("action %s without a preceding source", field)
+		}
+		source := sources[len(sources)-1]
+
+		for _, criterion := range strings.Fields(match[1]) {
+			status, action, found := strings.Cut(strings.ToLower(criterion), "=")
+			if !found {
+				return nil, fmt.Errorf("invalid action %s", criterion)
+			}
+
+			if !strings.HasPrefix(status, "!") {
+				source.actions[status] = action
+				continue
+			}
+
+			negated := strings.TrimPrefix(status, "!")
+			for _, otherStatus := range []string{nssStatusSuccess, nssStatusNotFound, nssStatusUnavail} {
+				if otherStatus != negated {
+					source.actions[otherStatus] = action
+				}
+			}
+		}
+	}
+
+	return sources, nil
+}
+
+// tokenizeNSSSources splits the sources line in sources and action groups, keeping each group,
+// like [NOTFOUND=return TRYAGAIN=continue], as a single token
+func tokenizeNSSSources(line string) ([]string, error) {
+	tokens := []string{}
+
+	for remaining := strings.TrimSpace(line); remaining != ""; remaining = strings.TrimSpace(remaining) {
+		if !strings.HasPrefix(remaining, "[") {
+			end := strings.IndexAny(remaining, " \t[")
+			if end == -1 {
+				end = len(remaining)
+			}
+			tokens = append(tokens, remaining[:end])
+			remaining = remaining[end:]
+			continue
+		}
+
+		end := strings.Index(remaining, "]")
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed action %s", remaining)
+		}
+		tokens = append(tokens, remaining[:end+1])
+		remaining = remaining[end+1:]
+	}
+
+	return tokens, nil
+}
@@ -1,24 +1,51 @@
 package gatherers_test
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/agent/internal/factsengine/gatherers"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
 	"github.com/trento-project/agent/test/helpers"
 )
 
 type HostsFileTestSuite struct {
 	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+}
+
+// getentExitError is the error of a getent command exiting with a non zero code
+type getentExitError int
+
+func (e getentExitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+func (e getentExitError) ExitCode() int {
+	return int(e)
 }
 
 func TestHostsFileTestSuite(t *testing.T) {
 	suite.Run(t, new(HostsFileTestSuite))
 }
 
+func (suite *HostsFileTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+}
+
+func (suite *HostsFileTestSuite) mockGetent(source, database, key string, output string, err error) {
+	suite.mockExecutor.On("Exec", "getent", "-s", "hosts:"+source, database, key).Return([]byte(output), err)
+}
+
 func (suite *HostsFileTestSuite) TestHostsFileBasic() {
-	c := gatherers.NewHostsFileGatherer(helpers.GetFixturePath("gatherers/hosts.basic"))
+	c := gatherers.NewHostsFileGatherer(
+		helpers.GetFixturePath("gatherers/hosts.basic"),
+		helpers.GetFixturePath("gatherers/nsswitch.conf"),
+		suite.mockExecutor,
+	)
 
 	factRequests := []entities.FactRequest{
 		{
@@ -108,7 +135,8 @@ func (suite *HostsFileTestSuite) TestHostsFileBasic() {
 }
 
 func (suite *HostsFileTestSuite) TestHostsFileNotExists() {
-	c := gatherers.NewHostsFileGatherer("non_existing_file")
+	c := gatherers.NewHostsFileGatherer(
+		"non_existing_file", helpers.GetFixturePath("gatherers/nsswitch.conf"), suite.mockExecutor)
 
 	factRequests := []entities.FactRequest{
 		{
@@ -126,7 +154,11 @@ func (suite *HostsFileTestSuite) TestHostsFileNotExists() {
 
 func (suite *HostsFileTestSuite) TestHostsFileIgnoresCommentedHosts() {
 
-	c := gatherers.NewHostsFileGatherer(helpers.GetFixturePath("gatherers/hosts.basic"))
+	c := gatherers.NewHostsFileGatherer(
+		helpers.GetFixturePath("gatherers/hosts.basic"),
+		helpers.GetFixturePath("gatherers/nsswitch.conf"),
+		suite.mockExecutor,
+	)
 
 	factRequests := []entities.FactRequest{
 		{
@@ -152,3 +184,375 @@ func (suite *HostsFileTestSuite) TestHostsFileIgnoresCommentedHosts() {
 	suite.NoError(err)
 	suite.ElementsMatch(expectedResults, factResults)
 }
+
+func (suite *HostsFileTestSuite) TestHostsFileReverse() {
+	c := gatherers.NewHostsFileGatherer(
+		helpers.GetFixturePath("gatherers/hosts.basic"),
+		helpers.GetFixturePath("gatherers/nsswitch.conf"),
+		suite.mockExecutor,
+	)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "hosts_reverse_localhost",
+			Gatherer: "hosts",
+			Argument: "reverse:::1",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "hosts_reverse_suse",
+			Gatherer: "hosts",
+			Argument: "reverse:52.84.66.74",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "hosts_reverse_unknown",
+			Gatherer: "hosts",
+			Argument: "reverse:10.0.0.1",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "hosts_reverse_all",
+			Gatherer: "hosts",
+			Argument: "reverse:",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "hosts_reverse_localhost",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "localhost"},
+				&entities.FactValueString{Value: "ip6-localhost"},
+				&entities.FactValueString{Value: "ip6-loopback"},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name: "hosts_reverse_suse",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "suse.com"},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name:  "hosts_reverse_unknown",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "requested field value not found in /etc/hosts file: reverse:10.0.0.1",
+				Type:    "hosts-file-value-not-found",
+			},
+			CheckID: "check1",
+		},
+		{
+			Name: "hosts_reverse_all",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"127.0.0.1": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "localhost"},
+				}},
+				"127.0.1.1": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "somehost"},
+				}},
+				"52.84.66.74": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "suse.com"},
+				}},
+				"::1": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "localhost"},
+					&entities.FactValueString{Value: "ip6-localhost"},
+					&entities.FactValueString{Value: "ip6-loopback"},
+				}},
+				"ff02::1": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "ip6-allnodes"},
+				}},
+				"ff02::2": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "ip6-allrouters"},
+				}},
+			}},
+			CheckID: "check2",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func stringsList(values ...string) *entities.FactValueList {
+	list := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, value := range values {
+		list.AppendValue(&entities.FactValueString{Value: value})
+	}
+	return list
+}
+
+func resolution(resolved bool, source string, addresses ...string) *entities.FactValueMap {
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"resolved":            &entities.FactValueBool{Value: resolved},
+		"source":              &entities.FactValueString{Value: source},
+		"addresses":           stringsList(addresses...),
+		"hostnames":           stringsList(),
+		"unavailable_sources": stringsList(),
+	}}
+}
+
+func (suite *HostsFileTestSuite) TestHostsFileResolve() {
+	c := gatherers.NewHostsFileGatherer(
+		helpers.GetFixturePath("gatherers/hosts.basic"),
+		helpers.GetFixturePath("gatherers/nsswitch.conf"),
+		suite.mockExecutor,
+	)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "resolve_files",
+			Gatherer: "hosts",
+			Argument: "resolve:suse.com",
+		},
+		{
+			Name:     "resolve_dns",
+			Gatherer: "hosts",
+			Argument: "resolve:node02",
+		},
+		{
+			Name:     "resolve_unavailable",
+			Gatherer: "hosts",
+			Argument: "resolve:node03",
+		},
+		{
+			Name:     "resolve_unknown",
+			Gatherer: "hosts",
+			Argument: "resolve:node04",
+		},
+		{
+			Name:     "resolve_case_insensitive",
+			Gatherer: "hosts",
+			Argument: "resolve:SUSE.com",
+		},
+	}
+
+	suite.mockGetent("dns", "ahosts", "node02",
+		"192.168.1.12    STREAM node02\n192.168.1.12    DGRAM  \n192.168.1.12    RAW    \n", nil)
+	suite.mockGetent("dns", "ahosts", "node03", "", errors.New("getent not found"))
+	suite.mockGetent("dns", "ahosts", "node04", "", getentExitError(2))
+
+	factResults, err := c.Gather(factRequests)
+
+	unavailable := resolution(false, "")
+	unavailable.Value["unavailable_sources"] = stringsList("dns")
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "resolve_files",
+			Value: resolution(true, "files", "52.84.66.74"),
+		},
+		{
+			Name:  "resolve_dns",
+			Value: resolution(true, "dns", "192.168.1.12"),
+		},
+		{
+			Name:  "resolve_unavailable",
+			Value: unavailable,
+		},
+		{
+			Name:  "resolve_unknown",
+			Value: resolution(false, ""),
+		},
+		{
+			Name:  "resolve_case_insensitive",
+			Value: resolution(true, "files", "52.84.66.74"),
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *HostsFileTestSuite) TestHostsFileResolveNSSwitchOrder() {
+	c := gatherers.NewHostsFileGatherer(
+		helpers.GetFixturePath("gatherers/hosts.basic"),
+		helpers.GetFixturePath("gatherers/nsswitch.conf.dns_first"),
+		suite.mockExecutor,
+	)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "resolve_dns_first",
+			Gatherer: "hosts",
+			Argument: "resolve:suse.com",
+		},
+		{
+			Name:     "resolve_not_found_returns",
+			Gatherer: "hosts",
+			Argument: "resolve:somehost",
+		},
+		{
+			Name:     "resolve_unavailable_continues",
+			Gatherer: "hosts",
+			Argument: "resolve:localhost",
+		},
+	}
+
+	suite.mockGetent("dns", "ahosts", "suse.com", "52.84.66.75     STREAM suse.com\n52.84.66.75     DGRAM  \n", nil)
+	suite.mockGetent("dns", "ahosts", "somehost", "", getentExitError(2))
+	suite.mockGetent("dns", "ahosts", "localhost", "", getentExitError(1))
+
+	factResults, err := c.Gather(factRequests)
+
+	unavailableContinues := resolution(true, "files", "127.0.0.1", "::1")
+	unavailableContinues.Value["unavailable_sources"] = stringsList("dns")
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "resolve_dns_first",
+			Value: resolution(true, "dns", "52.84.66.75"),
+		},
+		{
+			Name:  "resolve_not_found_returns",
+			Value: resolution(false, ""),
+		},
+		{
+			Name:  "resolve_unavailable_continues",
+			Value: unavailableContinues,
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *HostsFileTestSuite) TestHostsFileResolveMultipleActions() {
+	c := gatherers.NewHostsFileGatherer(
+		helpers.GetFixturePath("gatherers/hosts.basic"),
+		helpers.GetFixturePath("gatherers/nsswitch.conf.actions"),
+		suite.mockExecutor,
+	)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "resolve_dns",
+			Gatherer: "hosts",
+			Argument: "resolve:node02",
+		},
+		{
+			Name:     "resolve_not_found_returns",
+			Gatherer: "hosts",
+			Argument: "resolve:suse.com",
+		},
+		{
+			Name:     "resolve_unavailable_continues",
+			Gatherer: "hosts",
+			Argument: "resolve:localhost",
+		},
+	}
+
+	suite.mockGetent("dns", "ahosts", "node02", "192.168.1.12    STREAM node02\n", nil)
+	suite.mockGetent("dns", "ahosts", "suse.com", "", getentExitError(2))
+	suite.mockGetent("dns", "ahosts", "localhost", "", getentExitError(1))
+
+	factResults, err := c.Gather(factRequests)
+
+	unavailableContinues := resolution(true, "files", "127.0.0.1", "::1")
+	unavailableContinues.Value["unavailable_sources"] = stringsList("dns")
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "resolve_dns",
+			Value: resolution(true, "dns", "192.168.1.12"),
+		},
+		{
+			Name:  "resolve_not_found_returns",
+			Value: resolution(false, ""),
+		},
+		{
+			Name:  "resolve_unavailable_continues",
+			Value: unavailableContinues,
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *HostsFileTestSuite) TestHostsFileResolveDefaultSources() {
+	c := gatherers.NewHostsFileGatherer(
+		helpers.GetFixturePath("gatherers/hosts.basic"), "non_existing_nsswitch", suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "resolve_files",
+			Gatherer: "hosts",
+			Argument: "resolve:suse.com",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "resolve_files",
+			Value: resolution(true, "files", "52.84.66.74"),
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *HostsFileTestSuite) TestHostsFileResolveOtherSourcesAndIPs() {
+	c := gatherers.NewHostsFileGatherer(
+		helpers.GetFixturePath("gatherers/hosts.basic"),
+		helpers.GetFixturePath("gatherers/nsswitch.conf.other_sources"),
+		suite.mockExecutor,
+	)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "resolve_myhostname",
+			Gatherer: "hosts",
+			Argument: "resolve:node01",
+		},
+		{
+			Name:     "resolve_ip_files",
+			Gatherer: "hosts",
+			Argument: "resolve:0:0:0:0:0:0:0:1",
+		},
+		{
+			Name:     "resolve_ip_dns",
+			Gatherer: "hosts",
+			Argument: "resolve:192.168.1.12",
+		},
+	}
+
+	suite.mockGetent("myhostname", "ahosts", "node01",
+		"192.168.1.11    STREAM node01\nfe80::1         STREAM \n", nil)
+	suite.mockGetent("myhostname", "hosts", "192.168.1.12", "", getentExitError(2))
+	suite.mockGetent("dns", "hosts", "192.168.1.12", "192.168.1.12    node02 node02.example.com\n", nil)
+
+	factResults, err := c.Gather(factRequests)
+
+	resolvedIPFiles := resolution(true, "files")
+	resolvedIPFiles.Value["hostnames"] = stringsList("localhost", "ip6-localhost", "ip6-loopback")
+	resolvedIPDNS := resolution(true, "dns")
+	resolvedIPDNS.Value["hostnames"] = stringsList("node02", "node02.example.com")
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "resolve_myhostname",
+			Value: resolution(true, "myhostname", "192.168.1.11", "fe80::1"),
+		},
+		{
+			Name:  "resolve_ip_files",
+			Value: resolvedIPFiles,
+		},
+		{
+			Name:  "resolve_ip_dns",
+			Value: resolvedIPDNS,
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockExecutor.AssertExpectations(suite.T())
+}
@@ -0,0 +1,82 @@
+package gatherers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	MountInfoPath = "/proc/self/mountinfo"
+)
+
+type mountInfoEntry struct {
+	ID           string
+	ParentID     string
+	MajorMinor   string
+	Root         string
+	MountPoint   string
+	Options      []string
+	Optional     []string
+	FSType       string
+	Source       string
+	SuperOptions []string
+}
+
+// readMountInfo parses a mountinfo file. The format is described in the proc(5) man page:
+// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func readMountInfo(fs afero.Fs, mountInfoPath string) ([]mountInfoEntry, error) {
+	content, err := afero.ReadFile(fs, mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []mountInfoEntry{}
+	for index, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		separator := -1
+		for i, field := range fields {
+			if field == "-" {
+				separator = i
+				break
+			}
+		}
+
+		if separator < 6 || len(fields) < separator+3 {
+			return nil, fmt.Errorf("invalid mountinfo entry in line %d: %s", index+1, line)
+		}
+
+		entry := mountInfoEntry{
+			ID:           fields[0],
+			ParentID:     fields[1],
+			MajorMinor:   fields[2],
+			Root:         unescapeMountField(fields[3]),
+			MountPoint:   unescapeMountField(fields[4]),
+			Options:      strings.Split(fields[5], ","),
+			Optional:     fields[6:separator],
+			FSType:       fields[separator+1],
+			Source:       unescapeMountField(fields[separator+2]),
+			SuperOptions: []string{},
+		}
+
+		if len(fields) > separator+3 {
+			entry.SuperOptions = strings.Split(fields[separator+3], ",")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// unescapeMountField replaces the octal escaped characters used by the kernel
+// and fstab for spaces, tabs, new lines and backslashes
+func unescapeMountField(field string) string {
+	replacer := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+	return replacer.Replace(field)
+}
@@ -0,0 +1,381 @@
+package gatherers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/internal/core/cluster/cib"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	MountsGathererName         = "mounts"
+	FstabPath                  = "/etc/fstab"
+	filesystemResourceAgent    = "Filesystem"
+	filesystemResourceDevice   = "device"
+	filesystemResourceDir      = "directory"
+	filesystemResourceFSType   = "fstype"
+	filesystemResourceOptions  = "options"
+	mountsNFSDefaultV4Version  = "4"
+	mountsNFSVersionOption     = "vers"
+	mountsNFSVersionOptionLong = "nfsvers"
+)
+
+// nolint:gochecknoglobals
+var (
+	MountsInvalidArgumentError = entities.FactGatheringError{
+		Type:    "mounts-invalid-argument",
+		Message: "invalid argument, an absolute mount point path is expected",
+	}
+
+	MountsFileError = entities.FactGatheringError{
+		Type:    "mounts-file-error",
+		Message: "error reading the mounts information",
+	}
+
+	MountsDecodingError = entities.FactGatheringError{
+		Type:    "mounts-decoding-error",
+		Message: "error decoding the mounts information",
+	}
+
+	MountsNotFoundError = entities.FactGatheringError{
+		Type:    "mounts-not-found",
+		Message: "the requested mount point was not found",
+	}
+)
+
+type MountsGatherer struct {
+	executor utils.CommandExecutor
+	fs       afero.Fs
+}
+
+type fstabEntry struct {
+	Device     string
+	MountPoint string
+	FSType     string
+	Options    []string
+	Dump       int
+	Pass       int
+}
+
+type filesystemResource struct {
+	ID         string
+	Device     string
+	MountPoint string
+	FSType     string
+	Options    []string
+}
+
+type mountsState struct {
+	mountPoints []string
+	mounted     map[string]mountInfoEntry
+	fstab       map[string]fstabEntry
+	resources   map[string]filesystemResource
+}
+
+func NewDefaultMountsGatherer() *MountsGatherer {
+	return NewMountsGatherer(utils.Executor{}, afero.NewOsFs())
+}
+
+func NewMountsGatherer(executor utils.CommandExecutor, fs afero.Fs) *MountsGatherer {
+	return &MountsGatherer{
+		executor: executor,
+		fs:       fs,
+	}
+}
+
+// Gather returns the mount information of the mount point given as argument, combining the
+// current mounts, the fstab entries and the cluster Filesystem resources.
+// Arguments with glob patterns, like /usr/sap/*/ASCS*, return a list with the matching mount points
+func (g *MountsGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", MountsGathererName)
+
+	state, gatheringError := g.loadMountsState()
+	if gatheringError != nil {
+		return nil, gatheringError
+	}
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		if value, err := state.getMounts(factReq.Argument); err == nil {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		} else {
+			log.Error(err)
+			fact = entities.NewFactGatheredWithError(factReq, err)
+		}
+
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", MountsGathererName)
+	return facts, nil
+}
+
+func (g *MountsGatherer) loadMountsState() (*mountsState, *entities.FactGatheringError) {
+	mountInfo, err := readMountInfo(g.fs, MountInfoPath)
+	if err != nil {
+		return nil, MountsFileError.Wrap(err.Error())
+	}
+
+	fstab, err := readFstab(g.fs, FstabPath)
+	if err != nil {
+		return nil, MountsDecodingError.Wrap(err.Error())
+	}
+
+	resources, err := g.getFilesystemResources()
+	if err != nil {
+		return nil, MountsDecodingError.Wrap(err.Error())
+	}
+
+	state := &mountsState{
+		mountPoints: []string{},
+		mounted:     make(map[string]mountInfoEntry),
+		fstab:       make(map[string]fstabEntry),
+		resources:   make(map[string]filesystemResource),
+	}
+
+	addMountPoint := func(mountPoint string) {
+		_, mounted := state.mounted[mountPoint]
+		_, inFstab := state.fstab[mountPoint]
+		_, managed := state.resources[mountPoint]
+		if !mounted && !inFstab && !managed {
+			state.mountPoints = append(state.mountPoints, mountPoint)
+		}
+	}
+
+	// the last entry of a mount point is the visible one, if some path is mounted more than once
+	for _, entry := range mountInfo {
+		mountPoint := path.Clean(entry.MountPoint)
+		addMountPoint(mountPoint)
+		state.mounted[mountPoint] = entry
+	}
+
+	for _, entry := range fstab {
+		mountPoint := path.Clean(entry.MountPoint)
+		addMountPoint(mountPoint)
+		state.fstab[mountPoint] = entry
+	}
+
+	for _, resource := range resources {
+		mountPoint := path.Clean(resource.MountPoint)
+		addMountPoint(mountPoint)
+		state.resources[mountPoint] = resource
+	}
+
+	return state, nil
+}
+
+// getFilesystemResources returns the cluster Filesystem resources. Hosts without
+// a running cluster don't have any cluster managed mount point
+func (g *MountsGatherer) getFilesystemResources() ([]filesystemResource, error) {
+	cibadmin, err := g.executor.Exec("cibadmin", "--query", "--local")
+	if err != nil {
+		log.Debugf("Cluster information not available, mounts are not cluster managed: %s", err)
+		return []filesystemResource{}, nil
+	}
+
+	var root cib.Root
+	if err := xml.Unmarshal(cibadmin, &root); err != nil {
+		return nil, fmt.Errorf("could not parse cibadmin output: %w", err)
+	}
+
+	resources := []filesystemResource{}
+	for _, primitive := range root.Primitives() {
+		if primitive.Type != filesystemResourceAgent {
+			continue
+		}
+
+		resource := filesystemResource{ID: primitive.ID, Options: []string{}}
+		for _, attribute := range primitive.InstanceAttributes {
+			switch attribute.Name {
+			case filesystemResourceDevice:
+				resource.Device = attribute.Value
+			case filesystemResourceDir:
+				resource.MountPoint = attribute.Value
+			case filesystemResourceFSType:
+				resource.FSType = attribute.Value
+			case filesystemResourceOptions:
+				resource.Options = strings.Split(attribute.Value, ",")
+			}
+		}
+
+		if resource.MountPoint != "" {
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources, nil
+}
+
+func (s *mountsState) getMounts(argument string) (entities.FactValue, *entities.FactGatheringError) {
+	if !path.IsAbs(argument) {
+		return nil, MountsInvalidArgumentError.Wrap(argument)
+	}
+
+	if !strings.ContainsAny(argument, "*?[") {
+		mountPoint := path.Clean(argument)
+		_, mounted := s.mounted[mountPoint]
+		_, inFstab := s.fstab[mountPoint]
+		_, managed := s.resources[mountPoint]
+		if !mounted && !inFstab && !managed {
+			return nil, MountsNotFoundError.Wrap(argument)
+		}
+		return s.mountToFactValue(mountPoint), nil
+	}
+
+	mounts := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, mountPoint := range s.mountPoints {
+		matched, err := path.Match(argument, mountPoint)
+		if err != nil {
+			return nil, MountsInvalidArgumentError.Wrap(fmt.Sprintf("%s: %s", argument, err))
+		}
+		if matched {
+			mounts.AppendValue(s.mountToFactValue(mountPoint))
+		}
+	}
+
+	return mounts, nil
+}
+
+// mountToFactValue returns the mount point information. The device, file system type and
+// options are taken from the current mount, or from the fstab and cluster configuration
+// if the mount point is not mounted
+func (s *mountsState) mountToFactValue(mountPoint string) entities.FactValue {
+	mount, mounted := s.mounted[mountPoint]
+	fstab, inFstab := s.fstab[mountPoint]
+	resource, managed := s.resources[mountPoint]
+
+	var device, fsType string
+	options := []string{}
+	superOptions := []string{}
+
+	switch {
+	case mounted:
+		device, fsType, options, superOptions = mount.Source, mount.FSType, mount.Options, mount.SuperOptions
+	case inFstab:
+		device, fsType, options = fstab.Device, fstab.FSType, fstab.Options
+	case managed:
+		device, fsType, options = resource.Device, resource.FSType, resource.Options
+	}
+
+	mountMap := map[string]entities.FactValue{
+		"mount_point":     &entities.FactValueString{Value: mountPoint},
+		"mounted":         &entities.FactValueBool{Value: mounted},
+		"device":          &entities.FactValueString{Value: device},
+		"fstype":          &entities.FactValueString{Value: fsType},
+		"options":         stringsToFactValueList(options),
+		"super_options":   stringsToFactValueList(superOptions),
+		"nfs_version":     &entities.FactValueString{Value: nfsVersion(fsType, options, superOptions)},
+		"in_fstab":        &entities.FactValueBool{Value: inFstab},
+		"cluster_managed": &entities.FactValueBool{Value: managed},
+	}
+
+	if inFstab {
+		mountMap["fstab"] = &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"device":  &entities.FactValueString{Value: fstab.Device},
+			"fstype":  &entities.FactValueString{Value: fstab.FSType},
+			"options": stringsToFactValueList(fstab.Options),
+			"dump":    &entities.FactValueInt{Value: fstab.Dump},
+			"pass":    &entities.FactValueInt{Value: fstab.Pass},
+		}}
+	}
+
+	if managed {
+		mountMap["cluster_resource"] = &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"id":      &entities.FactValueString{Value: resource.ID},
+			"device":  &entities.FactValueString{Value: resource.Device},
+			"fstype":  &entities.FactValueString{Value: resource.FSType},
+			"options": stringsToFactValueList(resource.Options),
+		}}
+	}
+
+	return &entities.FactValueMap{Value: mountMap}
+}
+
+// nfsVersion returns the NFS protocol version from the mount options. The kernel
+// reports it in the vers option, but fstab and cluster resources might use nfsvers
+func nfsVersion(fsType string, options, superOptions []string) string {
+	if fsType != "nfs" && fsType != "nfs4" {
+		return ""
+	}
+
+	allOptions := []string{}
+	allOptions = append(allOptions, options...)
+	allOptions = append(allOptions, superOptions...)
+
+	for _, option := range allOptions {
+		keyValue := strings.SplitN(option, "=", 2)
+		if len(keyValue) == 2 && (keyValue[0] == mountsNFSVersionOption || keyValue[0] == mountsNFSVersionOptionLong) {
+			return keyValue[1]
+		}
+	}
+
+	if fsType == "nfs4" {
+		return mountsNFSDefaultV4Version
+	}
+
+	return ""
+}
+
+// readFstab parses the fstab file, as described in the fstab(5) man page.
+// A missing file is handled as an empty one
+func readFstab(fs afero.Fs, fstabPath string) ([]fstabEntry, error) {
+	content, err := afero.ReadFile(fs, fstabPath)
+	if os.IsNotExist(err) {
+		return []fstabEntry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	entries := []fstabEntry{}
+	for index, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid fstab entry in line %d: %s", index+1, trimmed)
+		}
+
+		entry := fstabEntry{
+			Device:     unescapeMountField(fields[0]),
+			MountPoint: unescapeMountField(fields[1]),
+			FSType:     "auto",
+			Options:    []string{"defaults"},
+			Dump:       0,
+			Pass:       0,
+		}
+
+		if len(fields) > 2 {
+			entry.FSType = fields[2]
+		}
+		if len(fields) > 3 {
+			entry.Options = strings.Split(fields[3], ",")
+		}
+		if len(fields) > 4 {
+			if entry.Dump, err = strconv.Atoi(fields[4]); err != nil {
+				return nil, fmt.Errorf("invalid fstab dump value in line %d: %s", index+1, fields[4])
+			}
+		}
+		if len(fields) > 5 {
+			if entry.Pass, err = strconv.Atoi(fields[5]); err != nil {
+				return nil, fmt.Errorf("invalid fstab pass value in line %d: %s", index+1, fields[5])
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
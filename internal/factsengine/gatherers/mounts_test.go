@@ -0,0 +1,302 @@
+package gatherers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+)
+
+type MountsTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+	fs           afero.Fs
+}
+
+func TestMountsTestSuite(t *testing.T) {
+	suite.Run(t, new(MountsTestSuite))
+}
+
+func (suite *MountsTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+	suite.fs = afero.NewMemMapFs()
+	_ = afero.WriteFile(suite.fs, "/proc/self/mountinfo", readFixture("gatherers/mountinfo"), 0644)
+	_ = afero.WriteFile(suite.fs, "/etc/fstab", readFixture("gatherers/fstab"), 0644)
+}
+
+func (suite *MountsTestSuite) TestMountsGather() {
+	suite.mockExecutor.On("Exec", "cibadmin", "--query", "--local").Return(
+		readFixture("gatherers/cibadmin-filesystem.xml"), nil)
+
+	c := gatherers.NewMountsGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "hana_shared",
+			Gatherer: "mounts",
+			Argument: "/hana/shared",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "ascs",
+			Gatherer: "mounts",
+			Argument: "/usr/sap/PRD/ASCS00/",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "ers",
+			Gatherer: "mounts",
+			Argument: "/usr/sap/PRD/ERS10",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "sapmnt",
+			Gatherer: "mounts",
+			Argument: "/sapmnt/PRD",
+			CheckID:  "check3",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "hana_shared",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"mount_point": &entities.FactValueString{Value: "/hana/shared"},
+				"mounted":     &entities.FactValueBool{Value: true},
+				"device":      &entities.FactValueString{Value: "10.0.0.10:/hana/shared"},
+				"fstype":      &entities.FactValueString{Value: "nfs4"},
+				"options": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "rw"},
+					&entities.FactValueString{Value: "relatime"},
+				}},
+				"super_options": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "rw"},
+					&entities.FactValueString{Value: "vers=4.1"},
+					&entities.FactValueString{Value: "rsize=1048576"},
+					&entities.FactValueString{Value: "wsize=1048576"},
+					&entities.FactValueString{Value: "hard"},
+					&entities.FactValueString{Value: "proto=tcp"},
+					&entities.FactValueString{Value: "timeo=600"},
+					&entities.FactValueString{Value: "retrans=2"},
+					&entities.FactValueString{Value: "sec=sys"},
+					&entities.FactValueString{Value: "clientaddr=10.0.0.5"},
+					&entities.FactValueString{Value: "local_lock=none"},
+					&entities.FactValueString{Value: "addr=10.0.0.10"},
+				}},
+				"nfs_version":     &entities.FactValueString{Value: "4.1"},
+				"in_fstab":        &entities.FactValueBool{Value: true},
+				"cluster_managed": &entities.FactValueBool{Value: false},
+				"fstab": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"device": &entities.FactValueString{Value: "10.0.0.10:/hana/shared"},
+					"fstype": &entities.FactValueString{Value: "nfs"},
+					"options": &entities.FactValueList{Value: []entities.FactValue{
+						&entities.FactValueString{Value: "rw"},
+						&entities.FactValueString{Value: "nfsvers=4.1"},
+						&entities.FactValueString{Value: "hard"},
+						&entities.FactValueString{Value: "_netdev"},
+					}},
+					"dump": &entities.FactValueInt{Value: 0},
+					"pass": &entities.FactValueInt{Value: 0},
+				}},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name: "ascs",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"mount_point": &entities.FactValueString{Value: "/usr/sap/PRD/ASCS00"},
+				"mounted":     &entities.FactValueBool{Value: true},
+				"device":      &entities.FactValueString{Value: "10.0.0.10:/usr/sap/PRD/ASCS00"},
+				"fstype":      &entities.FactValueString{Value: "nfs4"},
+				"options": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "rw"},
+					&entities.FactValueString{Value: "relatime"},
+				}},
+				"super_options": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "rw"},
+					&entities.FactValueString{Value: "vers=4.1"},
+					&entities.FactValueString{Value: "hard"},
+					&entities.FactValueString{Value: "proto=tcp"},
+				}},
+				"nfs_version":     &entities.FactValueString{Value: "4.1"},
+				"in_fstab":        &entities.FactValueBool{Value: true},
+				"cluster_managed": &entities.FactValueBool{Value: true},
+				"fstab": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"device": &entities.FactValueString{Value: "10.0.0.10:/usr/sap/PRD/ASCS00"},
+					"fstype": &entities.FactValueString{Value: "nfs4"},
+					"options": &entities.FactValueList{Value: []entities.FactValue{
+						&entities.FactValueString{Value: "rw"},
+						&entities.FactValueString{Value: "hard"},
+					}},
+					"dump": &entities.FactValueInt{Value: 0},
+					"pass": &entities.FactValueInt{Value: 0},
+				}},
+				"cluster_resource": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"id":      &entities.FactValueString{Value: "rsc_fs_PRD_ASCS00"},
+					"device":  &entities.FactValueString{Value: "10.0.0.10:/usr/sap/PRD/ASCS00"},
+					"fstype":  &entities.FactValueString{Value: "nfs4"},
+					"options": &entities.FactValueList{Value: []entities.FactValue{}},
+				}},
+			}},
+			CheckID: "check2",
+		},
+		{
+			Name: "ers",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"mount_point": &entities.FactValueString{Value: "/usr/sap/PRD/ERS10"},
+				"mounted":     &entities.FactValueBool{Value: false},
+				"device":      &entities.FactValueString{Value: "10.0.0.10:/usr/sap/PRD/ERS10"},
+				"fstype":      &entities.FactValueString{Value: "nfs"},
+				"options": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "nfsvers=4.2"},
+					&entities.FactValueString{Value: "hard"},
+				}},
+				"super_options":   &entities.FactValueList{Value: []entities.FactValue{}},
+				"nfs_version":     &entities.FactValueString{Value: "4.2"},
+				"in_fstab":        &entities.FactValueBool{Value: false},
+				"cluster_managed": &entities.FactValueBool{Value: true},
+				"cluster_resource": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"id":     &entities.FactValueString{Value: "rsc_fs_PRD_ERS10"},
+					"device": &entities.FactValueString{Value: "10.0.0.10:/usr/sap/PRD/ERS10"},
+					"fstype": &entities.FactValueString{Value: "nfs"},
+					"options": &entities.FactValueList{Value: []entities.FactValue{
+						&entities.FactValueString{Value: "nfsvers=4.2"},
+						&entities.FactValueString{Value: "hard"},
+					}},
+				}},
+			}},
+			CheckID: "check2",
+		},
+		{
+			Name: "sapmnt",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"mount_point": &entities.FactValueString{Value: "/sapmnt/PRD"},
+				"mounted":     &entities.FactValueBool{Value: false},
+				"device":      &entities.FactValueString{Value: "10.0.0.10:/sapmnt/PRD"},
+				"fstype":      &entities.FactValueString{Value: "nfs"},
+				"options": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "vers=3"},
+					&entities.FactValueString{Value: "noauto"},
+				}},
+				"super_options":   &entities.FactValueList{Value: []entities.FactValue{}},
+				"nfs_version":     &entities.FactValueString{Value: "3"},
+				"in_fstab":        &entities.FactValueBool{Value: true},
+				"cluster_managed": &entities.FactValueBool{Value: false},
+				"fstab": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"device": &entities.FactValueString{Value: "10.0.0.10:/sapmnt/PRD"},
+					"fstype": &entities.FactValueString{Value: "nfs"},
+					"options": &entities.FactValueList{Value: []entities.FactValue{
+						&entities.FactValueString{Value: "vers=3"},
+						&entities.FactValueString{Value: "noauto"},
+					}},
+					"dump": &entities.FactValueInt{Value: 0},
+					"pass": &entities.FactValueInt{Value: 0},
+				}},
+			}},
+			CheckID: "check3",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockExecutor.AssertNumberOfCalls(suite.T(), "Exec", 1)
+}
+
+func (suite *MountsTestSuite) TestMountsGatherPattern() {
+	suite.mockExecutor.On("Exec", "cibadmin", "--query", "--local").Return(
+		[]byte{}, errors.New("cibadmin: command not found"))
+
+	c := gatherers.NewMountsGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "hana_mounts",
+			Gatherer: "mounts",
+			Argument: "/hana/*",
+		},
+		{
+			Name:     "no_cluster",
+			Gatherer: "mounts",
+			Argument: "/usr/sap/PRD/ASCS00",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+	suite.NoError(err)
+	suite.Len(factResults, 2)
+
+	hanaMounts, ok := factResults[0].Value.(*entities.FactValueList)
+	suite.True(ok)
+	suite.Len(hanaMounts.Value, 2)
+
+	mountPoints := []string{}
+	for _, mount := range hanaMounts.Value {
+		mountPoint, _ := mount.(*entities.FactValueMap).GetValue("mount_point")
+		mountPoints = append(mountPoints, mountPoint.(*entities.FactValueString).Value)
+	}
+	suite.ElementsMatch([]string{"/hana/data", "/hana/shared"}, mountPoints)
+
+	ascs, _ := factResults[1].Value.(*entities.FactValueMap)
+	suite.Equal(&entities.FactValueBool{Value: false}, ascs.Value["cluster_managed"])
+	suite.Equal(&entities.FactValueBool{Value: true}, ascs.Value["mounted"])
+}
+
+func (suite *MountsTestSuite) TestMountsGatherErrors() {
+	suite.mockExecutor.On("Exec", "cibadmin", "--query", "--local").Return(
+		readFixture("gatherers/cibadmin-filesystem.xml"), nil)
+
+	c := gatherers.NewMountsGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "relative",
+			Gatherer: "mounts",
+			Argument: "hana/shared",
+		},
+		{
+			Name:     "not_found",
+			Gatherer: "mounts",
+			Argument: "/hana/log",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "relative",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "invalid argument, an absolute mount point path is expected: hana/shared",
+				Type:    "mounts-invalid-argument",
+			},
+		},
+		{
+			Name:  "not_found",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "the requested mount point was not found: /hana/log",
+				Type:    "mounts-not-found",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *MountsTestSuite) TestMountsGatherMountInfoError() {
+	c := gatherers.NewMountsGatherer(suite.mockExecutor, afero.NewMemMapFs())
+
+	factResults, err := c.Gather([]entities.FactRequest{})
+
+	suite.EqualError(err, "fact gathering error: mounts-file-error - "+
+		"error reading the mounts information: open /proc/self/mountinfo: file does not exist")
+	suite.Empty(factResults)
+}
@@ -0,0 +1,152 @@
+package gatherers
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/internal/core/hosts"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+)
+
+const (
+	NetworkGathererName = "network"
+)
+
+// nolint:gochecknoglobals
+var (
+	NetworkDiscoveryError = entities.FactGatheringError{
+		Type:    "network-discovery-error",
+		Message: "error discovering the network configuration",
+	}
+)
+
+type NetworkGatherer struct {
+	fs       afero.Fs
+	provider hosts.NetworkInterfacesProvider
+}
+
+func NewDefaultNetworkGatherer() *NetworkGatherer {
+	return NewNetworkGatherer(afero.NewOsFs(), hosts.NetlinkInterfacesProvider{})
+}
+
+func NewNetworkGatherer(fs afero.Fs, provider hosts.NetworkInterfacesProvider) *NetworkGatherer {
+	return &NetworkGatherer{
+		fs:       fs,
+		provider: provider,
+	}
+}
+
+// Gather returns the network configuration of the host. The argument is the path to the requested
+// value, like interfaces.0.mtu, routes, default_routes or dns.nameservers. The interfaces are a list,
+// with the interface name in the name field, as names like eth2.100 can't be used in the path
+func (g *NetworkGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", NetworkGathererName)
+
+	network, err := hosts.NewNetwork(g.fs, g.provider)
+	if err != nil {
+		gatheringError := NetworkDiscoveryError.Wrap(err.Error())
+		log.Error(gatheringError)
+		return entities.NewFactsGatheredListWithError(factsRequests, gatheringError), nil
+	}
+
+	networkMap := networkToFactValueMap(network)
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		if value, err := networkMap.GetValue(factReq.Argument); err == nil {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		} else {
+			log.Error(err)
+			fact = entities.NewFactGatheredWithError(factReq, err)
+		}
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", NetworkGathererName)
+	return facts, nil
+}
+
+func networkToFactValueMap(network *hosts.Network) *entities.FactValueMap {
+	interfaces := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, networkInterface := range network.Interfaces {
+		interfaces.AppendValue(networkInterfaceToFactValue(networkInterface))
+	}
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"interfaces":     interfaces,
+		"routes":         networkRoutesToFactValue(network.Routes),
+		"default_routes": networkRoutesToFactValue(network.DefaultRoutes()),
+		"dns": &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"nameservers": stringsToFactValueList(network.DNS.Nameservers),
+			"search":      stringsToFactValueList(network.DNS.Search),
+			"options":     stringsToFactValueList(network.DNS.Options),
+		}},
+	}}
+}
+
+func networkInterfaceToFactValue(networkInterface *hosts.NetworkInterface) entities.FactValue {
+	addresses := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, address := range networkInterface.Addresses {
+		addresses.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"address":       &entities.FactValueString{Value: address.Address},
+			"prefix_length": &entities.FactValueInt{Value: address.PrefixLength},
+			"family":        &entities.FactValueString{Value: address.Family},
+			"scope":         &entities.FactValueString{Value: address.Scope},
+		}})
+	}
+
+	interfaceMap := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"name":        &entities.FactValueString{Value: networkInterface.Name},
+		"index":       &entities.FactValueInt{Value: networkInterface.Index},
+		"mac_address": &entities.FactValueString{Value: networkInterface.MacAddress},
+		"mtu":         &entities.FactValueInt{Value: networkInterface.MTU},
+		"state":       &entities.FactValueString{Value: networkInterface.State},
+		"loopback":    &entities.FactValueBool{Value: networkInterface.Loopback},
+		"addresses":   addresses,
+		"master":      &entities.FactValueString{Value: networkInterface.Master},
+	}}
+
+	if bond := networkInterface.Bonding; bond != nil {
+		slaves := &entities.FactValueList{Value: []entities.FactValue{}}
+		for _, slave := range bond.Slaves {
+			slaves.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+				"name":               &entities.FactValueString{Value: slave.Name},
+				"mii_status":         &entities.FactValueString{Value: slave.MIIStatus},
+				"link_failure_count": &entities.FactValueInt{Value: slave.LinkFailureCount},
+			}})
+		}
+
+		interfaceMap.Value["bonding"] = &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"mode":                 &entities.FactValueString{Value: bond.Mode},
+			"mii_status":           &entities.FactValueString{Value: bond.MIIStatus},
+			"mii_polling_interval": &entities.FactValueInt{Value: bond.MIIPollingInterval},
+			"active_slave":         &entities.FactValueString{Value: bond.ActiveSlave},
+			"slaves":               slaves,
+		}}
+	}
+
+	if vlan := networkInterface.VLAN; vlan != nil {
+		interfaceMap.Value["vlan"] = &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"id":     &entities.FactValueInt{Value: vlan.ID},
+			"parent": &entities.FactValueString{Value: vlan.Parent},
+		}}
+	}
+
+	return interfaceMap
+}
+
+func networkRoutesToFactValue(routes []*hosts.NetworkRoute) entities.FactValue {
+	routesList := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, route := range routes {
+		routesList.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"family":      &entities.FactValueString{Value: route.Family},
+			"destination": &entities.FactValueString{Value: route.Destination},
+			"gateway":     &entities.FactValueString{Value: route.Gateway},
+			"interface":   &entities.FactValueString{Value: route.Interface},
+			"metric":      &entities.FactValueInt{Value: route.Metric},
+			"default":     &entities.FactValueBool{Value: route.Default},
+		}})
+	}
+	return routesList
+}
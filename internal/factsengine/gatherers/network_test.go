@@ -0,0 +1,260 @@
+package gatherers_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	hostsMocks "github.com/trento-project/agent/internal/core/hosts/mocks"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+)
+
+type NetworkTestSuite struct {
+	suite.Suite
+	fs           afero.Fs
+	mockProvider *hostsMocks.NetworkInterfacesProvider
+}
+
+func TestNetworkTestSuite(t *testing.T) {
+	suite.Run(t, new(NetworkTestSuite))
+}
+
+func (suite *NetworkTestSuite) SetupTest() {
+	suite.fs = afero.NewMemMapFs()
+	suite.mockProvider = new(hostsMocks.NetworkInterfacesProvider)
+
+	_ = afero.WriteFile(suite.fs, "/proc/net/route", readFixture("discovery/host/network/route"), 0644)
+	_ = afero.WriteFile(suite.fs, "/proc/net/bonding/bond0", readFixture("discovery/host/network/bond0"), 0644)
+	_ = afero.WriteFile(suite.fs, "/etc/resolv.conf", readFixture("discovery/host/network/resolv.conf"), 0644)
+	_ = afero.WriteFile(suite.fs, "/sys/class/net/bond0/operstate", []byte("up\n"), 0644)
+}
+
+func (suite *NetworkTestSuite) TestNetworkGather() {
+	eth0 := net.Interface{Index: 2, Name: "eth0", MTU: 9000, Flags: net.FlagUp}
+	bond0 := net.Interface{Index: 4, Name: "bond0", MTU: 9000, Flags: net.FlagUp}
+	_, bondAddress, _ := net.ParseCIDR("192.168.1.20/24")
+	bondAddress.IP = net.ParseIP("192.168.1.20")
+
+	suite.mockProvider.On("Interfaces").Return([]net.Interface{eth0, bond0}, nil)
+	suite.mockProvider.On("Addrs", eth0).Return([]net.Addr{}, nil)
+	suite.mockProvider.On("Addrs", bond0).Return([]net.Addr{bondAddress}, nil)
+
+	c := gatherers.NewNetworkGatherer(suite.fs, suite.mockProvider)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "bond_mode",
+			Gatherer: "network",
+			Argument: "interfaces.1.bonding.mode",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "eth0_master",
+			Gatherer: "network",
+			Argument: "interfaces.0.master",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "bond_address",
+			Gatherer: "network",
+			Argument: "interfaces.1.addresses.0",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "default_routes",
+			Gatherer: "network",
+			Argument: "default_routes",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "nameservers",
+			Gatherer: "network",
+			Argument: "dns.nameservers",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "unknown",
+			Gatherer: "network",
+			Argument: "interfaces.9.mtu",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "bond_mode",
+			Value:   &entities.FactValueString{Value: "active-backup"},
+			CheckID: "check1",
+		},
+		{
+			Name:    "eth0_master",
+			Value:   &entities.FactValueString{Value: "bond0"},
+			CheckID: "check1",
+		},
+		{
+			Name: "bond_address",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"address":       &entities.FactValueString{Value: "192.168.1.20"},
+				"prefix_length": &entities.FactValueInt{Value: 24},
+				"family":        &entities.FactValueString{Value: "ipv4"},
+				"scope":         &entities.FactValueString{Value: "global"},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name: "default_routes",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"family":      &entities.FactValueString{Value: "ipv4"},
+					"destination": &entities.FactValueString{Value: "0.0.0.0/0"},
+					"gateway":     &entities.FactValueString{Value: "192.168.1.1"},
+					"interface":   &entities.FactValueString{Value: "bond0"},
+					"metric":      &entities.FactValueInt{Value: 100},
+					"default":     &entities.FactValueBool{Value: true},
+				}},
+			}},
+			CheckID: "check2",
+		},
+		{
+			Name: "nameservers",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "192.168.1.10"},
+				&entities.FactValueString{Value: "192.168.1.11"},
+			}},
+			CheckID: "check2",
+		},
+		{
+			Name:  "unknown",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error getting value: 9 index is not available in the list: interfaces.9.mtu",
+				Type:    "value-not-found",
+			},
+			CheckID: "check2",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *NetworkTestSuite) TestNetworkGatherBondingState() {
+	bond0 := net.Interface{Index: 4, Name: "bond0", MTU: 9000, Flags: net.FlagUp}
+	suite.mockProvider.On("Interfaces").Return([]net.Interface{bond0}, nil)
+	suite.mockProvider.On("Addrs", bond0).Return([]net.Addr{}, nil)
+
+	c := gatherers.NewNetworkGatherer(suite.fs, suite.mockProvider)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "bond",
+			Gatherer: "network",
+			Argument: "interfaces.0",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+	suite.NoError(err)
+
+	bond, ok := factResults[0].Value.(*entities.FactValueMap)
+	suite.True(ok)
+
+	expectedValues := map[string]entities.FactValue{
+		"state":                        &entities.FactValueString{Value: "up"},
+		"mtu":                          &entities.FactValueInt{Value: 9000},
+		"loopback":                     &entities.FactValueBool{Value: false},
+		"bonding.active_slave":         &entities.FactValueString{Value: "eth0"},
+		"bonding.mii_polling_interval": &entities.FactValueInt{Value: 100},
+		"bonding.slaves.1.name":        &entities.FactValueString{Value: "eth1"},
+		"bonding.slaves.1.mii_status":  &entities.FactValueString{Value: "down"},
+	}
+
+	for valuePath, expected := range expectedValues {
+		value, valueErr := bond.GetValue(valuePath)
+		suite.Nil(valueErr, valuePath)
+		suite.Equal(expected, value, valuePath)
+	}
+
+	_, valueErr := bond.GetValue("vlan")
+	suite.NotNil(valueErr)
+}
+
+func (suite *NetworkTestSuite) TestNetworkGatherVLAN() {
+	_ = afero.WriteFile(suite.fs, "/proc/net/vlan/config", readFixture("discovery/host/network/vlan_config"), 0644)
+
+	eth2 := net.Interface{Index: 3, Name: "eth2", MTU: 1500, Flags: net.FlagUp}
+	vlan := net.Interface{Index: 5, Name: "eth2.100", MTU: 1500, Flags: net.FlagUp}
+	suite.mockProvider.On("Interfaces").Return([]net.Interface{eth2, vlan}, nil)
+	suite.mockProvider.On("Addrs", eth2).Return([]net.Addr{}, nil)
+	suite.mockProvider.On("Addrs", vlan).Return([]net.Addr{}, nil)
+
+	c := gatherers.NewNetworkGatherer(suite.fs, suite.mockProvider)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "vlan_name",
+			Gatherer: "network",
+			Argument: "interfaces.1.name",
+		},
+		{
+			Name:     "vlan",
+			Gatherer: "network",
+			Argument: "interfaces.1.vlan",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "vlan_name",
+			Value: &entities.FactValueString{Value: "eth2.100"},
+		},
+		{
+			Name: "vlan",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"id":     &entities.FactValueInt{Value: 100},
+				"parent": &entities.FactValueString{Value: "eth2"},
+			}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *NetworkTestSuite) TestNetworkGatherError() {
+	suite.mockProvider.On("Interfaces").Return(nil, errors.New("netlink error"))
+
+	c := gatherers.NewNetworkGatherer(suite.fs, suite.mockProvider)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "mtu",
+			Gatherer: "network",
+			Argument: "interfaces.0.mtu",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "mtu",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error discovering the network configuration: " +
+					"error listing the network interfaces: netlink error",
+				Type: "network-discovery-error",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
@@ -1,6 +1,11 @@
 package gatherers
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
 	"github.com/trento-project/agent/pkg/utils"
@@ -8,6 +13,12 @@ import (
 
 const (
 	PackageVersionGathererName = "package_version"
+	packageVersionQueryFormat  = "%{NAME}|%{EPOCH}|%{VERSION}|%{RELEASE}|%{ARCH}|%{INSTALLTIME}\\n"
+	rpmNoneValue               = "(none)"
+)
+
+var (
+	packageComparisonCompiled = regexp.MustCompile(`^([^<>=!\s]+)\s*(>=|<=|==|!=|=|<|>)\s*([^<>=!\s]\S*)$`)
 )
 
 // nolint:gochecknoglobals
@@ -16,12 +27,36 @@ var (
 		Type:    "package-version-cmd-error",
 		Message: "error getting version of package",
 	}
+
+	PackageVersionNotInstalledError = entities.FactGatheringError{
+		Type:    "package-version-not-installed",
+		Message: "package is not installed",
+	}
+
+	PackageVersionDecodingError = entities.FactGatheringError{
+		Type:    "package-version-decoding-error",
+		Message: "error decoding rpm output",
+	}
+
+	PackageVersionInvalidArgumentError = entities.FactGatheringError{
+		Type:    "package-version-invalid-argument",
+		Message: "invalid package version argument",
+	}
 )
 
 type PackageVersionGatherer struct {
 	executor utils.CommandExecutor
 }
 
+type installedPackage struct {
+	Name        string
+	Epoch       int
+	Version     string
+	Release     string
+	Arch        string
+	InstallTime int
+}
+
 func NewDefaultPackageVersionGatherer() *PackageVersionGatherer {
 	return NewPackageVersionGatherer(utils.Executor{})
 }
@@ -32,20 +67,23 @@ func NewPackageVersionGatherer(executor utils.CommandExecutor) *PackageVersionGa
 	}
 }
 
+// Gather returns, for every requested package, a list with the details of every installed
+// instance of the package.
+// If the argument has a comparison, like pacemaker>=2.0.5, a boolean is returned instead,
+// comparing the most recent installed instance of the package using the rpm version semantics
 func (g *PackageVersionGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
 	facts := []entities.Fact{}
 	log.Infof("Starting %s facts gathering process", PackageVersionGathererName)
 
 	for _, factReq := range factsRequests {
 		var fact entities.Fact
-		version, err := g.executor.Exec(
-			"rpm", "-q", "--qf", "%{VERSION}", factReq.Argument)
-		if err != nil {
-			gatheringError := PackageVersionCommandError.Wrap(factReq.Argument)
+
+		value, gatheringError := g.gatherPackage(factReq.Argument)
+		if gatheringError != nil {
 			log.Error(gatheringError)
 			fact = entities.NewFactGatheredWithError(factReq, gatheringError)
 		} else {
-			fact = entities.NewFactGatheredWithRequest(factReq, &entities.FactValueString{Value: (string(version))})
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
 		}
 
 		facts = append(facts, fact)
@@ -54,3 +92,278 @@ func (g *PackageVersionGatherer) Gather(factsRequests []entities.FactRequest) ([
 	log.Infof("Requested %s facts gathered", PackageVersionGathererName)
 	return facts, nil
 }
+
+func (g *PackageVersionGatherer) gatherPackage(argument string) (entities.FactValue, *entities.FactGatheringError) {
+	packageName := strings.TrimSpace(argument)
+	operator := ""
+	requestedVersion := ""
+
+	if strings.ContainsAny(argument, "<>=!") {
+		match := packageComparisonCompiled.FindStringSubmatch(strings.TrimSpace(argument))
+		if match == nil {
+			return nil, PackageVersionInvalidArgumentError.Wrap(argument)
+		}
+		packageName, operator, requestedVersion = match[1], match[2], match[3]
+	}
+
+	if packageName == "" {
+		return nil, PackageVersionInvalidArgumentError.Wrap("empty package name")
+	}
+
+	packages, gatheringError := g.queryPackage(packageName)
+	if gatheringError != nil {
+		return nil, gatheringError
+	}
+
+	if operator == "" {
+		packagesList := &entities.FactValueList{Value: []entities.FactValue{}}
+		for _, pkg := range packages {
+			packagesList.AppendValue(pkg.toFactValue())
+		}
+		return packagesList, nil
+	}
+
+	latest := packages[0]
+	for _, pkg := range packages[1:] {
+		if compareInstalledPackages(pkg, latest) > 0 {
+			latest = pkg
+		}
+	}
+
+	result := compareRequestedVersion(latest, requestedVersion)
+
+	switch operator {
+	case ">=":
+		return &entities.FactValueBool{Value: result >= 0}, nil
+	case "<=":
+		return &entities.FactValueBool{Value: result <= 0}, nil
+	case ">":
+		return &entities.FactValueBool{Value: result > 0}, nil
+	case "<":
+		return &entities.FactValueBool{Value: result < 0}, nil
+	case "!=":
+		return &entities.FactValueBool{Value: result != 0}, nil
+	default:
+		return &entities.FactValueBool{Value: result == 0}, nil
+	}
+}
+
+func (g *PackageVersionGatherer) queryPackage(packageName string) ([]installedPackage, *entities.FactGatheringError) {
+	output, err := g.executor.Exec("rpm", "-q", "--qf", packageVersionQueryFormat, packageName)
+	// rpm returns an error code and prints a message in the standard output
+	// when the package is not installed
+	if strings.Contains(string(output), fmt.Sprintf("package %s is not installed", packageName)) {
+		return nil, PackageVersionNotInstalledError.Wrap(packageName)
+	}
+
+	if err != nil {
+		return nil, PackageVersionCommandError.Wrap(packageName)
+	}
+
+	packages := []installedPackage{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		pkg, err := parseInstalledPackage(line)
+		if err != nil {
+			return nil, PackageVersionDecodingError.Wrap(err.Error())
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+func parseInstalledPackage(line string) (installedPackage, error) {
+	fields := strings.Split(strings.TrimSpace(line), "|")
+	if len(fields) != 6 {
+		return installedPackage{}, fmt.Errorf("unexpected rpm output line: %s", line)
+	}
+
+	epoch := 0
+	if fields[1] != rpmNoneValue {
+		var err error
+		epoch, err = strconv.Atoi(fields[1])
+		if err != nil {
+			return installedPackage{}, fmt.Errorf("invalid epoch value: %s", fields[1])
+		}
+	}
+
+	installTime, err := strconv.Atoi(fields[5])
+	if err != nil {
+		return installedPackage{}, fmt.Errorf("invalid install time value: %s", fields[5])
+	}
+
+	return installedPackage{
+		Name:        fields[0],
+		Epoch:       epoch,
+		Version:     fields[2],
+		Release:     fields[3],
+		Arch:        fields[4],
+		InstallTime: installTime,
+	}, nil
+}
+
+func (p installedPackage) toFactValue() entities.FactValue {
+	// version and release are stored as strings on purpose, as values like 2.10
+	// would be converted to numbers otherwise
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"version":      &entities.FactValueString{Value: p.Version},
+		"release":      &entities.FactValueString{Value: p.Release},
+		"epoch":        &entities.FactValueInt{Value: p.Epoch},
+		"arch":         &entities.FactValueString{Value: p.Arch},
+		"install_time": &entities.FactValueInt{Value: p.InstallTime},
+	}}
+}
+
+func compareInstalledPackages(a, b installedPackage) int {
+	if a.Epoch != b.Epoch {
+		if a.Epoch > b.Epoch {
+			return 1
+		}
+		return -1
+	}
+
+	if result := compareRPMVersions(a.Version, b.Version); result != 0 {
+		return result
+	}
+
+	return compareRPMVersions(a.Release, b.Release)
+}
+
+// compareRequestedVersion compares an installed package with a version in the
+// [epoch:]version[-release] format. The epoch defaults to 0 and the release is
+// only compared if it is given
+func compareRequestedVersion(pkg installedPackage, requested string) int {
+	requestedEpoch := 0
+	if epoch, rest, found := strings.Cut(requested, ":"); found {
+		if parsedEpoch, err := strconv.Atoi(epoch); err == nil {
+			requestedEpoch = parsedEpoch
+			requested = rest
+		}
+	}
+
+	// use the installed package release if it is not requested, so they are equal
+	requestedRelease := pkg.Release
+	if index := strings.LastIndex(requested, "-"); index != -1 {
+		requestedRelease = requested[index+1:]
+		requested = requested[:index]
+	}
+
+	return compareInstalledPackages(pkg, installedPackage{
+		Epoch:   requestedEpoch,
+		Version: requested,
+		Release: requestedRelease,
+	})
+}
+
+// compareRPMVersions compares two version strings following the rpmvercmp algorithm.
+// It returns 1 if a is newer than b, -1 if b is newer than a and 0 if they are equal
+func compareRPMVersions(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	isAlnum := func(c byte) bool {
+		return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+	trimSeparators := func(s string) string {
+		for len(s) > 0 && !isAlnum(s[0]) && s[0] != '~' && s[0] != '^' {
+			s = s[1:]
+		}
+		return s
+	}
+
+	one, two := a, b
+	for len(one) > 0 || len(two) > 0 {
+		one = trimSeparators(one)
+		two = trimSeparators(two)
+
+		// the tilde sorts before everything else, even the end of the string
+		if strings.HasPrefix(one, "~") || strings.HasPrefix(two, "~") {
+			if !strings.HasPrefix(one, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(two, "~") {
+				return -1
+			}
+			one, two = one[1:], two[1:]
+			continue
+		}
+
+		// the caret sorts after the end of the string, but before everything else
+		if strings.HasPrefix(one, "^") || strings.HasPrefix(two, "^") {
+			if len(one) == 0 {
+				return -1
+			}
+			if len(two) == 0 {
+				return 1
+			}
+			if !strings.HasPrefix(one, "^") {
+				return 1
+			}
+			if !strings.HasPrefix(two, "^") {
+				return -1
+			}
+			one, two = one[1:], two[1:]
+			continue
+		}
+
+		if len(one) == 0 || len(two) == 0 {
+			break
+		}
+
+		numeric := isDigit(one[0])
+		segmentOne, restOne := splitVersionSegment(one, numeric)
+		segmentTwo, restTwo := splitVersionSegment(two, numeric)
+
+		// segments of different types, numeric ones are considered newer
+		if len(segmentTwo) == 0 {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		if numeric {
+			segmentOne = strings.TrimLeft(segmentOne, "0")
+			segmentTwo = strings.TrimLeft(segmentTwo, "0")
+			if len(segmentOne) != len(segmentTwo) {
+				if len(segmentOne) > len(segmentTwo) {
+					return 1
+				}
+				return -1
+			}
+		}
+
+		if result := strings.Compare(segmentOne, segmentTwo); result != 0 {
+			return result
+		}
+
+		one, two = restOne, restTwo
+	}
+
+	switch {
+	case len(one) == 0 && len(two) == 0:
+		return 0
+	case len(one) == 0:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func splitVersionSegment(version string, numeric bool) (string, string) {
+	index := 0
+	for index < len(version) {
+		c := version[index]
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if (numeric && !isDigit(c)) || (!numeric && !isLetter) {
+			break
+		}
+		index++
+	}
+	return version[:index], version[index:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
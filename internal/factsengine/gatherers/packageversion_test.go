@@ -1,11 +1,10 @@
-package gatherers_test
+package gatherers
 
 import (
 	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
-	"github.com/trento-project/agent/internal/factsengine/gatherers"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
 	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
 )
@@ -24,12 +23,15 @@ func (suite *PackageVersionTestSuite) SetupTest() {
 }
 
 func (suite *PackageVersionTestSuite) TestPackageVersionGather() {
-	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "corosync").Return(
-		[]byte("2.4.5"), nil)
-	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "pacemaker").Return(
-		[]byte("2.0.5+20201202.ba59be712"), nil)
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "corosync").Return(
+		[]byte("corosync|(none)|2.4.5|150300.12.7.1|x86_64|1660000000\n"), nil)
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "pacemaker").Return(
+		[]byte("pacemaker|(none)|2.0.5+20201202.ba59be712|150300.4.21.1|x86_64|1660000001\n"), nil)
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "kernel-default").Return(
+		[]byte("kernel-default|(none)|5.14.21|150400.24.33.2|x86_64|1660000002\n"+
+			"kernel-default|(none)|5.14.21|150400.24.38.1|x86_64|1670000000\n"), nil)
 
-	p := gatherers.NewPackageVersionGatherer(suite.mockExecutor)
+	p := NewPackageVersionGatherer(suite.mockExecutor)
 
 	factRequests := []entities.FactRequest{
 		{
@@ -44,69 +46,292 @@ func (suite *PackageVersionTestSuite) TestPackageVersionGather() {
 			Argument: "pacemaker",
 			CheckID:  "check2",
 		},
+		{
+			Name:     "kernel",
+			Gatherer: "package_version",
+			Argument: "kernel-default",
+			CheckID:  "check3",
+		},
 	}
 
 	factResults, err := p.Gather(factRequests)
 
 	expectedResults := []entities.Fact{
 		{
-			Name:    "corosync",
-			Value:   &entities.FactValueString{Value: "2.4.5"},
+			Name: "corosync",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"version":      &entities.FactValueString{Value: "2.4.5"},
+					"release":      &entities.FactValueString{Value: "150300.12.7.1"},
+					"epoch":        &entities.FactValueInt{Value: 0},
+					"arch":         &entities.FactValueString{Value: "x86_64"},
+					"install_time": &entities.FactValueInt{Value: 1660000000},
+				}},
+			}},
 			CheckID: "check1",
 		},
 		{
-			Name:    "pacemaker",
-			Value:   &entities.FactValueString{Value: "2.0.5+20201202.ba59be712"},
+			Name: "pacemaker",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"version":      &entities.FactValueString{Value: "2.0.5+20201202.ba59be712"},
+					"release":      &entities.FactValueString{Value: "150300.4.21.1"},
+					"epoch":        &entities.FactValueInt{Value: 0},
+					"arch":         &entities.FactValueString{Value: "x86_64"},
+					"install_time": &entities.FactValueInt{Value: 1660000001},
+				}},
+			}},
 			CheckID: "check2",
 		},
+		{
+			Name: "kernel",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"version":      &entities.FactValueString{Value: "5.14.21"},
+					"release":      &entities.FactValueString{Value: "150400.24.33.2"},
+					"epoch":        &entities.FactValueInt{Value: 0},
+					"arch":         &entities.FactValueString{Value: "x86_64"},
+					"install_time": &entities.FactValueInt{Value: 1660000002},
+				}},
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"version":      &entities.FactValueString{Value: "5.14.21"},
+					"release":      &entities.FactValueString{Value: "150400.24.38.1"},
+					"epoch":        &entities.FactValueInt{Value: 0},
+					"arch":         &entities.FactValueString{Value: "x86_64"},
+					"install_time": &entities.FactValueInt{Value: 1670000000},
+				}},
+			}},
+			CheckID: "check3",
+		},
 	}
 
 	suite.NoError(err)
 	suite.ElementsMatch(expectedResults, factResults)
 }
 
-func (suite *PackageVersionTestSuite) TestPackageVersionGatherMissingPackageError() {
-	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "corosync").Return(
-		[]byte("2.4.5"), nil)
-	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "pacemake").Return(
-		[]byte("Error getting version of package: pacemake\n"), errors.New("some error"))
+func (suite *PackageVersionTestSuite) TestPackageVersionGatherComparison() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "pacemaker").Return(
+		[]byte("pacemaker|(none)|2.0.5+20201202.ba59be712|150300.4.21.1|x86_64|1660000001\n"), nil)
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "kernel-default").Return(
+		[]byte("kernel-default|(none)|5.14.21|150400.24.38.1|x86_64|1670000000\n"+
+			"kernel-default|(none)|5.3.18|150300.59.93.1|x86_64|1660000002\n"), nil)
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "sapconf").Return(
+		[]byte("sapconf|1|5.0.5|1.1|noarch|1660000002\n"), nil)
 
-	p := gatherers.NewPackageVersionGatherer(suite.mockExecutor)
+	p := NewPackageVersionGatherer(suite.mockExecutor)
 
 	factRequests := []entities.FactRequest{
 		{
-			Name:     "corosync",
+			Name:     "pacemaker_newer",
 			Gatherer: "package_version",
-			Argument: "corosync",
+			Argument: "pacemaker>=2.0.5",
 			CheckID:  "check1",
 		},
+		{
+			Name:     "pacemaker_older",
+			Gatherer: "package_version",
+			Argument: "pacemaker < 2.0.10",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "pacemaker_release",
+			Gatherer: "package_version",
+			Argument: "pacemaker>2.0.5+20201202.ba59be712-150300.4.20",
+			CheckID:  "check3",
+		},
+		{
+			Name:     "pacemaker_equal",
+			Gatherer: "package_version",
+			Argument: "pacemaker=2.0.5+20201202.ba59be712",
+			CheckID:  "check4",
+		},
+		{
+			Name:     "kernel_latest",
+			Gatherer: "package_version",
+			Argument: "kernel-default>=5.14",
+			CheckID:  "check5",
+		},
+		{
+			Name:     "sapconf_epoch",
+			Gatherer: "package_version",
+			Argument: "sapconf>6.0.0",
+			CheckID:  "check6",
+		},
+		{
+			Name:     "sapconf_explicit_epoch",
+			Gatherer: "package_version",
+			Argument: "sapconf!=1:5.0.5",
+			CheckID:  "check7",
+		},
+		{
+			Name:     "invalid_comparison",
+			Gatherer: "package_version",
+			Argument: "pacemaker=>2.0.5",
+			CheckID:  "check8",
+		},
+	}
+
+	factResults, err := p.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "pacemaker_newer",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check1",
+		},
+		{
+			Name:    "pacemaker_older",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check2",
+		},
+		{
+			Name:    "pacemaker_release",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check3",
+		},
+		{
+			Name:    "pacemaker_equal",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check4",
+		},
+		{
+			Name:    "kernel_latest",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check5",
+		},
+		{
+			Name:    "sapconf_epoch",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check6",
+		},
+		{
+			Name:    "sapconf_explicit_epoch",
+			Value:   &entities.FactValueBool{Value: false},
+			CheckID: "check7",
+		},
+		{
+			Name:  "invalid_comparison",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "invalid package version argument: pacemaker=>2.0.5",
+				Type:    "package-version-invalid-argument",
+			},
+			CheckID: "check8",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *PackageVersionTestSuite) TestPackageVersionGatherErrors() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "pacemake").Return(
+		[]byte("package pacemake is not installed\n"), errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "corosync").Return(
+		nil, errors.New("exec: \"rpm\": executable file not found in $PATH"))
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", packageVersionQueryFormat, "sbd").Return(
+		[]byte("sbd|(none)|1.5.1\n"), nil)
+
+	p := NewPackageVersionGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
 		{
 			Name:     "pacemaker",
 			Gatherer: "package_version",
 			Argument: "pacemake",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "pacemaker_comparison",
+			Gatherer: "package_version",
+			Argument: "pacemake>=2.0.5",
 			CheckID:  "check2",
 		},
+		{
+			Name:     "corosync",
+			Gatherer: "package_version",
+			Argument: "corosync",
+			CheckID:  "check3",
+		},
+		{
+			Name:     "sbd",
+			Gatherer: "package_version",
+			Argument: "sbd",
+			CheckID:  "check4",
+		},
 	}
 
 	factResults, err := p.Gather(factRequests)
 
 	expectedResults := []entities.Fact{
 		{
-			Name:    "corosync",
-			Value:   &entities.FactValueString{Value: "2.4.5"},
+			Name:  "pacemaker",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "package is not installed: pacemake",
+				Type:    "package-version-not-installed",
+			},
 			CheckID: "check1",
 		},
 		{
-			Name:  "pacemaker",
+			Name:  "pacemaker_comparison",
 			Value: nil,
 			Error: &entities.FactGatheringError{
-				Message: "error getting version of package: pacemake",
-				Type:    "package-version-cmd-error",
+				Message: "package is not installed: pacemake",
+				Type:    "package-version-not-installed",
 			},
 			CheckID: "check2",
 		},
+		{
+			Name:  "corosync",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error getting version of package: corosync",
+				Type:    "package-version-cmd-error",
+			},
+			CheckID: "check3",
+		},
+		{
+			Name:  "sbd",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error decoding rpm output: unexpected rpm output line: sbd|(none)|1.5.1",
+				Type:    "package-version-decoding-error",
+			},
+			CheckID: "check4",
+		},
 	}
 
 	suite.NoError(err)
 	suite.ElementsMatch(expectedResults, factResults)
 }
+
+func (suite *PackageVersionTestSuite) TestCompareRPMVersions() {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "2.0", -1},
+		{"2.0.1", "2.0", 1},
+		{"2.0.10", "2.0.9", 1},
+		{"1.0010", "1.9", 1},
+		{"1.05", "1.5", 0},
+		{"1.0a", "1.0", 1},
+		{"2.0a", "2.0b", -1},
+		{"1.0", "1.a", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0^20230101", "1.0", 1},
+		{"1.0^20230101", "1.0.1", -1},
+		{"2.0.5+20201202.ba59be712", "2.0.5", 1},
+		{"5.14.21", "5.3.18", 1},
+		{"1_0", "1.0", 0},
+	}
+
+	for _, tt := range cases {
+		suite.Equal(tt.expected, compareRPMVersions(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+		suite.Equal(-tt.expected, compareRPMVersions(tt.b, tt.a), "%s vs %s", tt.b, tt.a)
+	}
+}
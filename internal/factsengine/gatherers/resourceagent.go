@@ -0,0 +1,243 @@
+package gatherers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/agent/internal/core/cluster/cib"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	ResourceAgentGathererName = "resource_agent"
+	resourceAgentStonithClass = "stonith"
+	// fencing resources accept the pcmk_* parameters handled by the fencer, on top of the agent ones
+	resourceAgentFencerPrefix = "pcmk_"
+)
+
+// nolint:gochecknoglobals
+var (
+	ResourceAgentCibError = entities.FactGatheringError{
+		Type:    "resource-agent-cib-error",
+		Message: "error getting the cluster configuration",
+	}
+
+	ResourceAgentDecodingError = entities.FactGatheringError{
+		Type:    "resource-agent-decoding-error",
+		Message: "error decoding the resource agent information",
+	}
+
+	ResourceAgentInvalidArgumentError = entities.FactGatheringError{
+		Type:    "resource-agent-invalid-argument",
+		Message: "invalid argument, a resource id is expected",
+	}
+
+	ResourceAgentResourceNotFoundError = entities.FactGatheringError{
+		Type:    "resource-agent-resource-not-found",
+		Message: "the requested resource was not found",
+	}
+
+	ResourceAgentMetadataError = entities.FactGatheringError{
+		Type:    "resource-agent-metadata-error",
+		Message: "error getting the resource agent metadata",
+	}
+)
+
+type ResourceAgentGatherer struct {
+	executor utils.CommandExecutor
+}
+
+type resourceAgentMetadata struct {
+	XMLName    xml.Name                 `xml:"resource-agent"`
+	Name       string                   `xml:"name,attr"`
+	Version    string                   `xml:"version,attr"`
+	Parameters []resourceAgentParameter `xml:"parameters>parameter"`
+}
+
+type resourceAgentParameter struct {
+	Name              string    `xml:"name,attr"`
+	Required          string    `xml:"required,attr"`
+	Unique            string    `xml:"unique,attr"`
+	DeprecatedAttr    string    `xml:"deprecated,attr"`
+	DeprecatedElement *struct{} `xml:"deprecated"`
+	Content           struct {
+		Type    string `xml:"type,attr"`
+		Default string `xml:"default,attr"`
+	} `xml:"content"`
+}
+
+// deprecated supports both the OCF 1.1 deprecated element and the older deprecated attribute
+func (p *resourceAgentParameter) deprecated() bool {
+	return p.DeprecatedElement != nil || p.DeprecatedAttr == "1" || p.DeprecatedAttr == "true"
+}
+
+func NewDefaultResourceAgentGatherer() *ResourceAgentGatherer {
+	return NewResourceAgentGatherer(utils.Executor{})
+}
+
+func NewResourceAgentGatherer(executor utils.CommandExecutor) *ResourceAgentGatherer {
+	return &ResourceAgentGatherer{
+		executor: executor,
+	}
+}
+
+// Gather combines the metadata of the resource agent with the instance attributes configured
+// for the resource given as argument. The argument is the resource id, optionally followed by
+// the path to the requested value, like rsc_SAPHana_PRD_HDB00.parameters.PREFER_SITE_TAKEOVER.value
+func (g *ResourceAgentGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", ResourceAgentGathererName)
+
+	cibadmin, err := g.executor.Exec("cibadmin", "--query", "--local")
+	if err != nil {
+		gatheringError := ResourceAgentCibError.Wrap(err.Error())
+		log.Error(gatheringError)
+		return entities.NewFactsGatheredListWithError(factsRequests, gatheringError), nil
+	}
+
+	var root cib.Root
+	if err := xml.Unmarshal(cibadmin, &root); err != nil {
+		gatheringError := ResourceAgentDecodingError.Wrap(err.Error())
+		log.Error(gatheringError)
+		return entities.NewFactsGatheredListWithError(factsRequests, gatheringError), nil
+	}
+
+	// the metadata is requested once per agent, as many resources usually share the same agent
+	metadataCache := make(map[string]*resourceAgentMetadata)
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		if value, err := g.gatherResourceAgent(&root, factReq.Argument, metadataCache); err == nil {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		} else {
+			log.Error(err)
+			fact = entities.NewFactGatheredWithError(factReq, err)
+		}
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", ResourceAgentGathererName)
+	return facts, nil
+}
+
+func (g *ResourceAgentGatherer) gatherResourceAgent(
+	root *cib.Root,
+	argument string,
+	metadataCache map[string]*resourceAgentMetadata,
+) (entities.FactValue, *entities.FactGatheringError) {
+	resourceID, valuePath, _ := strings.Cut(argument, ".")
+	if resourceID == "" {
+		return nil, ResourceAgentInvalidArgumentError.Wrap("empty resource id")
+	}
+
+	primitive, found := root.FindPrimitive(resourceID)
+	if !found {
+		return nil, ResourceAgentResourceNotFoundError.Wrap(resourceID)
+	}
+
+	agent := primitive.Agent()
+	metadata, cached := metadataCache[agent]
+	if !cached {
+		var err error
+		metadata, err = g.loadMetadata(agent)
+		if err != nil {
+			return nil, ResourceAgentMetadataError.Wrap(err.Error())
+		}
+		metadataCache[agent] = metadata
+	}
+
+	resourceAgent := resourceAgentToFactValue(&primitive, metadata)
+	if valuePath == "" {
+		return resourceAgent, nil
+	}
+
+	return resourceAgent.GetValue(valuePath)
+}
+
+func (g *ResourceAgentGatherer) loadMetadata(agent string) (*resourceAgentMetadata, error) {
+	output, err := g.executor.Exec("crm_resource", "--show-metadata", agent)
+	if err != nil {
+		return nil, fmt.Errorf("crm_resource --show-metadata %s: %w", agent, err)
+	}
+
+	var metadata resourceAgentMetadata
+	if err := xml.Unmarshal(output, &metadata); err != nil {
+		return nil, fmt.Errorf("could not parse the %s metadata: %w", agent, err)
+	}
+
+	return &metadata, nil
+}
+
+func resourceAgentToFactValue(primitive *cib.Primitive, metadata *resourceAgentMetadata) *entities.FactValueMap {
+	configured := make(map[string]string)
+	values := &entities.FactValueMap{Value: make(map[string]entities.FactValue)}
+	for _, attribute := range primitive.InstanceAttributes {
+		configured[attribute.Name] = attribute.Value
+		values.Value[attribute.Name] = &entities.FactValueString{Value: attribute.Value}
+	}
+
+	declared := make(map[string]bool)
+	deprecated := []string{}
+	missingRequired := []string{}
+	parameters := &entities.FactValueMap{Value: make(map[string]entities.FactValue)}
+
+	for _, parameter := range metadata.Parameters {
+		declared[parameter.Name] = true
+		value, isConfigured := configured[parameter.Name]
+		if !isConfigured {
+			value = parameter.Content.Default
+		}
+
+		required := parameter.Required == "1"
+		if required && !isConfigured {
+			missingRequired = append(missingRequired, parameter.Name)
+		}
+		if parameter.deprecated() && isConfigured {
+			deprecated = append(deprecated, parameter.Name)
+		}
+
+		parameters.Value[parameter.Name] = &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"type":       &entities.FactValueString{Value: parameter.Content.Type},
+			"default":    &entities.FactValueString{Value: parameter.Content.Default},
+			"required":   &entities.FactValueBool{Value: required},
+			"unique":     &entities.FactValueBool{Value: parameter.Unique == "1"},
+			"deprecated": &entities.FactValueBool{Value: parameter.deprecated()},
+			"configured": &entities.FactValueBool{Value: isConfigured},
+			"value":      &entities.FactValueString{Value: value},
+		}}
+	}
+
+	unknown := []string{}
+	for name := range configured {
+		if declared[name] {
+			continue
+		}
+		if primitive.Class == resourceAgentStonithClass && strings.HasPrefix(name, resourceAgentFencerPrefix) {
+			continue
+		}
+		unknown = append(unknown, name)
+	}
+
+	sort.Strings(deprecated)
+	sort.Strings(missingRequired)
+	sort.Strings(unknown)
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"id":               &entities.FactValueString{Value: primitive.ID},
+		"agent":            &entities.FactValueString{Value: primitive.Agent()},
+		"class":            &entities.FactValueString{Value: primitive.Class},
+		"provider":         &entities.FactValueString{Value: primitive.Provider},
+		"type":             &entities.FactValueString{Value: primitive.Type},
+		"version":          &entities.FactValueString{Value: metadata.Version},
+		"parameters":       parameters,
+		"values":           values,
+		"unknown":          stringsToFactValueList(unknown),
+		"deprecated":       stringsToFactValueList(deprecated),
+		"missing_required": stringsToFactValueList(missingRequired),
+	}}
+}
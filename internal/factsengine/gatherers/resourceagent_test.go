@@ -0,0 +1,293 @@
+package gatherers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+)
+
+type ResourceAgentTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+}
+
+func TestResourceAgentTestSuite(t *testing.T) {
+	suite.Run(t, new(ResourceAgentTestSuite))
+}
+
+func (suite *ResourceAgentTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+}
+
+func resourceAgentParameter(
+	paramType, defaultValue string, required, deprecated, configured bool, value string,
+) entities.FactValue {
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"type":       &entities.FactValueString{Value: paramType},
+		"default":    &entities.FactValueString{Value: defaultValue},
+		"required":   &entities.FactValueBool{Value: required},
+		"unique":     &entities.FactValueBool{Value: false},
+		"deprecated": &entities.FactValueBool{Value: deprecated},
+		"configured": &entities.FactValueBool{Value: configured},
+		"value":      &entities.FactValueString{Value: value},
+	}}
+}
+
+func (suite *ResourceAgentTestSuite) TestResourceAgentGather() {
+	suite.mockExecutor.On("Exec", "cibadmin", "--query", "--local").Return(
+		readFixture("gatherers/cibadmin.xml"), nil)
+	suite.mockExecutor.On("Exec", "crm_resource", "--show-metadata", "ocf:suse:SAPHana").Return(
+		readFixture("gatherers/crm-resource-metadata-SAPHana.xml"), nil).Once()
+
+	c := gatherers.NewResourceAgentGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "saphana",
+			Gatherer: "resource_agent",
+			Argument: "rsc_SAPHana_PRD_HDB00",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "prefer_site_takeover",
+			Gatherer: "resource_agent",
+			Argument: "rsc_SAPHana_PRD_HDB00.parameters.PREFER_SITE_TAKEOVER.value",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "hana_call_timeout",
+			Gatherer: "resource_agent",
+			Argument: "rsc_SAPHana_PRD_HDB00.parameters.HANA_CALL_TIMEOUT.value",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "saphana",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"id":       &entities.FactValueString{Value: "rsc_SAPHana_PRD_HDB00"},
+				"agent":    &entities.FactValueString{Value: "ocf:suse:SAPHana"},
+				"class":    &entities.FactValueString{Value: "ocf"},
+				"provider": &entities.FactValueString{Value: "suse"},
+				"type":     &entities.FactValueString{Value: "SAPHana"},
+				"version":  &entities.FactValueString{Value: "0.162.1"},
+				"parameters": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"SID":                       resourceAgentParameter("string", "", true, false, true, "PRD"),
+					"InstanceNumber":            resourceAgentParameter("string", "", true, false, true, "00"),
+					"PREFER_SITE_TAKEOVER":      resourceAgentParameter("boolean", "yes", false, false, true, "True"),
+					"AUTOMATED_REGISTER":        resourceAgentParameter("boolean", "false", false, false, true, "False"),
+					"DUPLICATE_PRIMARY_TIMEOUT": resourceAgentParameter("string", "7200", false, false, true, "7200"),
+					"HANA_CALL_TIMEOUT":         resourceAgentParameter("string", "60", false, false, false, "60"),
+					"SAPHanaFilter":             resourceAgentParameter("string", "", false, true, false, ""),
+				}},
+				"values": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"SID":                       &entities.FactValueString{Value: "PRD"},
+					"InstanceNumber":            &entities.FactValueString{Value: "00"},
+					"PREFER_SITE_TAKEOVER":      &entities.FactValueString{Value: "True"},
+					"AUTOMATED_REGISTER":        &entities.FactValueString{Value: "False"},
+					"DUPLICATE_PRIMARY_TIMEOUT": &entities.FactValueString{Value: "7200"},
+				}},
+				"unknown":          &entities.FactValueList{Value: []entities.FactValue{}},
+				"deprecated":       &entities.FactValueList{Value: []entities.FactValue{}},
+				"missing_required": &entities.FactValueList{Value: []entities.FactValue{}},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name:    "prefer_site_takeover",
+			Value:   &entities.FactValueString{Value: "True"},
+			CheckID: "check2",
+		},
+		{
+			Name:    "hana_call_timeout",
+			Value:   &entities.FactValueString{Value: "60"},
+			CheckID: "check2",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockExecutor.AssertNumberOfCalls(suite.T(), "Exec", 2)
+}
+
+func (suite *ResourceAgentTestSuite) TestResourceAgentGatherInvalidParameters() {
+	suite.mockExecutor.On("Exec", "cibadmin", "--query", "--local").Return(
+		readFixture("gatherers/cibadmin-resource-agent.xml"), nil)
+	suite.mockExecutor.On("Exec", "crm_resource", "--show-metadata", "ocf:suse:SAPHana").Return(
+		readFixture("gatherers/crm-resource-metadata-SAPHana.xml"), nil)
+	suite.mockExecutor.On("Exec", "crm_resource", "--show-metadata", "stonith:external/sbd").Return(
+		readFixture("gatherers/crm-resource-metadata-sbd.xml"), nil)
+
+	c := gatherers.NewResourceAgentGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "unknown",
+			Gatherer: "resource_agent",
+			Argument: "rsc_SAPHana_QAS_HDB10.unknown",
+		},
+		{
+			Name:     "deprecated",
+			Gatherer: "resource_agent",
+			Argument: "rsc_SAPHana_QAS_HDB10.deprecated",
+		},
+		{
+			Name:     "missing_required",
+			Gatherer: "resource_agent",
+			Argument: "rsc_SAPHana_QAS_HDB10.missing_required",
+		},
+		{
+			Name:     "stonith_unknown",
+			Gatherer: "resource_agent",
+			Argument: "stonith-sbd.unknown",
+		},
+		{
+			Name:     "stonith_deprecated",
+			Gatherer: "resource_agent",
+			Argument: "stonith-sbd.deprecated",
+		},
+		{
+			Name:     "stonith_agent",
+			Gatherer: "resource_agent",
+			Argument: "stonith-sbd.agent",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "unknown",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "PREFER_SITE_TAKOVER"},
+			}},
+		},
+		{
+			Name: "deprecated",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "SAPHanaFilter"},
+			}},
+		},
+		{
+			Name: "missing_required",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "InstanceNumber"},
+			}},
+		},
+		{
+			Name:  "stonith_unknown",
+			Value: &entities.FactValueList{Value: []entities.FactValue{}},
+		},
+		{
+			Name: "stonith_deprecated",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "sbd_device"},
+			}},
+		},
+		{
+			Name:  "stonith_agent",
+			Value: &entities.FactValueString{Value: "stonith:external/sbd"},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *ResourceAgentTestSuite) TestResourceAgentGatherErrors() {
+	suite.mockExecutor.On("Exec", "cibadmin", "--query", "--local").Return(
+		readFixture("gatherers/cibadmin.xml"), nil)
+	suite.mockExecutor.On("Exec", "crm_resource", "--show-metadata", "ocf:heartbeat:IPaddr2").Return(
+		[]byte{}, errors.New("exit status 5"))
+
+	c := gatherers.NewResourceAgentGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "empty",
+			Gatherer: "resource_agent",
+			Argument: "",
+		},
+		{
+			Name:     "not_found",
+			Gatherer: "resource_agent",
+			Argument: "rsc_unknown",
+		},
+		{
+			Name:     "metadata",
+			Gatherer: "resource_agent",
+			Argument: "rsc_ip_PRD_HDB00",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "empty",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "invalid argument, a resource id is expected: empty resource id",
+				Type:    "resource-agent-invalid-argument",
+			},
+		},
+		{
+			Name:  "not_found",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "the requested resource was not found: rsc_unknown",
+				Type:    "resource-agent-resource-not-found",
+			},
+		},
+		{
+			Name:  "metadata",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error getting the resource agent metadata: " +
+					"crm_resource --show-metadata ocf:heartbeat:IPaddr2: exit status 5",
+				Type: "resource-agent-metadata-error",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *ResourceAgentTestSuite) TestResourceAgentGatherCibError() {
+	suite.mockExecutor.On("Exec", "cibadmin", "--query", "--local").Return(
+		nil, errors.New("connection refused"))
+
+	c := gatherers.NewResourceAgentGatherer(suite.mockExecutor)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "saphana",
+			Gatherer: "resource_agent",
+			Argument: "rsc_SAPHana_PRD_HDB00",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "saphana",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error getting the cluster configuration: connection refused",
+				Type:    "resource-agent-cib-error",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
@@ -0,0 +1,278 @@
+package gatherers
+
+import (
+	"fmt"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/agent/internal/core/sapsystem/sapcontrolapi"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+)
+
+const (
+	SapControlGathererName = "sapcontrol"
+)
+
+var (
+	sapControlArgumentCompiled = regexp.MustCompile(`^(\d{2})\.(\w+)$`)
+)
+
+// nolint:gochecknoglobals
+var (
+	SapControlInvalidArgumentError = entities.FactGatheringError{
+		Type:    "sapcontrol-invalid-argument",
+		Message: "invalid argument, the format is <instance number>.<method>",
+	}
+
+	SapControlUnknownMethodError = entities.FactGatheringError{
+		Type:    "sapcontrol-unknown-method",
+		Message: "the requested sapcontrol method is not supported",
+	}
+
+	SapControlRequestError = entities.FactGatheringError{
+		Type:    "sapcontrol-request-error",
+		Message: "error requesting the sapcontrol web service",
+	}
+)
+
+type sapControlMethod func(webService sapcontrolapi.WebService) (entities.FactValue, error)
+
+// nolint:gochecknoglobals
+var sapControlMethods = map[string]sapControlMethod{
+	"GetInstanceProperties": getInstancePropertiesFact,
+	"GetProcessList":        getProcessListFact,
+	"GetSystemInstanceList": getSystemInstanceListFact,
+	"GetVersionInfo":        getVersionInfoFact,
+	"HACheckConfig":         haCheckConfigFact,
+	"HAGetFailoverConfig":   haGetFailoverConfigFact,
+	"EnqGetStatistic":       enqGetStatisticFact,
+}
+
+type SapControlGatherer struct {
+	webService sapcontrolapi.WebServiceConnector
+}
+
+func NewDefaultSapControlGatherer() *SapControlGatherer {
+	return NewSapControlGatherer(sapcontrolapi.WebServiceUnix{})
+}
+
+func NewSapControlGatherer(webService sapcontrolapi.WebServiceConnector) *SapControlGatherer {
+	return &SapControlGatherer{
+		webService: webService,
+	}
+}
+
+// Gather calls the sapcontrol web service method of the instance given in the argument,
+// with the <instance number>.<method> format, like 00.HAGetFailoverConfig
+func (g *SapControlGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", SapControlGathererName)
+
+	webServices := make(map[string]sapcontrolapi.WebService)
+	responses := make(map[string]entities.FactValue)
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		value, gatheringError := g.callMethod(factReq.Argument, webServices, responses)
+		if gatheringError != nil {
+			log.Error(gatheringError)
+			fact = entities.NewFactGatheredWithError(factReq, gatheringError)
+		} else {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		}
+
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", SapControlGathererName)
+	return facts, nil
+}
+
+func (g *SapControlGatherer) callMethod(
+	argument string,
+	webServices map[string]sapcontrolapi.WebService,
+	responses map[string]entities.FactValue,
+) (entities.FactValue, *entities.FactGatheringError) {
+	match := sapControlArgumentCompiled.FindStringSubmatch(argument)
+	if match == nil {
+		return nil, SapControlInvalidArgumentError.Wrap(argument)
+	}
+
+	instanceNumber, methodName := match[1], match[2]
+
+	method, found := sapControlMethods[methodName]
+	if !found {
+		return nil, SapControlUnknownMethodError.Wrap(methodName)
+	}
+
+	if response, found := responses[argument]; found {
+		return response, nil
+	}
+
+	webService, found := webServices[instanceNumber]
+	if !found {
+		webService = g.webService.New(instanceNumber)
+		webServices[instanceNumber] = webService
+	}
+
+	response, err := method(webService)
+	if err != nil {
+		return nil, SapControlRequestError.Wrap(fmt.Sprintf("%s: %s", argument, err))
+	}
+
+	responses[argument] = response
+	return response, nil
+}
+
+func getInstancePropertiesFact(webService sapcontrolapi.WebService) (entities.FactValue, error) {
+	response, err := webService.GetInstanceProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	properties := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, property := range response.Properties {
+		properties.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"property":     &entities.FactValueString{Value: property.Property},
+			"propertytype": &entities.FactValueString{Value: property.Propertytype},
+			"value":        &entities.FactValueString{Value: property.Value},
+		}})
+	}
+
+	return properties, nil
+}
+
+func getProcessListFact(webService sapcontrolapi.WebService) (entities.FactValue, error) {
+	response, err := webService.GetProcessList()
+	if err != nil {
+		return nil, err
+	}
+
+	processes := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, process := range response.Processes {
+		processes.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"name":        &entities.FactValueString{Value: process.Name},
+			"description": &entities.FactValueString{Value: process.Description},
+			"dispstatus":  &entities.FactValueString{Value: string(process.Dispstatus)},
+			"textstatus":  &entities.FactValueString{Value: process.Textstatus},
+			"starttime":   &entities.FactValueString{Value: process.Starttime},
+			"elapsedtime": &entities.FactValueString{Value: process.Elapsedtime},
+			"pid":         &entities.FactValueInt{Value: int(process.Pid)},
+		}})
+	}
+
+	return processes, nil
+}
+
+func getSystemInstanceListFact(webService sapcontrolapi.WebService) (entities.FactValue, error) {
+	response, err := webService.GetSystemInstanceList()
+	if err != nil {
+		return nil, err
+	}
+
+	instances := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, instance := range response.Instances {
+		instances.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"hostname":       &entities.FactValueString{Value: instance.Hostname},
+			"instance_nr":    &entities.FactValueInt{Value: int(instance.InstanceNr)},
+			"http_port":      &entities.FactValueInt{Value: int(instance.HttpPort)},
+			"https_port":     &entities.FactValueInt{Value: int(instance.HttpsPort)},
+			"start_priority": &entities.FactValueString{Value: instance.StartPriority},
+			"features":       &entities.FactValueString{Value: instance.Features},
+			"dispstatus":     &entities.FactValueString{Value: string(instance.Dispstatus)},
+		}})
+	}
+
+	return instances, nil
+}
+
+func getVersionInfoFact(webService sapcontrolapi.WebService) (entities.FactValue, error) {
+	response, err := webService.GetVersionInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, version := range response.InstanceInfo {
+		versions.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"filename":     &entities.FactValueString{Value: version.Filename},
+			"version_info": &entities.FactValueString{Value: version.VersionInfo},
+			"time":         &entities.FactValueString{Value: version.Time},
+		}})
+	}
+
+	return versions, nil
+}
+
+func haCheckConfigFact(webService sapcontrolapi.WebService) (entities.FactValue, error) {
+	response, err := webService.HACheckConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	checks := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, check := range response.Checks {
+		checks.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"state":       &entities.FactValueString{Value: string(check.State)},
+			"category":    &entities.FactValueString{Value: string(check.Category)},
+			"description": &entities.FactValueString{Value: check.Description},
+			"comment":     &entities.FactValueString{Value: check.Comment},
+		}})
+	}
+
+	return checks, nil
+}
+
+func haGetFailoverConfigFact(webService sapcontrolapi.WebService) (entities.FactValue, error) {
+	response, err := webService.HAGetFailoverConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"ha_active":                &entities.FactValueBool{Value: response.HAActive},
+		"ha_product_version":       &entities.FactValueString{Value: response.HAProductVersion},
+		"ha_sap_interface_version": &entities.FactValueString{Value: response.HASAPInterfaceVersion},
+		"ha_documentation":         &entities.FactValueString{Value: response.HADocumentation},
+		"ha_active_node":           &entities.FactValueString{Value: response.HAActiveNode},
+		"ha_nodes":                 stringsToFactValueList(response.HANodes),
+	}}, nil
+}
+
+func enqGetStatisticFact(webService sapcontrolapi.WebService) (entities.FactValue, error) {
+	response, err := webService.EnqGetStatistic()
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"owner_now":            &entities.FactValueInt{Value: int(response.OwnerNow)},
+		"owner_high":           &entities.FactValueInt{Value: int(response.OwnerHigh)},
+		"owner_max":            &entities.FactValueInt{Value: int(response.OwnerMax)},
+		"owner_state":          &entities.FactValueString{Value: string(response.OwnerState)},
+		"arguments_now":        &entities.FactValueInt{Value: int(response.ArgumentsNow)},
+		"arguments_high":       &entities.FactValueInt{Value: int(response.ArgumentsHigh)},
+		"arguments_max":        &entities.FactValueInt{Value: int(response.ArgumentsMax)},
+		"arguments_state":      &entities.FactValueString{Value: string(response.ArgumentsState)},
+		"locks_now":            &entities.FactValueInt{Value: int(response.LocksNow)},
+		"locks_high":           &entities.FactValueInt{Value: int(response.LocksHigh)},
+		"locks_max":            &entities.FactValueInt{Value: int(response.LocksMax)},
+		"locks_state":          &entities.FactValueString{Value: string(response.LocksState)},
+		"enqueue_requests":     &entities.FactValueInt{Value: int(response.EnqueueRequests)},
+		"enqueue_rejects":      &entities.FactValueInt{Value: int(response.EnqueueRejects)},
+		"enqueue_errors":       &entities.FactValueInt{Value: int(response.EnqueueErrors)},
+		"dequeue_requests":     &entities.FactValueInt{Value: int(response.DequeueRequests)},
+		"dequeue_errors":       &entities.FactValueInt{Value: int(response.DequeueErrors)},
+		"dequeue_all_requests": &entities.FactValueInt{Value: int(response.DequeueAllRequests)},
+		"cleanup_requests":     &entities.FactValueInt{Value: int(response.CleanupRequests)},
+		"backup_requests":      &entities.FactValueInt{Value: int(response.BackupRequests)},
+		"reporting_requests":   &entities.FactValueInt{Value: int(response.ReportingRequests)},
+		"compress_requests":    &entities.FactValueInt{Value: int(response.CompressRequests)},
+		"verify_requests":      &entities.FactValueInt{Value: int(response.VerifyRequests)},
+		"lock_time":            &entities.FactValueFloat{Value: response.LockTime},
+		"lock_wait_time":       &entities.FactValueFloat{Value: response.LockWaitTime},
+		"server_time":          &entities.FactValueFloat{Value: response.ServerTime},
+		"replication_state":    &entities.FactValueString{Value: string(response.ReplicationState)},
+	}}, nil
+}
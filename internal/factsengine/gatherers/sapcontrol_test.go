@@ -0,0 +1,270 @@
+package gatherers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/core/sapsystem/sapcontrolapi"
+	sapControlMocks "github.com/trento-project/agent/internal/core/sapsystem/sapcontrolapi/mocks"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+)
+
+type SapControlTestSuite struct {
+	suite.Suite
+	mockConnector  *sapControlMocks.WebServiceConnector
+	mockWebService *sapControlMocks.WebService
+}
+
+func TestSapControlTestSuite(t *testing.T) {
+	suite.Run(t, new(SapControlTestSuite))
+}
+
+func (suite *SapControlTestSuite) SetupTest() {
+	suite.mockConnector = new(sapControlMocks.WebServiceConnector)
+	suite.mockWebService = new(sapControlMocks.WebService)
+}
+
+func (suite *SapControlTestSuite) TestSapControlGather() {
+	suite.mockConnector.On("New", "00").Return(suite.mockWebService).Once()
+	suite.mockWebService.On("HAGetFailoverConfig").Return(&sapcontrolapi.HAGetFailoverConfigResponse{
+		HAActive:              true,
+		HAProductVersion:      "SUSE Linux Enterprise Server for SAP Applications 15 SP4",
+		HASAPInterfaceVersion: "SUSE Linux Enterprise Server for SAP Applications 15 SP4 (sap_suse_cluster_connector 3.1.2)",
+		HADocumentation:       "https://www.suse.com/products/sles-for-sap/resource-library/sap-best-practices/",
+		HAActiveNode:          "sapnode1",
+		HANodes:               []string{"sapnode1", "sapnode2"},
+	}, nil).Once()
+	suite.mockWebService.On("HACheckConfig").Return(&sapcontrolapi.HACheckConfigResponse{
+		Checks: []*sapcontrolapi.HACheck{
+			{
+				State:       sapcontrolapi.HAVerificationStateSuccess,
+				Category:    sapcontrolapi.HACheckCategorySAPConfiguration,
+				Description: "Redundant ABAP instance configuration",
+				Comment:     "2 ABAP instances detected",
+			},
+			{
+				State:       sapcontrolapi.HAVerificationStateError,
+				Category:    sapcontrolapi.HACheckCategoryHAConfiguration,
+				Description: "SAP instance resources in different HA groups",
+				Comment:     "SAP instances are in the same HA group",
+			},
+		},
+	}, nil).Once()
+	suite.mockWebService.On("GetProcessList").Return(&sapcontrolapi.GetProcessListResponse{
+		Processes: []*sapcontrolapi.OSProcess{
+			{
+				Name:        "enserver",
+				Description: "EnqueueServer",
+				Dispstatus:  sapcontrolapi.STATECOLOR_GREEN,
+				Textstatus:  "Running",
+				Starttime:   "2022 10 20 10:00:00",
+				Elapsedtime: "72:00:00",
+				Pid:         30787,
+			},
+		},
+	}, nil).Once()
+
+	c := gatherers.NewSapControlGatherer(suite.mockConnector)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "failover_config",
+			Gatherer: "sapcontrol",
+			Argument: "00.HAGetFailoverConfig",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "failover_config_again",
+			Gatherer: "sapcontrol",
+			Argument: "00.HAGetFailoverConfig",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "ha_checks",
+			Gatherer: "sapcontrol",
+			Argument: "00.HACheckConfig",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "processes",
+			Gatherer: "sapcontrol",
+			Argument: "00.GetProcessList",
+			CheckID:  "check1",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	failoverConfig := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"ha_active": &entities.FactValueBool{Value: true},
+		"ha_product_version": &entities.FactValueString{
+			Value: "SUSE Linux Enterprise Server for SAP Applications 15 SP4",
+		},
+		"ha_sap_interface_version": &entities.FactValueString{
+			Value: "SUSE Linux Enterprise Server for SAP Applications 15 SP4 (sap_suse_cluster_connector 3.1.2)",
+		},
+		"ha_documentation": &entities.FactValueString{
+			Value: "https://www.suse.com/products/sles-for-sap/resource-library/sap-best-practices/",
+		},
+		"ha_active_node": &entities.FactValueString{Value: "sapnode1"},
+		"ha_nodes": &entities.FactValueList{Value: []entities.FactValue{
+			&entities.FactValueString{Value: "sapnode1"},
+			&entities.FactValueString{Value: "sapnode2"},
+		}},
+	}}
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "failover_config",
+			Value:   failoverConfig,
+			CheckID: "check1",
+		},
+		{
+			Name:    "failover_config_again",
+			Value:   failoverConfig,
+			CheckID: "check2",
+		},
+		{
+			Name: "ha_checks",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"state":       &entities.FactValueString{Value: "SAPControl-HA-SUCCESS"},
+					"category":    &entities.FactValueString{Value: "SAPControl-SAP-CONFIGURATION"},
+					"description": &entities.FactValueString{Value: "Redundant ABAP instance configuration"},
+					"comment":     &entities.FactValueString{Value: "2 ABAP instances detected"},
+				}},
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"state":       &entities.FactValueString{Value: "SAPControl-HA-ERROR"},
+					"category":    &entities.FactValueString{Value: "SAPControl-HA-CONFIGURATION"},
+					"description": &entities.FactValueString{Value: "SAP instance resources in different HA groups"},
+					"comment":     &entities.FactValueString{Value: "SAP instances are in the same HA group"},
+				}},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name: "processes",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"name":        &entities.FactValueString{Value: "enserver"},
+					"description": &entities.FactValueString{Value: "EnqueueServer"},
+					"dispstatus":  &entities.FactValueString{Value: "SAPControl-GREEN"},
+					"textstatus":  &entities.FactValueString{Value: "Running"},
+					"starttime":   &entities.FactValueString{Value: "2022 10 20 10:00:00"},
+					"elapsedtime": &entities.FactValueString{Value: "72:00:00"},
+					"pid":         &entities.FactValueInt{Value: 30787},
+				}},
+			}},
+			CheckID: "check1",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockConnector.AssertExpectations(suite.T())
+	suite.mockWebService.AssertExpectations(suite.T())
+}
+
+func (suite *SapControlTestSuite) TestSapControlGatherVersionInfo() {
+	suite.mockConnector.On("New", "10").Return(suite.mockWebService)
+	suite.mockWebService.On("GetVersionInfo").Return(&sapcontrolapi.GetVersionInfoResponse{
+		InstanceInfo: []*sapcontrolapi.InstanceVersionInfo{
+			{
+				Filename:    "/usr/sap/PRD/ASCS10/exe/sapstartsrv",
+				VersionInfo: "789, patch 52, changelist 2113427, optU (Mar 24 2022, 07:55:56), linuxx86_64",
+				Time:        "2022 03 24 07:55:56",
+			},
+		},
+	}, nil)
+
+	c := gatherers.NewSapControlGatherer(suite.mockConnector)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "versions",
+			Gatherer: "sapcontrol",
+			Argument: "10.GetVersionInfo",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "versions",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueMap{Value: map[string]entities.FactValue{
+					"filename": &entities.FactValueString{Value: "/usr/sap/PRD/ASCS10/exe/sapstartsrv"},
+					"version_info": &entities.FactValueString{
+						Value: "789, patch 52, changelist 2113427, optU (Mar 24 2022, 07:55:56), linuxx86_64",
+					},
+					"time": &entities.FactValueString{Value: "2022 03 24 07:55:56"},
+				}},
+			}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *SapControlTestSuite) TestSapControlGatherErrors() {
+	suite.mockConnector.On("New", "01").Return(suite.mockWebService)
+	suite.mockWebService.On("EnqGetStatistic").Return(
+		nil, errors.New("dial unix /tmp/.sapstream50113: connect: no such file or directory"))
+
+	c := gatherers.NewSapControlGatherer(suite.mockConnector)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "invalid",
+			Gatherer: "sapcontrol",
+			Argument: "GetProcessList",
+		},
+		{
+			Name:     "unknown",
+			Gatherer: "sapcontrol",
+			Argument: "01.Stop",
+		},
+		{
+			Name:     "request_error",
+			Gatherer: "sapcontrol",
+			Argument: "01.EnqGetStatistic",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "invalid",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "invalid argument, the format is <instance number>.<method>: GetProcessList",
+				Type:    "sapcontrol-invalid-argument",
+			},
+		},
+		{
+			Name:  "unknown",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "the requested sapcontrol method is not supported: Stop",
+				Type:    "sapcontrol-unknown-method",
+			},
+		},
+		{
+			Name:  "request_error",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error requesting the sapcontrol web service: 01.EnqGetStatistic: " +
+					"dial unix /tmp/.sapstream50113: connect: no such file or directory",
+				Type: "sapcontrol-request-error",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
@@ -0,0 +1,244 @@
+package gatherers
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/internal/core/sapsystem"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+)
+
+const (
+	SapProfileGathererName  = "sap_profile"
+	sapDefaultProfileName   = "DEFAULT.PFL"
+	sapProfileTypeDefault   = "default"
+	sapProfileTypeStart     = "start"
+	sapProfileTypeInstance  = "instance"
+	sapProfileMaxResolution = 10
+)
+
+var (
+	sapSIDCompiled = regexp.MustCompile(`^[A-Z][A-Z0-9]{2}$`)
+	// backup files created by the profile maintenance tools, like PRD_ASCS00_host.1 or DEFAULT.BAK
+	sapProfileBackupCompiled    = regexp.MustCompile(`(?i)(\.\d+|\.bak|\.old|\.sav|~)$`)
+	sapProfileReferenceCompiled = regexp.MustCompile(`\$\(([^$()]+)\)`)
+)
+
+// nolint:gochecknoglobals
+var (
+	SapProfileInvalidArgumentError = entities.FactGatheringError{
+		Type:    "sap-profile-invalid-argument",
+		Message: "invalid argument, a SAP system id is expected",
+	}
+
+	SapProfileFileError = entities.FactGatheringError{
+		Type:    "sap-profile-file-error",
+		Message: "error reading the sap profiles",
+	}
+
+	SapProfileDecodingError = entities.FactGatheringError{
+		Type:    "sap-profile-decoding-error",
+		Message: "error decoding sap profile",
+	}
+)
+
+type SapProfileGatherer struct {
+	fs             afero.Fs
+	sapSystemPaths sapsystem.DiscoveryPaths
+}
+
+type sapProfileEntry struct {
+	key   string
+	value string
+}
+
+func NewDefaultSapProfileGatherer() *SapProfileGatherer {
+	return NewSapProfileGatherer(afero.NewOsFs(), sapsystem.NewDefaultDiscoveryPaths())
+}
+
+// NewSapProfileGatherer returns a gatherer looking for the profiles in the profile folder
+// of the system, found in the same SAP installation roots and overrides as the discovery
+func NewSapProfileGatherer(fs afero.Fs, sapSystemPaths sapsystem.DiscoveryPaths) *SapProfileGatherer {
+	return &SapProfileGatherer{
+		fs:             fs,
+		sapSystemPaths: sapSystemPaths,
+	}
+}
+
+// Gather returns all the profiles of the SAP system given as argument, indexed by file name.
+// Each profile includes the parameters with the $(...) references resolved and the raw parameters
+func (g *SapProfileGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", SapProfileGathererName)
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		if !sapSIDCompiled.MatchString(factReq.Argument) {
+			gatheringError := SapProfileInvalidArgumentError.Wrap(factReq.Argument)
+			log.Error(gatheringError)
+			facts = append(facts, entities.NewFactGatheredWithError(factReq, gatheringError))
+			continue
+		}
+
+		if profiles, err := g.getSystemProfiles(factReq.Argument); err == nil {
+			fact = entities.NewFactGatheredWithRequest(factReq, profiles)
+		} else {
+			log.Error(err)
+			fact = entities.NewFactGatheredWithError(factReq, err)
+		}
+
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", SapProfileGathererName)
+	return facts, nil
+}
+
+func (g *SapProfileGatherer) getSystemProfiles(sid string) (*entities.FactValueMap, *entities.FactGatheringError) {
+	systemPaths, err := sapsystem.FindSystemPaths(g.fs, g.sapSystemPaths, sid)
+	if err != nil {
+		return nil, SapProfileFileError.Wrap(err.Error())
+	}
+	profilesPath := systemPaths.Profile
+
+	files, err := afero.ReadDir(g.fs, profilesPath)
+	if err != nil {
+		return nil, SapProfileFileError.Wrap(err.Error())
+	}
+
+	profiles := make(map[string][]sapProfileEntry)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, ".") || sapProfileBackupCompiled.MatchString(name) {
+			continue
+		}
+
+		content, err := afero.ReadFile(g.fs, path.Join(profilesPath, name))
+		if err != nil {
+			return nil, SapProfileFileError.Wrap(err.Error())
+		}
+
+		entries, err := parseSapProfile(string(content))
+		if err != nil {
+			return nil, SapProfileDecodingError.Wrap(fmt.Sprintf("%s: %s", name, err))
+		}
+		profiles[name] = entries
+	}
+
+	// the instance and start profiles inherit the DEFAULT.PFL parameters
+	defaultParameters := sapProfileEntriesToMap(profiles[sapDefaultProfileName])
+
+	profilesMap := make(map[string]entities.FactValue)
+	for name, entries := range profiles {
+		profilePath := path.Join(profilesPath, name)
+		rawParameters := sapProfileEntriesToMap(entries)
+
+		scope := map[string]string{"SAPSYSTEMNAME": sid}
+		for key, value := range defaultParameters {
+			scope[key] = value
+		}
+		for key, value := range rawParameters {
+			scope[key] = value
+		}
+		scope["_PF"] = profilePath
+
+		parameters := make(map[string]entities.FactValue)
+		rawValues := make(map[string]entities.FactValue)
+		for key, value := range rawParameters {
+			parameters[key] = &entities.FactValueString{Value: resolveSapProfileValue(value, scope)}
+			rawValues[key] = &entities.FactValueString{Value: value}
+		}
+
+		profilesMap[name] = &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"path":           &entities.FactValueString{Value: profilePath},
+			"type":           &entities.FactValueString{Value: sapProfileType(name)},
+			"parameters":     &entities.FactValueMap{Value: parameters},
+			"raw_parameters": &entities.FactValueMap{Value: rawValues},
+		}}
+	}
+
+	return &entities.FactValueMap{Value: profilesMap}, nil
+}
+
+// parseSapProfile parses the SAP profile syntax. Lines starting with # are comments,
+// parameters are set with <name> = <value>, and lines ending with \ continue in the next line
+func parseSapProfile(content string) ([]sapProfileEntry, error) {
+	entries := []sapProfileEntry{}
+	lines := strings.Split(content, "\n")
+
+	for index := 0; index < len(lines); index++ {
+		lineNumber := index + 1
+		line := strings.TrimSpace(lines[index])
+
+		for strings.HasSuffix(line, `\`) && index+1 < len(lines) {
+			index++
+			line = strings.TrimSuffix(line, `\`) + strings.TrimSpace(lines[index])
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		separator := strings.Index(line, "=")
+		if separator == -1 {
+			return nil, fmt.Errorf("invalid entry in line %d: %s", lineNumber, line)
+		}
+
+		key := strings.TrimSpace(line[:separator])
+		if key == "" {
+			return nil, fmt.Errorf("empty parameter name in line %d", lineNumber)
+		}
+
+		entries = append(entries, sapProfileEntry{key: key, value: strings.TrimSpace(line[separator+1:])})
+	}
+
+	return entries, nil
+}
+
+// sapProfileEntriesToMap returns the profile parameters. As in SAP, a parameter set
+// more than once takes the last value
+func sapProfileEntriesToMap(entries []sapProfileEntry) map[string]string {
+	parameters := make(map[string]string)
+	for _, entry := range entries {
+		parameters[entry.key] = entry.value
+	}
+	return parameters
+}
+
+// resolveSapProfileValue replaces the $(name) references with the parameter values,
+// including nested references. Unknown references are kept as they are
+func resolveSapProfileValue(value string, scope map[string]string) string {
+	resolved := value
+	for i := 0; i < sapProfileMaxResolution; i++ {
+		next := sapProfileReferenceCompiled.ReplaceAllStringFunc(resolved, func(reference string) string {
+			name := sapProfileReferenceCompiled.FindStringSubmatch(reference)[1]
+			if referenced, found := scope[name]; found {
+				return referenced
+			}
+			return reference
+		})
+
+		if next == resolved {
+			break
+		}
+		resolved = next
+	}
+
+	return resolved
+}
+
+func sapProfileType(name string) string {
+	switch {
+	case name == sapDefaultProfileName:
+		return sapProfileTypeDefault
+	case strings.HasPrefix(name, "START_"):
+		return sapProfileTypeStart
+	default:
+		return sapProfileTypeInstance
+	}
+}
@@ -0,0 +1,216 @@
+package gatherers_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/core/sapsystem"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+)
+
+type SapProfileTestSuite struct {
+	suite.Suite
+	fs afero.Fs
+}
+
+func TestSapProfileTestSuite(t *testing.T) {
+	suite.Run(t, new(SapProfileTestSuite))
+}
+
+func (suite *SapProfileTestSuite) SetupTest() {
+	suite.fs = afero.NewMemMapFs()
+	profiles := []string{"DEFAULT.PFL", "PRD_ASCS00_sapascs", "PRD_ASCS00_sapascs.1", "START_D01_sapapp"}
+	for _, profile := range profiles {
+		_ = afero.WriteFile(
+			suite.fs, "/usr/sap/PRD/SYS/profile/"+profile, readFixture("gatherers/sap-profiles/"+profile), 0644)
+	}
+}
+
+func (suite *SapProfileTestSuite) TestSapProfileGather() {
+	c := gatherers.NewSapProfileGatherer(suite.fs, sapsystem.NewDefaultDiscoveryPaths())
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "profiles",
+			Gatherer: "sap_profile",
+			Argument: "PRD",
+			CheckID:  "check1",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+	suite.NoError(err)
+	suite.Len(factResults, 1)
+	suite.Nil(factResults[0].Error)
+
+	profiles, ok := factResults[0].Value.(*entities.FactValueMap)
+	suite.True(ok)
+	suite.Len(profiles.Value, 3)
+
+	ascsProfile := "/usr/sap/PRD/SYS/profile/PRD_ASCS00_sapascs"
+	expectedValues := map[string]string{
+		"PRD_ASCS00_sapascs.type":                            "instance",
+		"PRD_ASCS00_sapascs.path":                            ascsProfile,
+		"PRD_ASCS00_sapascs.parameters.service/halib":        "/usr/sap/PRD/ASCS00/exe/saphascriptco.so",
+		"PRD_ASCS00_sapascs.raw_parameters.service/halib":    "$(DIR_EXECUTABLE)/saphascriptco.so",
+		"PRD_ASCS00_sapascs.parameters.SAPSYSTEM":            "00",
+		"PRD_ASCS00_sapascs.parameters.Restart_Program_01":   "local en.sapPRD_ASCS00 pf=" + ascsProfile,
+		"PRD_ASCS00_sapascs.parameters.Start_Program_00":     "local /usr/sap/PRD/ASCS00/exe/msg_server pf=" + ascsProfile,
+		"PRD_ASCS00_sapascs.parameters.DIR_CT_RUN":           "$(DIR_EXE_ROOT)$(DIR_SEP)$(OS_UNICODE)$(DIR_SEP)linuxx86_64",
+		"PRD_ASCS00_sapascs.raw_parameters.enque/table_size": "64000",
+	}
+
+	for valuePath, expected := range expectedValues {
+		value, valueErr := profiles.GetValue(valuePath)
+		suite.Nil(valueErr, valuePath)
+		suite.Equal(&entities.FactValueString{Value: expected}, value, valuePath)
+	}
+
+	// the DEFAULT.PFL parameters are not included in the instance profiles
+	for _, valuePath := range []string{
+		"PRD_ASCS00_sapascs.parameters.enque/serverhost",
+		"PRD_ASCS00_sapascs.parameters.rdisp/msserv",
+	} {
+		_, valueErr := profiles.GetValue(valuePath)
+		suite.NotNil(valueErr, valuePath)
+	}
+
+	startProfile, valueErr := profiles.GetValue("START_D01_sapapp")
+	suite.Nil(valueErr)
+	suite.Equal(&entities.FactValueMap{Value: map[string]entities.FactValue{
+		"path": &entities.FactValueString{Value: "/usr/sap/PRD/SYS/profile/START_D01_sapapp"},
+		"type": &entities.FactValueString{Value: "start"},
+		"parameters": &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"SAPSYSTEM":     &entities.FactValueString{Value: "01"},
+			"INSTANCE_NAME": &entities.FactValueString{Value: "D01"},
+			"Execute_00": &entities.FactValueString{
+				Value: "immediate $(DIR_CT_RUN)/sapcpe$(FT_EXE) pf=/usr/sap/PRD/SYS/profile/START_D01_sapapp",
+			},
+		}},
+		"raw_parameters": &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"SAPSYSTEM":     &entities.FactValueString{Value: "01"},
+			"INSTANCE_NAME": &entities.FactValueString{Value: "D01"},
+			"Execute_00":    &entities.FactValueString{Value: "immediate $(DIR_CT_RUN)/sapcpe$(FT_EXE) pf=$(_PF)"},
+		}},
+	}}, startProfile)
+}
+
+func (suite *SapProfileTestSuite) TestSapProfileGatherDefaultInheritance() {
+	c := gatherers.NewSapProfileGatherer(suite.fs, sapsystem.NewDefaultDiscoveryPaths())
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "profiles",
+			Gatherer: "sap_profile",
+			Argument: "PRD",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+	suite.NoError(err)
+
+	profiles, _ := factResults[0].Value.(*entities.FactValueMap)
+
+	defaultProfile, _ := profiles.Value["DEFAULT.PFL"].(*entities.FactValueMap)
+	suite.Equal(&entities.FactValueString{Value: "default"}, defaultProfile.Value["type"])
+
+	parameters, _ := defaultProfile.Value["parameters"].(*entities.FactValueMap)
+	suite.Equal(&entities.FactValueString{Value: "sapmsPRD"}, parameters.Value["rdisp/msserv"])
+	suite.Equal(&entities.FactValueString{Value: "192.168.140.12"}, parameters.Value["SAPDBHOST"])
+	suite.Equal(
+		&entities.FactValueString{Value: "/usr/sap/PRD/SYS/global/security/rsecssfs/data"},
+		parameters.Value["rsec/ssfs_datapath"])
+
+	// the DEFAULT.PFL parameters are used to resolve the instance profile references
+	profileDir, valueErr := profiles.GetValue("PRD_ASCS00_sapascs.parameters.DIR_PROFILE")
+	suite.Nil(valueErr)
+	suite.Equal(&entities.FactValueString{Value: "/usr/sap/PRD/SYS/global/../profile"}, profileDir)
+}
+
+func (suite *SapProfileTestSuite) TestSapProfileGatherErrors() {
+	_ = afero.WriteFile(
+		suite.fs, "/usr/sap/QAS/SYS/profile/DEFAULT.PFL", readFixture("gatherers/sap-profile-invalid"), 0644)
+
+	c := gatherers.NewSapProfileGatherer(suite.fs, sapsystem.NewDefaultDiscoveryPaths())
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "invalid_sid",
+			Gatherer: "sap_profile",
+			Argument: "prd",
+		},
+		{
+			Name:     "not_found",
+			Gatherer: "sap_profile",
+			Argument: "DEV",
+		},
+		{
+			Name:     "invalid_profile",
+			Gatherer: "sap_profile",
+			Argument: "QAS",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "invalid_sid",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "invalid argument, a SAP system id is expected: prd",
+				Type:    "sap-profile-invalid-argument",
+			},
+		},
+		{
+			Name:  "not_found",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error reading the sap profiles: SAP system DEV not found",
+				Type:    "sap-profile-file-error",
+			},
+		},
+		{
+			Name:  "invalid_profile",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error decoding sap profile: DEFAULT.PFL: invalid entry in line 2: this line is invalid",
+				Type:    "sap-profile-decoding-error",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *SapProfileTestSuite) TestSapProfileGatherOverriddenPaths() {
+	_ = afero.WriteFile(
+		suite.fs, "/data/QAS_inst/profile/DEFAULT.PFL", []byte("SAPSYSTEMNAME = QAS\n"), 0644)
+	_ = suite.fs.MkdirAll("/data/QAS_inst/system", 0755)
+
+	c := gatherers.NewSapProfileGatherer(suite.fs, sapsystem.DiscoveryPaths{
+		Roots: []string{"/usr/sap"},
+		Overrides: map[string]sapsystem.SystemPaths{
+			"QAS": {System: "/data/QAS_inst/system", Profile: "/data/QAS_inst/profile"},
+		},
+	})
+
+	factResults, err := c.Gather([]entities.FactRequest{
+		{
+			Name:     "qas_system_name",
+			Gatherer: "sap_profile",
+			Argument: "QAS",
+		},
+	})
+
+	suite.NoError(err)
+	suite.Len(factResults, 1)
+	suite.Nil(factResults[0].Error)
+
+	profiles, _ := factResults[0].Value.(*entities.FactValueMap)
+	defaultProfile, _ := profiles.Value["DEFAULT.PFL"].(*entities.FactValueMap)
+	suite.Equal(&entities.FactValueString{Value: "/data/QAS_inst/profile/DEFAULT.PFL"}, defaultProfile.Value["path"])
+}
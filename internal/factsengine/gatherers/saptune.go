@@ -0,0 +1,278 @@
+package gatherers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hashicorp/go-envparse"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	SaptuneGathererName = "saptune"
+	// saptune supports the json output format starting from this version
+	saptuneMinJSONVersion = "3.1.0"
+	saptuneToolName       = "saptune"
+	sapconfToolName       = "sapconf"
+	sapconfProfilePath    = "/etc/sysconfig/sapconf"
+)
+
+// nolint:gochecknoglobals
+var (
+	SaptuneNotInstalledError = entities.FactGatheringError{
+		Type:    "saptune-not-installed",
+		Message: "neither saptune nor sapconf are installed",
+	}
+
+	SaptuneVersionUnsupportedError = entities.FactGatheringError{
+		Type:    "saptune-version-not-supported",
+		Message: "currently installed version of saptune is not supported",
+	}
+
+	SaptuneUnknownArgumentError = entities.FactGatheringError{
+		Type:    "saptune-unknown-argument",
+		Message: "the requested argument is not supported",
+	}
+
+	SaptuneCommandError = entities.FactGatheringError{
+		Type:    "saptune-cmd-error",
+		Message: "error executing saptune command",
+	}
+
+	SaptuneDecodingError = entities.FactGatheringError{
+		Type:    "saptune-decoding-error",
+		Message: "error decoding saptune output",
+	}
+
+	SaptuneSapconfProfileError = entities.FactGatheringError{
+		Type:    "saptune-sapconf-profile-error",
+		Message: "error reading the sapconf profile",
+	}
+)
+
+// nolint:gochecknoglobals
+// saptuneArguments maps the supported gatherer arguments with the
+// saptune command arguments used to get the data
+var saptuneArguments = map[string][]string{
+	"status":          {"status"},
+	"solution-verify": {"solution", "verify"},
+	"solution-list":   {"solution", "list"},
+	"note-verify":     {"note", "verify"},
+	"note-list":       {"note", "list"},
+	"staging":         {"staging", "status"},
+}
+
+type SaptuneGatherer struct {
+	executor utils.CommandExecutor
+	fs       afero.Fs
+}
+
+func NewDefaultSaptuneGatherer() *SaptuneGatherer {
+	return NewSaptuneGatherer(utils.Executor{}, afero.NewOsFs())
+}
+
+func NewSaptuneGatherer(executor utils.CommandExecutor, fs afero.Fs) *SaptuneGatherer {
+	return &SaptuneGatherer{
+		executor: executor,
+		fs:       fs,
+	}
+}
+
+func (g *SaptuneGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", SaptuneGathererName)
+
+	tool, version, err := g.detectTuningTool()
+	if err != nil {
+		return facts, err
+	}
+
+	// Cache the executions, as different facts can request the same saptune command
+	cachedOutputs := make(map[string]entities.FactValue)
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+		var value entities.FactValue
+		var gatheringError *entities.FactGatheringError
+
+		if cached, found := cachedOutputs[factReq.Argument]; found {
+			value = cached
+		} else if tool == sapconfToolName {
+			value, gatheringError = g.gatherSapconf(factReq.Argument, version)
+		} else {
+			value, gatheringError = g.gatherSaptune(factReq.Argument)
+		}
+
+		if gatheringError != nil {
+			log.Error(gatheringError)
+			fact = entities.NewFactGatheredWithError(factReq, gatheringError)
+		} else {
+			cachedOutputs[factReq.Argument] = value
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		}
+
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", SaptuneGathererName)
+	return facts, nil
+}
+
+// detectTuningTool returns the tuning tool available in the system, and its version.
+// saptune is preferred, and sapconf is used in older systems where saptune is not installed
+func (g *SaptuneGatherer) detectTuningTool() (string, string, *entities.FactGatheringError) {
+	saptuneVersion, err := g.executor.Exec("rpm", "-q", "--qf", "%{VERSION}", saptuneToolName)
+	if err == nil {
+		version := strings.TrimSpace(string(saptuneVersion))
+		if !isSaptuneVersionSupported(version) {
+			return "", "", SaptuneVersionUnsupportedError.Wrap(version)
+		}
+		return saptuneToolName, version, nil
+	}
+
+	sapconfVersion, err := g.executor.Exec("rpm", "-q", "--qf", "%{VERSION}", sapconfToolName)
+	if err == nil {
+		return sapconfToolName, strings.TrimSpace(string(sapconfVersion)), nil
+	}
+
+	return "", "", &SaptuneNotInstalledError
+}
+
+func (g *SaptuneGatherer) gatherSaptune(argument string) (entities.FactValue, *entities.FactGatheringError) {
+	saptuneArgs, found := saptuneArguments[argument]
+	if !found {
+		return nil, SaptuneUnknownArgumentError.Wrap(argument)
+	}
+
+	args := append([]string{"--format", "json"}, saptuneArgs...)
+	output, commandErr := g.executor.Exec(saptuneToolName, args...)
+	// saptune returns a non zero exit code when the system is not compliant,
+	// but the json output is printed anyway
+	if commandErr != nil && len(output) == 0 {
+		return nil, SaptuneCommandError.Wrap(commandErr.Error())
+	}
+
+	var saptuneOutput struct {
+		Result map[string]interface{} `json:"result"`
+	}
+
+	if err := json.Unmarshal(output, &saptuneOutput); err != nil {
+		return nil, SaptuneDecodingError.Wrap(err.Error())
+	}
+
+	if saptuneOutput.Result == nil {
+		return nil, SaptuneDecodingError.Wrap("result field not found")
+	}
+
+	result, ok := normalizeSaptuneValue(saptuneOutput.Result).(map[string]interface{})
+	if !ok {
+		return nil, SaptuneDecodingError.Wrap("unexpected result type")
+	}
+
+	if argument == "status" {
+		result["tool"] = saptuneToolName
+	}
+
+	value, err := entities.NewFactValue(result)
+	if err != nil {
+		return nil, SaptuneDecodingError.Wrap(err.Error())
+	}
+
+	return value, nil
+}
+
+// gatherSapconf builds a status fact with the sapconf package version, the tuning
+// parameters of the sapconf profile and the sapconf and tuned services states
+func (g *SaptuneGatherer) gatherSapconf(argument, version string) (entities.FactValue, *entities.FactGatheringError) {
+	if argument != "status" {
+		return nil, SaptuneUnknownArgumentError.Wrap(
+			argument + ": only status is available when sapconf is used")
+	}
+
+	profile, gatheringError := g.readSapconfProfile()
+	if gatheringError != nil {
+		return nil, gatheringError
+	}
+
+	services := make(map[string]entities.FactValue)
+	for _, service := range []string{sapconfToolName, "tuned"} {
+		services[service] = &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"enabled": &entities.FactValueString{Value: systemctlState(g.executor, "is-enabled", service)},
+			"active":  &entities.FactValueString{Value: systemctlState(g.executor, "is-active", service)},
+		}}
+	}
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"tool":            &entities.FactValueString{Value: sapconfToolName},
+		"package_version": &entities.FactValueString{Value: version},
+		"profile":         profile,
+		"services":        &entities.FactValueMap{Value: services},
+	}}, nil
+}
+
+// readSapconfProfile returns the tuning parameters of the sapconf sysconfig file, like THP or GOVERNOR
+func (g *SaptuneGatherer) readSapconfProfile() (*entities.FactValueMap, *entities.FactGatheringError) {
+	profileFile, err := g.fs.Open(sapconfProfilePath)
+	if err != nil {
+		return nil, SaptuneSapconfProfileError.Wrap(err.Error())
+	}
+	defer profileFile.Close()
+
+	parameters, err := envparse.Parse(profileFile)
+	if err != nil {
+		return nil, SaptuneSapconfProfileError.Wrap(err.Error())
+	}
+
+	profile := &entities.FactValueMap{Value: make(map[string]entities.FactValue)}
+	for key, value := range parameters {
+		profile.Value[key] = entities.ParseStringToFactValue(value)
+	}
+
+	return profile, nil
+}
+
+// systemctlState returns the output of the requested systemctl query. systemctl exits with
+// a non zero code for disabled or inactive services, so the error is not relevant here
+func systemctlState(executor utils.CommandExecutor, query, service string) string {
+	output, _ := executor.Exec("systemctl", query, service+".service")
+	state := strings.TrimSpace(string(output))
+	if state == "" {
+		return "unknown"
+	}
+	return state
+}
+
+// normalizeSaptuneValue converts the saptune json keys to snake case
+// and removes the null values, as they cannot be represented as FactValue
+func normalizeSaptuneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{})
+		for key, item := range v {
+			if item == nil {
+				continue
+			}
+			normalizedKey := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), " ", "_")
+			normalized[normalizedKey] = normalizeSaptuneValue(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := []interface{}{}
+		for _, item := range v {
+			if item == nil {
+				continue
+			}
+			normalized = append(normalized, normalizeSaptuneValue(item))
+		}
+		return normalized
+	default:
+		return v
+	}
+}
+
+func isSaptuneVersionSupported(version string) bool {
+	return compareRPMVersions(version, saptuneMinJSONVersion) >= 0
+}
@@ -0,0 +1,409 @@
+package gatherers_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+	"github.com/trento-project/agent/test/helpers"
+)
+
+type SaptuneTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+	fs           afero.Fs
+}
+
+func TestSaptuneTestSuite(t *testing.T) {
+	suite.Run(t, new(SaptuneTestSuite))
+}
+
+func (suite *SaptuneTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+	suite.fs = afero.NewMemMapFs()
+}
+
+func readFixture(name string) []byte {
+	fixtureFile, _ := os.Open(helpers.GetFixturePath(name))
+	content, _ := io.ReadAll(fixtureFile)
+	return content
+}
+
+func (suite *SaptuneTestSuite) TestSaptuneGatherStatus() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		[]byte("3.1.0"), nil)
+	suite.mockExecutor.On("Exec", "saptune", "--format", "json", "status").Return(
+		readFixture("gatherers/saptune-status.output"), errors.New("exit status 1"))
+
+	g := gatherers.NewSaptuneGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "saptune_status",
+			Gatherer: "saptune",
+			Argument: "status",
+			CheckID:  "check1",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+
+	noteList := &entities.FactValueList{Value: []entities.FactValue{
+		&entities.FactValueInt{Value: 941735},
+		&entities.FactValueInt{Value: 1771258},
+		&entities.FactValueInt{Value: 1980196},
+	}}
+	notesBySolution := &entities.FactValueList{Value: []entities.FactValue{
+		&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"solution_id": &entities.FactValueString{Value: "HANA"},
+			"note_list":   noteList,
+		}},
+	}}
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "saptune_status",
+			CheckID: "check1",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"tool": &entities.FactValueString{Value: "saptune"},
+				"services": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"saptune": &entities.FactValueList{Value: []entities.FactValue{
+						&entities.FactValueString{Value: "disabled"},
+						&entities.FactValueString{Value: "inactive"},
+					}},
+					"sapconf": &entities.FactValueList{Value: []entities.FactValue{}},
+					"tuned":   &entities.FactValueList{Value: []entities.FactValue{}},
+				}},
+				"systemd_system_state": &entities.FactValueString{Value: "degraded"},
+				"tuning_state":         &entities.FactValueString{Value: "compliant"},
+				"virtualization":       &entities.FactValueString{Value: "kvm"},
+				"configured_version":   &entities.FactValueInt{Value: 3},
+				"package_version":      &entities.FactValueString{Value: "3.1.0"},
+				"solution_enabled": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueString{Value: "HANA"},
+				}},
+				"notes_enabled_by_solution": notesBySolution,
+				"solution_applied": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"solution_id":       &entities.FactValueString{Value: "HANA"},
+						"applied_partially": &entities.FactValueBool{Value: false},
+					}},
+				}},
+				"notes_applied_by_solution":  notesBySolution,
+				"notes_enabled_additionally": &entities.FactValueList{Value: []entities.FactValue{}},
+				"notes_enabled":              noteList,
+				"notes_applied":              noteList,
+				"staging": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"staging_enabled":  &entities.FactValueBool{Value: false},
+					"notes_staged":     &entities.FactValueList{Value: []entities.FactValue{}},
+					"solutions_staged": &entities.FactValueList{Value: []entities.FactValue{}},
+				}},
+				"remember_message": &entities.FactValueString{Value: "This is a reminder"},
+			}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *SaptuneTestSuite) TestSaptuneGatherNoteVerify() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		[]byte("3.2.1"), nil)
+	suite.mockExecutor.On("Exec", "saptune", "--format", "json", "note", "verify").Return(
+		readFixture("gatherers/saptune-note-verify.output"), errors.New("exit status 1"))
+
+	g := gatherers.NewSaptuneGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "saptune_note_verify",
+			Gatherer: "saptune",
+			Argument: "note-verify",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "saptune_note_verify_again",
+			Gatherer: "saptune",
+			Argument: "note-verify",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+
+	expectedValue := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"verifications": &entities.FactValueList{Value: []entities.FactValue{
+			&entities.FactValueMap{Value: map[string]entities.FactValue{
+				"note_id":        &entities.FactValueInt{Value: 1771258},
+				"note_version":   &entities.FactValueInt{Value: 6},
+				"parameter":      &entities.FactValueString{Value: "LIMIT_@dba_hard_nofile"},
+				"compliant":      &entities.FactValueBool{Value: true},
+				"expected_value": &entities.FactValueString{Value: "@dba hard nofile 1048576"},
+				"actual_value":   &entities.FactValueString{Value: "@dba hard nofile 1048576"},
+			}},
+			&entities.FactValueMap{Value: map[string]entities.FactValue{
+				"note_id":        &entities.FactValueInt{Value: 941735},
+				"note_version":   &entities.FactValueInt{Value: 11},
+				"parameter":      &entities.FactValueString{Value: "ShmFileSystemSizeMB"},
+				"compliant":      &entities.FactValueBool{Value: false},
+				"expected_value": &entities.FactValueInt{Value: 25605},
+				"actual_value":   &entities.FactValueInt{Value: 1083},
+				"amendments": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"index":     &entities.FactValueInt{Value: 5},
+						"amendment": &entities.FactValueString{Value: "[5] Expected value does not match."},
+					}},
+				}},
+			}},
+		}},
+		"attentions": &entities.FactValueList{Value: []entities.FactValue{}},
+		"notes_enabled": &entities.FactValueList{Value: []entities.FactValue{
+			&entities.FactValueInt{Value: 941735},
+			&entities.FactValueInt{Value: 1771258},
+		}},
+		"system_compliance": &entities.FactValueBool{Value: false},
+	}}
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "saptune_note_verify",
+			CheckID: "check1",
+			Value:   expectedValue,
+		},
+		{
+			Name:    "saptune_note_verify_again",
+			CheckID: "check2",
+			Value:   expectedValue,
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockExecutor.AssertNumberOfCalls(suite.T(), "Exec", 2)
+}
+
+func (suite *SaptuneTestSuite) TestSaptuneGatherSapconfFallback() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		[]byte("package saptune is not installed"), errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "sapconf").Return(
+		[]byte("5.0.5"), nil)
+	suite.mockExecutor.On("Exec", "systemctl", "is-enabled", "sapconf.service").Return(
+		[]byte("enabled\n"), nil)
+	suite.mockExecutor.On("Exec", "systemctl", "is-active", "sapconf.service").Return(
+		[]byte("active\n"), nil)
+	suite.mockExecutor.On("Exec", "systemctl", "is-enabled", "tuned.service").Return(
+		[]byte("disabled\n"), errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "systemctl", "is-active", "tuned.service").Return(
+		[]byte("inactive\n"), errors.New("exit status 3"))
+	err := afero.WriteFile(suite.fs, "/etc/sysconfig/sapconf", readFixture("gatherers/sapconf.sysconfig"), 0644)
+	suite.NoError(err)
+
+	g := gatherers.NewSaptuneGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "tuning_status",
+			Gatherer: "saptune",
+			Argument: "status",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "tuning_verify",
+			Gatherer: "saptune",
+			Argument: "solution-verify",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "tuning_status",
+			CheckID: "check1",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"tool":            &entities.FactValueString{Value: "sapconf"},
+				"package_version": &entities.FactValueString{Value: "5.0.5"},
+				"profile": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"VSZ_TMPFS_PERCENT": &entities.FactValueInt{Value: 75},
+					"SEMMSL":            &entities.FactValueInt{Value: 32000},
+					"SEMMNS":            &entities.FactValueInt{Value: 1024000000},
+					"SEMOPM":            &entities.FactValueInt{Value: 500},
+					"SEMMNI":            &entities.FactValueInt{Value: 32000},
+					"THP":               &entities.FactValueString{Value: "never"},
+					"KSM":               &entities.FactValueInt{Value: 0},
+					"GOVERNOR":          &entities.FactValueString{Value: "performance"},
+					"PERF_BIAS":         &entities.FactValueString{Value: "performance"},
+					"IO_SCHEDULER":      &entities.FactValueString{Value: "noop none"},
+				}},
+				"services": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"sapconf": &entities.FactValueMap{Value: map[string]entities.FactValue{
+						"enabled": &entities.FactValueString{Value: "enabled"},
+						"active":  &entities.FactValueString{Value: "active"},
+					}},
+					"tuned": &entities.FactValueMap{Value: map[string]entities.FactValue{
+						"enabled": &entities.FactValueString{Value: "disabled"},
+						"active":  &entities.FactValueString{Value: "inactive"},
+					}},
+				}},
+			}},
+		},
+		{
+			Name:    "tuning_verify",
+			CheckID: "check2",
+			Error: &entities.FactGatheringError{
+				Type: "saptune-unknown-argument",
+				Message: "the requested argument is not supported: " +
+					"solution-verify: only status is available when sapconf is used",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *SaptuneTestSuite) TestSaptuneGatherSapconfProfileMissing() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		[]byte("package saptune is not installed"), errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "sapconf").Return(
+		[]byte("5.0.5"), nil)
+
+	g := gatherers.NewSaptuneGatherer(suite.mockExecutor, suite.fs)
+
+	factResults, err := g.Gather([]entities.FactRequest{
+		{
+			Name:     "tuning_status",
+			Gatherer: "saptune",
+			Argument: "status",
+		},
+	})
+
+	suite.NoError(err)
+	suite.Equal([]entities.Fact{
+		{
+			Name: "tuning_status",
+			Error: &entities.FactGatheringError{
+				Type:    "saptune-sapconf-profile-error",
+				Message: "error reading the sapconf profile: open /etc/sysconfig/sapconf: file does not exist",
+			},
+		},
+	}, factResults)
+}
+
+func (suite *SaptuneTestSuite) TestSaptuneGatherNotInstalled() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		nil, errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "sapconf").Return(
+		nil, errors.New("exit status 1"))
+
+	g := gatherers.NewSaptuneGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "saptune_status",
+			Gatherer: "saptune",
+			Argument: "status",
+			CheckID:  "check1",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+
+	suite.EqualError(err, "fact gathering error: saptune-not-installed - "+
+		"neither saptune nor sapconf are installed")
+	suite.Empty(factResults)
+}
+
+func (suite *SaptuneTestSuite) TestSaptuneGatherVersionNotSupported() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		[]byte("3.0.2"), nil)
+
+	g := gatherers.NewSaptuneGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "saptune_status",
+			Gatherer: "saptune",
+			Argument: "status",
+			CheckID:  "check1",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+
+	suite.EqualError(err, "fact gathering error: saptune-version-not-supported - "+
+		"currently installed version of saptune is not supported: 3.0.2")
+	suite.Empty(factResults)
+}
+
+func (suite *SaptuneTestSuite) TestSaptuneGatherErrors() {
+	suite.mockExecutor.On("Exec", "rpm", "-q", "--qf", "%{VERSION}", "saptune").Return(
+		[]byte("3.1.0"), nil)
+	suite.mockExecutor.On("Exec", "saptune", "--format", "json", "solution", "verify").Return(
+		nil, errors.New("exit status 2"))
+	suite.mockExecutor.On("Exec", "saptune", "--format", "json", "staging", "status").Return(
+		[]byte("not a json"), nil)
+
+	g := gatherers.NewSaptuneGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "saptune_solution_verify",
+			Gatherer: "saptune",
+			Argument: "solution-verify",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "saptune_staging",
+			Gatherer: "saptune",
+			Argument: "staging",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "saptune_unknown",
+			Gatherer: "saptune",
+			Argument: "unknown",
+			CheckID:  "check3",
+		},
+	}
+
+	factResults, err := g.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "saptune_solution_verify",
+			CheckID: "check1",
+			Error: &entities.FactGatheringError{
+				Type:    "saptune-cmd-error",
+				Message: "error executing saptune command: exit status 2",
+			},
+		},
+		{
+			Name:    "saptune_staging",
+			CheckID: "check2",
+			Error: &entities.FactGatheringError{
+				Type:    "saptune-decoding-error",
+				Message: "error decoding saptune output: invalid character 'o' in literal null (expecting 'u')",
+			},
+		},
+		{
+			Name:    "saptune_unknown",
+			CheckID: "check3",
+			Error: &entities.FactGatheringError{
+				Type:    "saptune-unknown-argument",
+				Message: "the requested argument is not supported: unknown",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
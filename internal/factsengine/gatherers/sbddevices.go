@@ -0,0 +1,113 @@
+package gatherers
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/trento-project/agent/internal/core/cluster"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	SBDDevicesGathererName = "sbd_devices"
+	sbdNodeStatusClear     = "clear"
+)
+
+// nolint:gochecknoglobals
+var (
+	SBDDevicesConfigFileError = entities.FactGatheringError{
+		Type:    "sbd-devices-config-file-error",
+		Message: "error reading sbd configuration file",
+	}
+)
+
+type SBDDevicesGatherer struct {
+	executor   utils.CommandExecutor
+	sbdPath    string
+	configFile string
+}
+
+func NewDefaultSBDDevicesGatherer() *SBDDevicesGatherer {
+	return NewSBDDevicesGatherer(utils.Executor{}, cluster.SBDPath, cluster.SBDConfigPath)
+}
+
+func NewSBDDevicesGatherer(executor utils.CommandExecutor, sbdPath, configFile string) *SBDDevicesGatherer {
+	return &SBDDevicesGatherer{
+		executor:   executor,
+		sbdPath:    sbdPath,
+		configFile: configFile,
+	}
+}
+
+// Gather returns the header and the slots of the devices configured in SBD_DEVICE, like
+// devices.0.timeouts.msgwait or devices.0.nodes. Diskless sbd setups return an empty devices list
+func (g *SBDDevicesGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", SBDDevicesGathererName)
+
+	conf, err := cluster.LoadSbdConfig(g.configFile)
+	if err != nil {
+		gatheringError := SBDDevicesConfigFileError.Wrap(err.Error())
+		log.Error(gatheringError)
+		return entities.NewFactsGatheredListWithError(factsRequests, gatheringError), nil
+	}
+
+	devices := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, devicePath := range cluster.SBDDevicePaths(conf) {
+		device := cluster.NewSBDDevice(g.executor, g.sbdPath, devicePath)
+		// the device data is partially loaded on errors, the status reports the device health
+		if err := device.LoadDeviceData(); err != nil {
+			log.Warnf("Error getting the %s sbd device data: %s", devicePath, err)
+		}
+		devices.AppendValue(sbdDeviceToFactValue(&device))
+	}
+
+	sbdDevices := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"devices": devices,
+	}}
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		if value, err := sbdDevices.GetValue(factReq.Argument); err == nil {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		} else {
+			log.Error(err)
+			fact = entities.NewFactGatheredWithError(factReq, err)
+		}
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", SBDDevicesGathererName)
+	return facts, nil
+}
+
+func sbdDeviceToFactValue(device *cluster.SBDDevice) entities.FactValue {
+	// a device without nodes, like one sbd list failed to read, is not clear
+	allNodesClear := len(device.List) > 0
+	nodes := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, node := range device.List {
+		nodes.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"slot":   &entities.FactValueInt{Value: node.ID},
+			"name":   &entities.FactValueString{Value: node.Name},
+			"status": &entities.FactValueString{Value: node.Status},
+		}})
+		allNodesClear = allNodesClear && node.Status == sbdNodeStatusClear
+	}
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"device":          &entities.FactValueString{Value: device.Device},
+		"status":          &entities.FactValueString{Value: device.Status},
+		"header_version":  &entities.FactValueString{Value: device.Dump.Header},
+		"uuid":            &entities.FactValueString{Value: device.Dump.UUID},
+		"slots":           &entities.FactValueInt{Value: device.Dump.Slots},
+		"sector_size":     &entities.FactValueInt{Value: device.Dump.SectorSize},
+		"nodes":           nodes,
+		"all_nodes_clear": &entities.FactValueBool{Value: allNodesClear},
+		"timeouts": &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"watchdog": &entities.FactValueInt{Value: device.Dump.TimeoutWatchdog},
+			"allocate": &entities.FactValueInt{Value: device.Dump.TimeoutAllocate},
+			"loop":     &entities.FactValueInt{Value: device.Dump.TimeoutLoop},
+			"msgwait":  &entities.FactValueInt{Value: device.Dump.TimeoutMsgwait},
+		}},
+	}}
+}
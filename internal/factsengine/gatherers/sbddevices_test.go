@@ -0,0 +1,175 @@
+package gatherers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+	"github.com/trento-project/agent/test/helpers"
+)
+
+type SBDDevicesTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+}
+
+func TestSBDDevicesTestSuite(t *testing.T) {
+	suite.Run(t, new(SBDDevicesTestSuite))
+}
+
+func (suite *SBDDevicesTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+}
+
+func (suite *SBDDevicesTestSuite) TestSBDDevicesGather() {
+	suite.mockExecutor.On("Exec", "/usr/sbin/sbd", "-d", "/dev/vdc", "dump").Return(
+		readFixture("gatherers/sbd-dump.output"), nil)
+	suite.mockExecutor.On("Exec", "/usr/sbin/sbd", "-d", "/dev/vdc", "list").Return(
+		readFixture("gatherers/sbd-list.output"), nil)
+	suite.mockExecutor.On("Exec", "/usr/sbin/sbd", "-d", "/dev/vdb", "dump").Return(
+		[]byte("== disk /dev/vdb unreadable!\nsbd failed; please check the logs."), errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "/usr/sbin/sbd", "-d", "/dev/vdb", "list").Return(
+		[]byte("== disk /dev/vdb unreadable!\nsbd failed; please check the logs."), errors.New("exit status 1"))
+
+	c := gatherers.NewSBDDevicesGatherer(
+		suite.mockExecutor, "/usr/sbin/sbd", helpers.GetFixturePath("discovery/cluster/sbd/sbd_config"))
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "vdc",
+			Gatherer: "sbd_devices",
+			Argument: "devices.0",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "vdb_status",
+			Gatherer: "sbd_devices",
+			Argument: "devices.1.status",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "msgwait",
+			Gatherer: "sbd_devices",
+			Argument: "devices.0.timeouts.msgwait",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "vdb_all_nodes_clear",
+			Gatherer: "sbd_devices",
+			Argument: "devices.1.all_nodes_clear",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "vdc",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"device":         &entities.FactValueString{Value: "/dev/vdc"},
+				"status":         &entities.FactValueString{Value: "healthy"},
+				"header_version": &entities.FactValueString{Value: "2.1"},
+				"uuid":           &entities.FactValueString{Value: "541bdcea-16af-44a4-8ab9-6a98602e65ca"},
+				"slots":          &entities.FactValueInt{Value: 255},
+				"sector_size":    &entities.FactValueInt{Value: 512},
+				"nodes": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"slot":   &entities.FactValueInt{Value: 0},
+						"name":   &entities.FactValueString{Value: "hana01"},
+						"status": &entities.FactValueString{Value: "clear"},
+					}},
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"slot":   &entities.FactValueInt{Value: 1},
+						"name":   &entities.FactValueString{Value: "hana02"},
+						"status": &entities.FactValueString{Value: "reset"},
+					}},
+				}},
+				"all_nodes_clear": &entities.FactValueBool{Value: false},
+				"timeouts": &entities.FactValueMap{Value: map[string]entities.FactValue{
+					"watchdog": &entities.FactValueInt{Value: 15},
+					"allocate": &entities.FactValueInt{Value: 2},
+					"loop":     &entities.FactValueInt{Value: 1},
+					"msgwait":  &entities.FactValueInt{Value: 30},
+				}},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name:    "vdb_status",
+			Value:   &entities.FactValueString{Value: "unhealthy"},
+			CheckID: "check1",
+		},
+		{
+			Name:    "msgwait",
+			Value:   &entities.FactValueInt{Value: 30},
+			CheckID: "check2",
+		},
+		{
+			Name:    "vdb_all_nodes_clear",
+			Value:   &entities.FactValueBool{Value: false},
+			CheckID: "check2",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *SBDDevicesTestSuite) TestSBDDevicesGatherDiskless() {
+	c := gatherers.NewSBDDevicesGatherer(
+		suite.mockExecutor, "/usr/sbin/sbd", helpers.GetFixturePath("discovery/cluster/sbd/sbd_config_no_device"))
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "devices",
+			Gatherer: "sbd_devices",
+			Argument: "devices",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "devices",
+			Value: &entities.FactValueList{Value: []entities.FactValue{}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockExecutor.AssertNotCalled(suite.T(), "Exec")
+}
+
+func (suite *SBDDevicesTestSuite) TestSBDDevicesGatherConfigError() {
+	c := gatherers.NewSBDDevicesGatherer(suite.mockExecutor, "/usr/sbin/sbd", "non_existing_sbd_config")
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "devices",
+			Gatherer: "sbd_devices",
+			Argument: "devices",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "devices",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error reading sbd configuration file: could not open sbd config file: " +
+					"open non_existing_sbd_config: no such file or directory",
+				Type: "sbd-devices-config-file-error",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
@@ -3,6 +3,7 @@ package gatherers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/coreos/go-systemd/v22/dbus"
 	log "github.com/sirupsen/logrus"
@@ -10,7 +11,10 @@ import (
 )
 
 const (
-	SystemDGathererName = "systemd"
+	SystemDGathererName    = "systemd"
+	systemdDefaultProperty = "ActiveState"
+	systemdDefaultUnitType = "service"
+	systemdUnitNotFound    = "not-found"
 )
 
 // nolint:gochecknoglobals
@@ -20,15 +24,43 @@ var (
 		Message: "systemd gatherer not initialized properly",
 	}
 
-	SystemDListUnitsError = entities.FactGatheringError{
-		Type:    "systemd-list-units-error",
-		Message: "error getting unit states",
+	SystemDUnitPropertiesError = entities.FactGatheringError{
+		Type:    "systemd-unit-properties-error",
+		Message: "error getting unit properties",
+	}
+
+	SystemDUnitNotFoundError = entities.FactGatheringError{
+		Type:    "systemd-unit-not-found",
+		Message: "requested unit not found",
+	}
+
+	SystemDPropertyNotFoundError = entities.FactGatheringError{
+		Type:    "systemd-property-not-found",
+		Message: "requested property not found",
 	}
 )
 
+// nolint:gochecknoglobals
+// systemdUnitTypes maps the unit name suffixes with the D-Bus interface
+// name which has the unit type specific properties
+var systemdUnitTypes = map[string]string{
+	"service":   "Service",
+	"socket":    "Socket",
+	"target":    "Target",
+	"timer":     "Timer",
+	"mount":     "Mount",
+	"automount": "Automount",
+	"path":      "Path",
+	"slice":     "Slice",
+	"scope":     "Scope",
+	"swap":      "Swap",
+	"device":    "Device",
+}
+
 //go:generate mockery --name=DbusConnector
 type DbusConnector interface {
-	ListUnitsByNamesContext(ctx context.Context, units []string) ([]dbus.UnitStatus, error)
+	GetUnitPropertiesContext(ctx context.Context, unit string) (map[string]interface{}, error)
+	GetUnitTypePropertiesContext(ctx context.Context, unit string, unitType string) (map[string]interface{}, error)
 }
 
 type SystemDGatherer struct {
@@ -36,6 +68,11 @@ type SystemDGatherer struct {
 	initialized    bool
 }
 
+type systemdUnitProperties struct {
+	properties     map[string]interface{}
+	typeProperties map[string]interface{}
+}
+
 func NewDefaultSystemDGatherer() *SystemDGatherer {
 	ctx := context.Background()
 	conn, err := dbus.NewWithContext(ctx)
@@ -57,6 +94,9 @@ func NewSystemDGatherer(conn DbusConnector, initialized bool) *SystemDGatherer {
 	}
 }
 
+// Gather returns the requested unit properties. The argument format is <unit>.<property>,
+// for example pacemaker.ActiveState or sbd.timer.NextElapseUSecRealtime.
+// The unit type defaults to service, and the property to ActiveState
 func (g *SystemDGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
 	facts := []entities.Fact{}
 	log.Infof("Starting systemd state facts gathering process")
@@ -65,30 +105,117 @@ func (g *SystemDGatherer) Gather(factsRequests []entities.FactRequest) ([]entiti
 		return facts, &SystemDNotInitializedError
 	}
 
-	services := []string{}
+	ctx := context.Background()
+	units := make(map[string]*systemdUnitProperties)
+
 	for _, factReq := range factsRequests {
-		services = append(services, completeServiceName(factReq.Argument))
-	}
+		var fact entities.Fact
 
-	ctx := context.Background()
+		unit, unitType, property := parseSystemDArgument(factReq.Argument)
 
-	states, err := g.dbusConnnector.ListUnitsByNamesContext(ctx, services)
-	if err != nil {
-		return facts, SystemDListUnitsError.Wrap(err.Error())
-	}
+		unitProperties, found := units[unit]
+		if !found {
+			unitProperties = &systemdUnitProperties{properties: nil, typeProperties: nil}
+			units[unit] = unitProperties
+		}
 
-	for index, factReq := range factsRequests {
-		if states[index].Name == completeServiceName(factReq.Argument) {
-			state := &entities.FactValueString{Value: states[index].ActiveState}
-			fact := entities.NewFactGatheredWithRequest(factReq, state)
-			facts = append(facts, fact)
+		value, gatheringError := g.getUnitProperty(ctx, unitProperties, unit, unitType, property)
+		if gatheringError != nil {
+			log.Error(gatheringError)
+			fact = entities.NewFactGatheredWithError(factReq, gatheringError)
+		} else {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
 		}
+
+		facts = append(facts, fact)
 	}
 
 	log.Infof("Requested systemd state facts gathered")
 	return facts, nil
 }
 
-func completeServiceName(service string) string {
-	return fmt.Sprintf("%s.service", service)
+// getUnitProperty looks for the property in the generic unit properties first, and in the
+// unit type specific properties afterwards. The fetched properties are stored to be reused
+func (g *SystemDGatherer) getUnitProperty(
+	ctx context.Context,
+	unitProperties *systemdUnitProperties,
+	unit, unitType, property string,
+) (entities.FactValue, *entities.FactGatheringError) {
+	if unitProperties.properties == nil {
+		properties, err := g.dbusConnnector.GetUnitPropertiesContext(ctx, unit)
+		if err != nil {
+			return nil, SystemDUnitPropertiesError.Wrap(fmt.Sprintf("%s: %s", unit, err))
+		}
+		unitProperties.properties = properties
+	}
+
+	if loadState, ok := unitProperties.properties["LoadState"]; !ok || loadState == systemdUnitNotFound {
+		return nil, SystemDUnitNotFoundError.Wrap(unit)
+	}
+
+	if value, found := unitProperties.properties[property]; found {
+		return systemdValueToFactValue(value), nil
+	}
+
+	if unitProperties.typeProperties == nil {
+		typeProperties, err := g.dbusConnnector.GetUnitTypePropertiesContext(ctx, unit, unitType)
+		if err != nil {
+			return nil, SystemDUnitPropertiesError.Wrap(fmt.Sprintf("%s: %s", unit, err))
+		}
+		unitProperties.typeProperties = typeProperties
+	}
+
+	if value, found := unitProperties.typeProperties[property]; found {
+		return systemdValueToFactValue(value), nil
+	}
+
+	return nil, SystemDPropertyNotFoundError.Wrap(fmt.Sprintf("%s.%s", unit, property))
+}
+
+// parseSystemDArgument returns the full unit name, the D-Bus unit type and the requested property
+func parseSystemDArgument(argument string) (string, string, string) {
+	unit := argument
+	property := systemdDefaultProperty
+
+	if index := strings.LastIndex(argument, "."); index != -1 {
+		if _, isUnitType := systemdUnitTypes[argument[index+1:]]; !isUnitType {
+			unit = argument[:index]
+			property = argument[index+1:]
+		}
+	}
+
+	unitType := systemdDefaultUnitType
+	if index := strings.LastIndex(unit, "."); index != -1 && systemdUnitTypes[unit[index+1:]] != "" {
+		unitType = unit[index+1:]
+	} else {
+		unit = fmt.Sprintf("%s.%s", unit, systemdDefaultUnitType)
+	}
+
+	return unit, systemdUnitTypes[unitType], property
+}
+
+// systemdValueToFactValue converts the D-Bus property values to FactValue.
+// Complex D-Bus types, like structs, are represented as strings
+func systemdValueToFactValue(value interface{}) entities.FactValue {
+	switch v := value.(type) {
+	case string:
+		// strings are not parsed, to keep values like "0" or "yes" as strings
+		return &entities.FactValueString{Value: v}
+	case []string:
+		return stringsToFactValueList(v)
+	case bool:
+		return &entities.FactValueBool{Value: v}
+	case byte, int16, uint16, int32, uint32, int64, int:
+		return entities.ParseStringToFactValue(fmt.Sprint(v))
+	case uint64:
+		// systemd uses the max uint64 value as infinity, which doesn't fit in an int
+		if v > uint64(^uint(0)>>1) {
+			return &entities.FactValueString{Value: "infinity"}
+		}
+		return &entities.FactValueInt{Value: int(v)}
+	case float64:
+		return &entities.FactValueFloat{Value: v}
+	default:
+		return &entities.FactValueString{Value: fmt.Sprint(v)}
+	}
 }
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/coreos/go-systemd/v22/dbus"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	mocks "github.com/trento-project/agent/internal/factsengine/gatherers/mocks"
@@ -24,19 +23,36 @@ func TestSystemDTestSuite(t *testing.T) {
 func (suite *SystemDTestSuite) TestSystemDGather() {
 	mockConnector := new(mocks.DbusConnector)
 
-	units := []dbus.UnitStatus{
-		{
-			Name:        "corosync.service",
-			ActiveState: "active",
-		},
-		{
-			Name:        "pacemaker.service",
-			ActiveState: "inactive",
-		},
-	}
-
-	mockConnector.On("ListUnitsByNamesContext", mock.Anything, []string{"corosync.service", "pacemaker.service"}).Return(
-		units, nil)
+	mockConnector.On("GetUnitPropertiesContext", mock.Anything, "corosync.service").Return(
+		map[string]interface{}{
+			"LoadState":     "loaded",
+			"ActiveState":   "active",
+			"UnitFileState": "enabled",
+		}, nil).Once()
+	mockConnector.On("GetUnitPropertiesContext", mock.Anything, "pacemaker.service").Return(
+		map[string]interface{}{
+			"LoadState":     "loaded",
+			"ActiveState":   "inactive",
+			"UnitFileState": "disabled",
+			"After":         []string{"corosync.service", "network.target"},
+			"CanStart":      true,
+		}, nil).Once()
+	mockConnector.On("GetUnitTypePropertiesContext", mock.Anything, "pacemaker.service", "Service").Return(
+		map[string]interface{}{
+			"Restart":         "on-failure",
+			"MainPID":         uint32(1234),
+			"TimeoutStopUSec": uint64(1800000000),
+			"LimitNOFILE":     ^uint64(0),
+		}, nil).Once()
+	mockConnector.On("GetUnitPropertiesContext", mock.Anything, "sbd.timer").Return(
+		map[string]interface{}{
+			"LoadState":   "loaded",
+			"ActiveState": "active",
+		}, nil).Once()
+	mockConnector.On("GetUnitTypePropertiesContext", mock.Anything, "sbd.timer", "Timer").Return(
+		map[string]interface{}{
+			"Persistent": false,
+		}, nil).Once()
 
 	s := gatherers.NewSystemDGatherer(mockConnector, true)
 
@@ -47,12 +63,66 @@ func (suite *SystemDTestSuite) TestSystemDGather() {
 			Argument: "corosync",
 			CheckID:  "check1",
 		},
+		{
+			Name:     "corosync_unit_file_state",
+			Gatherer: "systemd",
+			Argument: "corosync.UnitFileState",
+			CheckID:  "check1",
+		},
 		{
 			Name:     "pacemaker",
 			Gatherer: "systemd",
-			Argument: "pacemaker",
+			Argument: "pacemaker.service.ActiveState",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "pacemaker_after",
+			Gatherer: "systemd",
+			Argument: "pacemaker.After",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "pacemaker_can_start",
+			Gatherer: "systemd",
+			Argument: "pacemaker.CanStart",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "pacemaker_restart",
+			Gatherer: "systemd",
+			Argument: "pacemaker.Restart",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "pacemaker_main_pid",
+			Gatherer: "systemd",
+			Argument: "pacemaker.MainPID",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "pacemaker_timeout",
+			Gatherer: "systemd",
+			Argument: "pacemaker.TimeoutStopUSec",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "pacemaker_limit_nofile",
+			Gatherer: "systemd",
+			Argument: "pacemaker.LimitNOFILE",
 			CheckID:  "check2",
 		},
+		{
+			Name:     "sbd_timer",
+			Gatherer: "systemd",
+			Argument: "sbd.timer",
+			CheckID:  "check3",
+		},
+		{
+			Name:     "sbd_timer_persistent",
+			Gatherer: "systemd",
+			Argument: "sbd.timer.Persistent",
+			CheckID:  "check3",
+		},
 	}
 
 	factResults, err := s.Gather(factRequests)
@@ -63,15 +133,64 @@ func (suite *SystemDTestSuite) TestSystemDGather() {
 			Value:   &entities.FactValueString{Value: "active"},
 			CheckID: "check1",
 		},
+		{
+			Name:    "corosync_unit_file_state",
+			Value:   &entities.FactValueString{Value: "enabled"},
+			CheckID: "check1",
+		},
 		{
 			Name:    "pacemaker",
 			Value:   &entities.FactValueString{Value: "inactive"},
 			CheckID: "check2",
 		},
+		{
+			Name: "pacemaker_after",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "corosync.service"},
+				&entities.FactValueString{Value: "network.target"},
+			}},
+			CheckID: "check2",
+		},
+		{
+			Name:    "pacemaker_can_start",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check2",
+		},
+		{
+			Name:    "pacemaker_restart",
+			Value:   &entities.FactValueString{Value: "on-failure"},
+			CheckID: "check2",
+		},
+		{
+			Name:    "pacemaker_main_pid",
+			Value:   &entities.FactValueInt{Value: 1234},
+			CheckID: "check2",
+		},
+		{
+			Name:    "pacemaker_timeout",
+			Value:   &entities.FactValueInt{Value: 1800000000},
+			CheckID: "check2",
+		},
+		{
+			Name:    "pacemaker_limit_nofile",
+			Value:   &entities.FactValueString{Value: "infinity"},
+			CheckID: "check2",
+		},
+		{
+			Name:    "sbd_timer",
+			Value:   &entities.FactValueString{Value: "active"},
+			CheckID: "check3",
+		},
+		{
+			Name:    "sbd_timer_persistent",
+			Value:   &entities.FactValueBool{Value: false},
+			CheckID: "check3",
+		},
 	}
 
 	suite.NoError(err)
 	suite.ElementsMatch(expectedResults, factResults)
+	mockConnector.AssertExpectations(suite.T())
 }
 
 func (suite *SystemDTestSuite) TestSystemDGatherNotInitialized() {
@@ -100,11 +219,25 @@ func (suite *SystemDTestSuite) TestSystemDGatherNotInitialized() {
 		"systemd gatherer not initialized properly")
 }
 
-func (suite *SystemDTestSuite) TestSystemDGatherError() {
+func (suite *SystemDTestSuite) TestSystemDGatherErrors() {
 	mockConnector := new(mocks.DbusConnector)
 
-	mockConnector.On("ListUnitsByNamesContext", mock.Anything, mock.Anything).Return(
-		nil, errors.New("error listing"))
+	mockConnector.On("GetUnitPropertiesContext", mock.Anything, "corosync.service").Return(
+		nil, errors.New("error getting properties"))
+	mockConnector.On("GetUnitPropertiesContext", mock.Anything, "unknown.service").Return(
+		map[string]interface{}{
+			"LoadState":   "not-found",
+			"ActiveState": "inactive",
+		}, nil)
+	mockConnector.On("GetUnitPropertiesContext", mock.Anything, "pacemaker.service").Return(
+		map[string]interface{}{
+			"LoadState":   "loaded",
+			"ActiveState": "active",
+		}, nil)
+	mockConnector.On("GetUnitTypePropertiesContext", mock.Anything, "pacemaker.service", "Service").Return(
+		map[string]interface{}{
+			"Restart": "on-failure",
+		}, nil)
 
 	s := gatherers.NewSystemDGatherer(mockConnector, true)
 
@@ -116,15 +249,48 @@ func (suite *SystemDTestSuite) TestSystemDGatherError() {
 			CheckID:  "check1",
 		},
 		{
-			Name:     "pacemaker",
+			Name:     "unknown",
 			Gatherer: "systemd",
-			Argument: "pacemaker",
+			Argument: "unknown",
 			CheckID:  "check2",
 		},
+		{
+			Name:     "pacemaker_unknown_property",
+			Gatherer: "systemd",
+			Argument: "pacemaker.Unknown",
+			CheckID:  "check3",
+		},
 	}
 
-	_, err := s.Gather(factRequests)
+	factResults, err := s.Gather(factRequests)
 
-	suite.EqualError(err, "fact gathering error: systemd-list-units-error - "+
-		"error getting unit states: error listing")
+	expectedResults := []entities.Fact{
+		{
+			Name: "corosync",
+			Error: &entities.FactGatheringError{
+				Type:    "systemd-unit-properties-error",
+				Message: "error getting unit properties: corosync.service: error getting properties",
+			},
+			CheckID: "check1",
+		},
+		{
+			Name: "unknown",
+			Error: &entities.FactGatheringError{
+				Type:    "systemd-unit-not-found",
+				Message: "requested unit not found: unknown.service",
+			},
+			CheckID: "check2",
+		},
+		{
+			Name: "pacemaker_unknown_property",
+			Error: &entities.FactGatheringError{
+				Type:    "systemd-property-not-found",
+				Message: "requested property not found: pacemaker.service.Unknown",
+			},
+			CheckID: "check3",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
 }
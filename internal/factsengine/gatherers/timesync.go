@@ -0,0 +1,386 @@
+package gatherers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	TimeSyncGathererName = "time_sync"
+	ChronyConfPath       = "/etc/chrony.conf"
+	NTPConfPath          = "/etc/ntp.conf"
+	chronyServiceName    = "chronyd"
+	ntpServiceName       = "ntpd"
+	noTimeServiceName    = "none"
+	// reference id used by chrony when it is not synchronized
+	chronyUnsyncedReferenceID = "00000000"
+	chronyNotSynchronised     = "Not synchronised"
+	chronyTrackingFields      = 14
+	chronySourcesFields       = 10
+	ntpqPeersFields           = 10
+	ntpqSystemPeerTally       = "*"
+)
+
+// nolint:gochecknoglobals
+var (
+	TimeSyncCommandError = entities.FactGatheringError{
+		Type:    "time-sync-command-error",
+		Message: "error executing the time synchronization command",
+	}
+
+	TimeSyncFileError = entities.FactGatheringError{
+		Type:    "time-sync-file-error",
+		Message: "error reading the time synchronization configuration",
+	}
+
+	TimeSyncDecodingError = entities.FactGatheringError{
+		Type:    "time-sync-decoding-error",
+		Message: "error decoding the time synchronization state",
+	}
+)
+
+type TimeSyncGatherer struct {
+	executor utils.CommandExecutor
+	fs       afero.Fs
+}
+
+type timeServer struct {
+	Type    string
+	Address string
+	Options []string
+}
+
+func NewDefaultTimeSyncGatherer() *TimeSyncGatherer {
+	return NewTimeSyncGatherer(utils.Executor{}, afero.NewOsFs())
+}
+
+func NewTimeSyncGatherer(executor utils.CommandExecutor, fs afero.Fs) *TimeSyncGatherer {
+	return &TimeSyncGatherer{
+		executor: executor,
+		fs:       fs,
+	}
+}
+
+// Gather reports the active time synchronization service, chronyd or ntpd, with its sync state,
+// offset in seconds, stratum, sources and configured servers. The argument is the path to the
+// requested value, like synchronized or configured_servers.0.address
+func (g *TimeSyncGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", TimeSyncGathererName)
+
+	var timeSync *entities.FactValueMap
+	var gatheringError *entities.FactGatheringError
+
+	switch {
+	case systemctlState(g.executor, "is-active", chronyServiceName) == "active":
+		timeSync, gatheringError = g.gatherChrony()
+	case systemctlState(g.executor, "is-active", ntpServiceName) == "active":
+		timeSync, gatheringError = g.gatherNTP()
+	default:
+		timeSync = &entities.FactValueMap{Value: map[string]entities.FactValue{
+			"service":            &entities.FactValueString{Value: noTimeServiceName},
+			"synchronized":       &entities.FactValueBool{Value: false},
+			"sources":            &entities.FactValueList{Value: []entities.FactValue{}},
+			"configured_servers": &entities.FactValueList{Value: []entities.FactValue{}},
+		}}
+	}
+
+	if gatheringError != nil {
+		log.Error(gatheringError)
+		return entities.NewFactsGatheredListWithError(factsRequests, gatheringError), nil
+	}
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		if value, err := timeSync.GetValue(factReq.Argument); err == nil {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		} else {
+			log.Error(err)
+			fact = entities.NewFactGatheredWithError(factReq, err)
+		}
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", TimeSyncGathererName)
+	return facts, nil
+}
+
+func (g *TimeSyncGatherer) gatherChrony() (*entities.FactValueMap, *entities.FactGatheringError) {
+	tracking, err := g.executor.Exec("chronyc", "-c", "tracking")
+	if err != nil {
+		return nil, TimeSyncCommandError.Wrap(fmt.Sprintf("chronyc tracking: %s", err))
+	}
+
+	timeSync, err := parseChronyTracking(tracking)
+	if err != nil {
+		return nil, TimeSyncDecodingError.Wrap(err.Error())
+	}
+
+	sources, err := g.executor.Exec("chronyc", "-c", "sources")
+	if err != nil {
+		return nil, TimeSyncCommandError.Wrap(fmt.Sprintf("chronyc sources: %s", err))
+	}
+
+	timeSync.Value["sources"], err = parseChronySources(sources)
+	if err != nil {
+		return nil, TimeSyncDecodingError.Wrap(err.Error())
+	}
+
+	servers, err := readTimeServers(g.fs, ChronyConfPath)
+	if err != nil {
+		return nil, TimeSyncFileError.Wrap(err.Error())
+	}
+
+	timeSync.Value["service"] = &entities.FactValueString{Value: chronyServiceName}
+	timeSync.Value["configured_servers"] = timeServersToFactValue(servers)
+
+	return timeSync, nil
+}
+
+// parseChronyTracking parses the chronyc -c tracking output, a single csv line with the fields:
+// reference id, reference name, stratum, reference time, system time offset, last offset,
+// rms offset, frequency, residual frequency, skew, root delay, root dispersion, update interval, leap status
+func parseChronyTracking(output []byte) (*entities.FactValueMap, error) {
+	records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) != 1 || len(records[0]) != chronyTrackingFields {
+		return nil, fmt.Errorf("unexpected chronyc tracking output: %s", strings.TrimSpace(string(output)))
+	}
+	fields := records[0]
+
+	stratum, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid stratum: %s", fields[2])
+	}
+
+	floatFields := map[string]int{
+		"offset":          4,
+		"last_offset":     5,
+		"rms_offset":      6,
+		"frequency":       7,
+		"root_delay":      10,
+		"root_dispersion": 11,
+	}
+
+	tracking := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"reference_id": &entities.FactValueString{Value: fields[0]},
+		"reference":    &entities.FactValueString{Value: fields[1]},
+		"stratum":      &entities.FactValueInt{Value: stratum},
+		"leap_status":  &entities.FactValueString{Value: fields[13]},
+		"synchronized": &entities.FactValueBool{
+			Value: fields[0] != chronyUnsyncedReferenceID && fields[13] != chronyNotSynchronised,
+		},
+	}}
+
+	for key, index := range floatFields {
+		value, err := strconv.ParseFloat(fields[index], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", key, fields[index])
+		}
+		tracking.Value[key] = &entities.FactValueFloat{Value: value}
+	}
+
+	return tracking, nil
+}
+
+// parseChronySources parses the chronyc -c sources output, with one csv line per source and the fields:
+// mode, state, name, stratum, poll, reach, last rx, adjusted offset, measured offset, error
+func parseChronySources(output []byte) (*entities.FactValueList, error) {
+	records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, fields := range records {
+		if len(fields) != chronySourcesFields {
+			return nil, fmt.Errorf("unexpected chronyc sources line: %s", strings.Join(fields, ","))
+		}
+
+		stratum, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid stratum: %s", fields[3])
+		}
+
+		offset, err := strconv.ParseFloat(fields[7], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset: %s", fields[7])
+		}
+
+		sources.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"mode":     &entities.FactValueString{Value: fields[0]},
+			"state":    &entities.FactValueString{Value: fields[1]},
+			"address":  &entities.FactValueString{Value: fields[2]},
+			"stratum":  &entities.FactValueInt{Value: stratum},
+			"reach":    &entities.FactValueString{Value: fields[5]},
+			"offset":   &entities.FactValueFloat{Value: offset},
+			"selected": &entities.FactValueBool{Value: fields[1] == "*"},
+		}})
+	}
+
+	return sources, nil
+}
+
+func (g *TimeSyncGatherer) gatherNTP() (*entities.FactValueMap, *entities.FactGatheringError) {
+	peers, err := g.executor.Exec("ntpq", "-pn")
+	if err != nil {
+		return nil, TimeSyncCommandError.Wrap(fmt.Sprintf("ntpq: %s", err))
+	}
+
+	timeSync, err := parseNTPPeers(peers)
+	if err != nil {
+		return nil, TimeSyncDecodingError.Wrap(err.Error())
+	}
+
+	servers, err := readTimeServers(g.fs, NTPConfPath)
+	if err != nil {
+		return nil, TimeSyncFileError.Wrap(err.Error())
+	}
+
+	timeSync.Value["service"] = &entities.FactValueString{Value: ntpServiceName}
+	timeSync.Value["configured_servers"] = timeServersToFactValue(servers)
+
+	return timeSync, nil
+}
+
+// parseNTPPeers parses the ntpq -pn output, with the fields remote, refid, st, t, when, poll, reach,
+// delay, offset and jitter after the tally code. The state is taken from the system peer, marked with *.
+// ntpq reports the offsets in milliseconds, they are converted to seconds like in chrony
+func parseNTPPeers(output []byte) (*entities.FactValueMap, error) {
+	timeSync := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"synchronized": &entities.FactValueBool{Value: false},
+	}}
+	sources := &entities.FactValueList{Value: []entities.FactValue{}}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 2 || strings.HasPrefix(strings.TrimSpace(line), "remote") || strings.HasPrefix(line, "=") {
+			continue
+		}
+
+		// the first character is the peer tally code
+		tally := strings.TrimSpace(line[:1])
+		fields := strings.Fields(line[1:])
+		if len(fields) != ntpqPeersFields {
+			return nil, fmt.Errorf("unexpected ntpq line: %s", line)
+		}
+
+		stratum, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid stratum: %s", fields[2])
+		}
+
+		// parsing the milliseconds with the exponent avoids the rounding errors of a division
+		offset, err := strconv.ParseFloat(fields[8]+"e-3", 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset: %s", fields[8])
+		}
+
+		sources.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"mode":     &entities.FactValueString{Value: fields[3]},
+			"state":    &entities.FactValueString{Value: tally},
+			"address":  &entities.FactValueString{Value: fields[0]},
+			"stratum":  &entities.FactValueInt{Value: stratum},
+			"reach":    &entities.FactValueString{Value: fields[6]},
+			"offset":   &entities.FactValueFloat{Value: offset},
+			"selected": &entities.FactValueBool{Value: tally == ntpqSystemPeerTally},
+		}})
+
+		if tally == ntpqSystemPeerTally {
+			timeSync.Value["synchronized"] = &entities.FactValueBool{Value: true}
+			timeSync.Value["reference"] = &entities.FactValueString{Value: fields[0]}
+			timeSync.Value["stratum"] = &entities.FactValueInt{Value: stratum + 1}
+			timeSync.Value["offset"] = &entities.FactValueFloat{Value: offset}
+		}
+	}
+
+	timeSync.Value["sources"] = sources
+	return timeSync, nil
+}
+
+// readTimeServers reads the server, pool and peer directives of the chrony or ntp configuration file,
+// following the include directives. A missing file means that there are no servers configured
+func readTimeServers(fs afero.Fs, configPath string) ([]timeServer, error) {
+	content, err := afero.ReadFile(fs, configPath)
+	if os.IsNotExist(err) {
+		return []timeServer{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	servers := []timeServer{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// chrony accepts #, ;, ! and % as comment characters, ntp only #
+		if len(fields) < 2 || strings.ContainsAny(fields[0][:1], "#;!%") {
+			continue
+		}
+
+		switch fields[0] {
+		case "server", "pool", "peer":
+			servers = append(servers, timeServer{Type: fields[0], Address: fields[1], Options: fields[2:]})
+		case "include", "confdir":
+			includedServers, err := readIncludedTimeServers(fs, fields[0], fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			servers = append(servers, includedServers...)
+		}
+	}
+
+	return servers, nil
+}
+
+func readIncludedTimeServers(fs afero.Fs, directive string, patterns []string) ([]timeServer, error) {
+	servers := []timeServer{}
+
+	for _, pattern := range patterns {
+		// confdir includes the .conf files of the given directories
+		if directive == "confdir" {
+			pattern = strings.TrimSuffix(pattern, "/") + "/*.conf"
+		}
+
+		files, err := afero.Glob(fs, pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			includedServers, err := readTimeServers(fs, file)
+			if err != nil {
+				return nil, err
+			}
+			servers = append(servers, includedServers...)
+		}
+	}
+
+	return servers, nil
+}
+
+func timeServersToFactValue(servers []timeServer) *entities.FactValueList {
+	serversList := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, server := range servers {
+		serversList.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"type":    &entities.FactValueString{Value: server.Type},
+			"address": &entities.FactValueString{Value: server.Address},
+			"options": stringsToFactValueList(server.Options),
+		}})
+	}
+	return serversList
+}
@@ -0,0 +1,357 @@
+package gatherers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+)
+
+type TimeSyncTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+	fs           afero.Fs
+}
+
+func TestTimeSyncTestSuite(t *testing.T) {
+	suite.Run(t, new(TimeSyncTestSuite))
+}
+
+func (suite *TimeSyncTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+	suite.fs = afero.NewMemMapFs()
+	_ = afero.WriteFile(suite.fs, "/etc/chrony.conf", readFixture("gatherers/chrony.conf"), 0644)
+	_ = afero.WriteFile(suite.fs, "/etc/chrony.d/pool.conf", readFixture("gatherers/chrony.d/pool.conf"), 0644)
+	_ = afero.WriteFile(suite.fs, "/etc/ntp.conf", readFixture("gatherers/ntp.conf"), 0644)
+}
+
+func (suite *TimeSyncTestSuite) mockServices(chronydState, ntpdState string) {
+	suite.mockExecutor.On("Exec", "systemctl", "is-active", "chronyd.service").Return(
+		[]byte(chronydState+"\n"), nil)
+	suite.mockExecutor.On("Exec", "systemctl", "is-active", "ntpd.service").Return(
+		[]byte(ntpdState+"\n"), nil)
+}
+
+func timeServerFactValue(serverType, address string, options ...string) entities.FactValue {
+	optionsList := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, option := range options {
+		optionsList.AppendValue(&entities.FactValueString{Value: option})
+	}
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"type":    &entities.FactValueString{Value: serverType},
+		"address": &entities.FactValueString{Value: address},
+		"options": optionsList,
+	}}
+}
+
+func (suite *TimeSyncTestSuite) TestTimeSyncGatherChrony() {
+	suite.mockServices("active", "inactive")
+	suite.mockExecutor.On("Exec", "chronyc", "-c", "tracking").Return(
+		readFixture("gatherers/chronyc-tracking.output"), nil)
+	suite.mockExecutor.On("Exec", "chronyc", "-c", "sources").Return(
+		readFixture("gatherers/chronyc-sources.output"), nil)
+
+	c := gatherers.NewTimeSyncGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "service",
+			Gatherer: "time_sync",
+			Argument: "service",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "synchronized",
+			Gatherer: "time_sync",
+			Argument: "synchronized",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "offset",
+			Gatherer: "time_sync",
+			Argument: "offset",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "stratum",
+			Gatherer: "time_sync",
+			Argument: "stratum",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "selected_source",
+			Gatherer: "time_sync",
+			Argument: "sources.0",
+			CheckID:  "check2",
+		},
+		{
+			Name:     "servers",
+			Gatherer: "time_sync",
+			Argument: "configured_servers",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:    "service",
+			Value:   &entities.FactValueString{Value: "chronyd"},
+			CheckID: "check1",
+		},
+		{
+			Name:    "synchronized",
+			Value:   &entities.FactValueBool{Value: true},
+			CheckID: "check1",
+		},
+		{
+			Name:    "offset",
+			Value:   &entities.FactValueFloat{Value: 0.000012470},
+			CheckID: "check1",
+		},
+		{
+			Name:    "stratum",
+			Value:   &entities.FactValueInt{Value: 4},
+			CheckID: "check1",
+		},
+		{
+			Name: "selected_source",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"mode":     &entities.FactValueString{Value: "^"},
+				"state":    &entities.FactValueString{Value: "*"},
+				"address":  &entities.FactValueString{Value: "169.254.169.123"},
+				"stratum":  &entities.FactValueInt{Value: 3},
+				"reach":    &entities.FactValueString{Value: "377"},
+				"offset":   &entities.FactValueFloat{Value: 0.000002571},
+				"selected": &entities.FactValueBool{Value: true},
+			}},
+			CheckID: "check2",
+		},
+		{
+			Name: "servers",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				timeServerFactValue("server", "169.254.169.123", "prefer", "iburst", "minpoll", "4", "maxpoll", "4"),
+				timeServerFactValue("server", "ntp1.example.com", "iburst"),
+				timeServerFactValue("pool", "ntp2.example.com", "iburst", "maxsources", "2"),
+			}},
+			CheckID: "check2",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *TimeSyncTestSuite) TestTimeSyncGatherChronyUnsynchronised() {
+	suite.mockServices("active", "inactive")
+	suite.mockExecutor.On("Exec", "chronyc", "-c", "tracking").Return(
+		readFixture("gatherers/chronyc-tracking-unsynchronised.output"), nil)
+	suite.mockExecutor.On("Exec", "chronyc", "-c", "sources").Return([]byte{}, nil)
+
+	c := gatherers.NewTimeSyncGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "synchronized",
+			Gatherer: "time_sync",
+			Argument: "synchronized",
+		},
+		{
+			Name:     "leap_status",
+			Gatherer: "time_sync",
+			Argument: "leap_status",
+		},
+		{
+			Name:     "sources",
+			Gatherer: "time_sync",
+			Argument: "sources",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "synchronized",
+			Value: &entities.FactValueBool{Value: false},
+		},
+		{
+			Name:  "leap_status",
+			Value: &entities.FactValueString{Value: "Not synchronised"},
+		},
+		{
+			Name:  "sources",
+			Value: &entities.FactValueList{Value: []entities.FactValue{}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *TimeSyncTestSuite) TestTimeSyncGatherNTP() {
+	suite.mockServices("inactive", "active")
+	suite.mockExecutor.On("Exec", "ntpq", "-pn").Return(readFixture("gatherers/ntpq-peers.output"), nil)
+
+	c := gatherers.NewTimeSyncGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "time_sync",
+			Gatherer: "time_sync",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "time_sync",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"service":      &entities.FactValueString{Value: "ntpd"},
+				"synchronized": &entities.FactValueBool{Value: true},
+				"reference":    &entities.FactValueString{Value: "10.0.0.1"},
+				"stratum":      &entities.FactValueInt{Value: 2},
+				"offset":       &entities.FactValueFloat{Value: -0.000123},
+				"sources": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"mode":     &entities.FactValueString{Value: "u"},
+						"state":    &entities.FactValueString{Value: "*"},
+						"address":  &entities.FactValueString{Value: "10.0.0.1"},
+						"stratum":  &entities.FactValueInt{Value: 1},
+						"reach":    &entities.FactValueString{Value: "377"},
+						"offset":   &entities.FactValueFloat{Value: -0.000123},
+						"selected": &entities.FactValueBool{Value: true},
+					}},
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"mode":     &entities.FactValueString{Value: "u"},
+						"state":    &entities.FactValueString{Value: "+"},
+						"address":  &entities.FactValueString{Value: "10.0.0.2"},
+						"stratum":  &entities.FactValueInt{Value: 2},
+						"reach":    &entities.FactValueString{Value: "377"},
+						"offset":   &entities.FactValueFloat{Value: 0.000234},
+						"selected": &entities.FactValueBool{Value: false},
+					}},
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"mode":     &entities.FactValueString{Value: "p"},
+						"state":    &entities.FactValueString{Value: ""},
+						"address":  &entities.FactValueString{Value: "0.suse.pool.ntp"},
+						"stratum":  &entities.FactValueInt{Value: 16},
+						"reach":    &entities.FactValueString{Value: "0"},
+						"offset":   &entities.FactValueFloat{Value: 0},
+						"selected": &entities.FactValueBool{Value: false},
+					}},
+				}},
+				"configured_servers": &entities.FactValueList{Value: []entities.FactValue{
+					timeServerFactValue("server", "10.0.0.1", "iburst", "prefer"),
+					timeServerFactValue("server", "10.0.0.2", "iburst"),
+					timeServerFactValue("pool", "0.suse.pool.ntp.org", "iburst"),
+				}},
+			}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *TimeSyncTestSuite) TestTimeSyncGatherNoService() {
+	suite.mockServices("inactive", "unknown")
+
+	c := gatherers.NewTimeSyncGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "service",
+			Gatherer: "time_sync",
+			Argument: "service",
+		},
+		{
+			Name:     "synchronized",
+			Gatherer: "time_sync",
+			Argument: "synchronized",
+		},
+		{
+			Name:     "offset",
+			Gatherer: "time_sync",
+			Argument: "offset",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "service",
+			Value: &entities.FactValueString{Value: "none"},
+		},
+		{
+			Name:  "synchronized",
+			Value: &entities.FactValueBool{Value: false},
+		},
+		{
+			Name:  "offset",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error getting value: requested field value not found: offset",
+				Type:    "value-not-found",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *TimeSyncTestSuite) TestTimeSyncGatherErrors() {
+	suite.mockServices("active", "inactive")
+	suite.mockExecutor.On("Exec", "chronyc", "-c", "tracking").Return(
+		nil, errors.New("506 Cannot talk to daemon")).Once()
+
+	c := gatherers.NewTimeSyncGatherer(suite.mockExecutor, suite.fs)
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "synchronized",
+			Gatherer: "time_sync",
+			Argument: "synchronized",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	suite.NoError(err)
+	suite.ElementsMatch([]entities.Fact{
+		{
+			Name:  "synchronized",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error executing the time synchronization command: chronyc tracking: 506 Cannot talk to daemon",
+				Type:    "time-sync-command-error",
+			},
+		},
+	}, factResults)
+
+	suite.mockExecutor.On("Exec", "chronyc", "-c", "tracking").Return([]byte("A9FEA97B,169.254.169.123\n"), nil)
+
+	factResults, err = c.Gather(factRequests)
+
+	suite.NoError(err)
+	suite.ElementsMatch([]entities.Fact{
+		{
+			Name:  "synchronized",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error decoding the time synchronization state: " +
+					"unexpected chronyc tracking output: A9FEA97B,169.254.169.123",
+				Type: "time-sync-decoding-error",
+			},
+		},
+	}, factResults)
+}
@@ -0,0 +1,277 @@
+package gatherers
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/trento-project/agent/internal/core/cluster"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	"github.com/trento-project/agent/pkg/utils"
+)
+
+const (
+	WatchdogGathererName  = "watchdog"
+	watchdogDevicesGlob   = "/dev/watchdog*"
+	watchdogDefaultDevice = "/dev/watchdog"
+	watchdogSysfsPath     = "/sys/class/watchdog"
+	procModulesPath       = "/proc/modules"
+	softdogModule         = "softdog"
+	softdogIdentity       = "Software Watchdog"
+	watchdogTypeSoftware  = "software"
+	watchdogTypeHardware  = "hardware"
+	sbdWatchdogDevice     = "SBD_WATCHDOG_DEV"
+	sbdWatchdogTimeout    = "SBD_WATCHDOG_TIMEOUT"
+	sbdDefaultTimeout     = 5
+	sbdProcessName        = "sbd"
+)
+
+// nolint:gochecknoglobals
+var (
+	WatchdogFileError = entities.FactGatheringError{
+		Type:    "watchdog-file-error",
+		Message: "error reading the watchdog information",
+	}
+)
+
+type WatchdogGatherer struct {
+	executor      utils.CommandExecutor
+	fs            afero.Fs
+	sbdConfigFile string
+}
+
+func NewDefaultWatchdogGatherer() *WatchdogGatherer {
+	return NewWatchdogGatherer(utils.Executor{}, afero.NewOsFs(), cluster.SBDConfigPath)
+}
+
+func NewWatchdogGatherer(executor utils.CommandExecutor, fs afero.Fs, sbdConfigFile string) *WatchdogGatherer {
+	return &WatchdogGatherer{
+		executor:      executor,
+		fs:            fs,
+		sbdConfigFile: sbdConfigFile,
+	}
+}
+
+// Gather lists the watchdog devices with their driver, timeouts and the processes holding them,
+// and correlates them with the sbd watchdog configuration. The argument is the path to the
+// requested value, like devices.watchdog0.timeout or sbd.device_present
+func (g *WatchdogGatherer) Gather(factsRequests []entities.FactRequest) ([]entities.Fact, error) {
+	facts := []entities.Fact{}
+	log.Infof("Starting %s facts gathering process", WatchdogGathererName)
+
+	watchdog, gatheringError := g.gatherWatchdog()
+	if gatheringError != nil {
+		log.Error(gatheringError)
+		return entities.NewFactsGatheredListWithError(factsRequests, gatheringError), nil
+	}
+
+	for _, factReq := range factsRequests {
+		var fact entities.Fact
+
+		if value, err := watchdog.GetValue(factReq.Argument); err == nil {
+			fact = entities.NewFactGatheredWithRequest(factReq, value)
+		} else {
+			log.Error(err)
+			fact = entities.NewFactGatheredWithError(factReq, err)
+		}
+		facts = append(facts, fact)
+	}
+
+	log.Infof("Requested %s facts gathered", WatchdogGathererName)
+	return facts, nil
+}
+
+func (g *WatchdogGatherer) gatherWatchdog() (*entities.FactValueMap, *entities.FactGatheringError) {
+	devicePaths, err := afero.Glob(g.fs, watchdogDevicesGlob)
+	if err != nil {
+		return nil, WatchdogFileError.Wrap(err.Error())
+	}
+
+	loadedModules, err := g.loadedModules()
+	if err != nil {
+		return nil, WatchdogFileError.Wrap(err.Error())
+	}
+
+	devices := &entities.FactValueMap{Value: make(map[string]entities.FactValue)}
+	// the modules providing the watchdog devices, built-in drivers are not listed
+	modules := []string{}
+	for _, devicePath := range devicePaths {
+		device, module := g.gatherWatchdogDevice(devicePath)
+		devices.Value[path.Base(devicePath)] = device
+		if loadedModules[module] {
+			modules = appendUnique(modules, module)
+		}
+	}
+
+	watchdog := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"devices": devices,
+		"modules": stringsToFactValueList(modules),
+	}}
+
+	sbdConfig, err := cluster.LoadSbdConfig(g.sbdConfigFile)
+	if err != nil {
+		log.Debugf("SBD watchdog configuration not available: %s", err)
+		return watchdog, nil
+	}
+
+	watchdog.Value["sbd"] = sbdWatchdogFactValue(sbdConfig, devices)
+
+	return watchdog, nil
+}
+
+// gatherWatchdogDevice returns the device details and the kernel module of its driver
+func (g *WatchdogGatherer) gatherWatchdogDevice(devicePath string) (*entities.FactValueMap, string) {
+	// /dev/watchdog is the legacy interface of the first registered watchdog
+	sysfsName := path.Base(devicePath)
+	if devicePath == watchdogDefaultDevice {
+		sysfsName = "watchdog0"
+	}
+	sysfsDevice := path.Join(watchdogSysfsPath, sysfsName)
+
+	identity := g.readSysfsValue(sysfsDevice, "identity")
+
+	// the driver links to its module, unless it's built in the kernel. softdog has no parent
+	// device, so it's only recognized by its identity
+	module := g.readSysfsLink(sysfsDevice, "device/driver/module")
+	if module == "" && identity == softdogIdentity {
+		module = softdogModule
+	}
+
+	driver := module
+	if driver == "" {
+		driver = g.readSysfsLink(sysfsDevice, "device/driver")
+	}
+
+	deviceType := watchdogTypeHardware
+	if module == softdogModule {
+		deviceType = watchdogTypeSoftware
+	}
+
+	holders := &entities.FactValueList{Value: []entities.FactValue{}}
+	for _, pid := range g.deviceHolders(devicePath) {
+		command, _ := afero.ReadFile(g.fs, path.Join("/proc", strconv.Itoa(pid), "comm"))
+		holders.AppendValue(&entities.FactValueMap{Value: map[string]entities.FactValue{
+			"pid":     &entities.FactValueInt{Value: pid},
+			"command": &entities.FactValueString{Value: strings.TrimSpace(string(command))},
+		}})
+	}
+
+	return &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"path":       &entities.FactValueString{Value: devicePath},
+		"identity":   &entities.FactValueString{Value: identity},
+		"driver":     &entities.FactValueString{Value: driver},
+		"type":       &entities.FactValueString{Value: deviceType},
+		"timeout":    entities.ParseStringToFactValue(g.readSysfsValue(sysfsDevice, "timeout")),
+		"pretimeout": entities.ParseStringToFactValue(g.readSysfsValue(sysfsDevice, "pretimeout")),
+		"state":      &entities.FactValueString{Value: g.readSysfsValue(sysfsDevice, "state")},
+		"nowayout":   &entities.FactValueBool{Value: g.readSysfsValue(sysfsDevice, "nowayout") == "1"},
+		"holders":    holders,
+	}}, module
+}
+
+// readSysfsValue returns the content of the sysfs attribute, or an empty string
+// if the attribute is not available, as not all the drivers implement all of them
+func (g *WatchdogGatherer) readSysfsValue(sysfsDevice, attribute string) string {
+	content, err := afero.ReadFile(g.fs, path.Join(sysfsDevice, attribute))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// readSysfsLink returns the name of the entry the sysfs link points to, or an empty string
+// if the link doesn't exist or the filesystem doesn't support links
+func (g *WatchdogGatherer) readSysfsLink(sysfsDevice, link string) string {
+	linkReader, ok := g.fs.(afero.LinkReader)
+	if !ok {
+		return ""
+	}
+
+	target, err := linkReader.ReadlinkIfPossible(path.Join(sysfsDevice, link))
+	if err != nil {
+		return ""
+	}
+	return path.Base(target)
+}
+
+// deviceHolders returns the pids of the processes holding the device open. fuser prints the
+// pids in the standard output, and exits with a non zero code if no process is using the device
+func (g *WatchdogGatherer) deviceHolders(devicePath string) []int {
+	output, _ := g.executor.Exec("fuser", devicePath)
+
+	pids := []int{}
+	for _, field := range strings.Fields(string(output)) {
+		// fuser appends the access type to the pid, like 1234o
+		if pid, err := strconv.Atoi(strings.TrimRight(field, "cefFrmo")); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+func (g *WatchdogGatherer) loadedModules() (map[string]bool, error) {
+	modules := make(map[string]bool)
+
+	content, err := afero.ReadFile(g.fs, procModulesPath)
+	if os.IsNotExist(err) {
+		return modules, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			modules[fields[0]] = true
+		}
+	}
+	return modules, nil
+}
+
+// sbdWatchdogFactValue correlates the sbd watchdog configuration with the available devices.
+// sbd sets the device timeout to SBD_WATCHDOG_TIMEOUT when it opens the device
+func sbdWatchdogFactValue(sbdConfig map[string]string, devices *entities.FactValueMap) *entities.FactValueMap {
+	devicePath := sbdConfig[sbdWatchdogDevice]
+	if devicePath == "" {
+		devicePath = watchdogDefaultDevice
+	}
+
+	timeout := sbdDefaultTimeout
+	if configuredTimeout, err := strconv.Atoi(sbdConfig[sbdWatchdogTimeout]); err == nil {
+		timeout = configuredTimeout
+	}
+
+	sbdWatchdog := &entities.FactValueMap{Value: map[string]entities.FactValue{
+		"watchdog_device":  &entities.FactValueString{Value: devicePath},
+		"watchdog_timeout": &entities.FactValueInt{Value: timeout},
+		"device_present":   &entities.FactValueBool{Value: false},
+		"held_by_sbd":      &entities.FactValueBool{Value: false},
+		"timeout_matches":  &entities.FactValueBool{Value: false},
+	}}
+
+	device, found := devices.Value[path.Base(devicePath)].(*entities.FactValueMap)
+	if !found {
+		return sbdWatchdog
+	}
+
+	sbdWatchdog.Value["device_present"] = &entities.FactValueBool{Value: true}
+	sbdWatchdog.Value["timeout_matches"] = &entities.FactValueBool{
+		Value: device.Value["timeout"].AsInterface() == timeout,
+	}
+
+	if holders, ok := device.Value["holders"].(*entities.FactValueList); ok {
+		for _, holder := range holders.Value {
+			holderMap, ok := holder.(*entities.FactValueMap)
+			if ok && holderMap.Value["command"].AsInterface() == sbdProcessName {
+				sbdWatchdog.Value["held_by_sbd"] = &entities.FactValueBool{Value: true}
+			}
+		}
+	}
+
+	return sbdWatchdog
+}
@@ -0,0 +1,280 @@
+package gatherers_test
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+	"github.com/trento-project/agent/internal/factsengine/gatherers"
+	"github.com/trento-project/agent/pkg/factsengine/entities"
+	utilsMocks "github.com/trento-project/agent/pkg/utils/mocks"
+	"github.com/trento-project/agent/test/helpers"
+)
+
+type WatchdogTestSuite struct {
+	suite.Suite
+	mockExecutor *utilsMocks.CommandExecutor
+	fs           afero.Fs
+}
+
+func TestWatchdogTestSuite(t *testing.T) {
+	suite.Run(t, new(WatchdogTestSuite))
+}
+
+func (suite *WatchdogTestSuite) SetupTest() {
+	suite.mockExecutor = new(utilsMocks.CommandExecutor)
+	// the sysfs links are needed to find the drivers, and the memory filesystem doesn't support them
+	suite.fs = afero.NewBasePathFs(afero.NewOsFs(), suite.T().TempDir())
+
+	files := map[string]string{
+		"/dev/watchdog":                          "",
+		"/dev/watchdog0":                         "",
+		"/sys/class/watchdog/watchdog0/identity": "Software Watchdog\n",
+		"/sys/class/watchdog/watchdog0/timeout":  "5\n",
+		"/sys/class/watchdog/watchdog0/state":    "active\n",
+		"/sys/class/watchdog/watchdog0/nowayout": "0\n",
+		"/proc/2150/comm":                        "sbd\n",
+		"/proc/modules":                          string(readFixture("gatherers/proc-modules")),
+	}
+	for filePath, content := range files {
+		suite.writeFile(filePath, content)
+	}
+}
+
+func (suite *WatchdogTestSuite) writeFile(filePath, content string) {
+	suite.NoError(suite.fs.MkdirAll(path.Dir(filePath), 0755))
+	suite.NoError(afero.WriteFile(suite.fs, filePath, []byte(content), 0644))
+}
+
+func (suite *WatchdogTestSuite) symlink(target, link string) {
+	suite.NoError(suite.fs.MkdirAll(target, 0755))
+	suite.NoError(suite.fs.MkdirAll(path.Dir(link), 0755))
+	suite.NoError(suite.fs.(afero.Linker).SymlinkIfPossible(target, link))
+}
+
+// mockDriver links the watchdog device to its platform device and driver, and the driver to its module
+// unless it's built in the kernel
+func (suite *WatchdogTestSuite) mockDriver(sysfsName, driver, module string) {
+	platformDevice := "/sys/devices/platform/" + driver + ".0"
+	suite.symlink(platformDevice, "/sys/class/watchdog/"+sysfsName+"/device")
+	suite.symlink("/sys/bus/platform/drivers/"+driver, platformDevice+"/driver")
+	if module != "" {
+		suite.symlink("/sys/module/"+module, "/sys/bus/platform/drivers/"+driver+"/module")
+	}
+}
+
+func (suite *WatchdogTestSuite) TestWatchdogGather() {
+	suite.mockExecutor.On("Exec", "fuser", "/dev/watchdog").Return([]byte(" 2150"), nil)
+	suite.mockExecutor.On("Exec", "fuser", "/dev/watchdog0").Return([]byte{}, errors.New("exit status 1"))
+
+	c := gatherers.NewWatchdogGatherer(
+		suite.mockExecutor, suite.fs, helpers.GetFixturePath("discovery/cluster/sbd/sbd_config"))
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "watchdog",
+			Gatherer: "watchdog",
+			Argument: "devices.watchdog",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "watchdog0_holders",
+			Gatherer: "watchdog",
+			Argument: "devices.watchdog0.holders",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "modules",
+			Gatherer: "watchdog",
+			Argument: "modules",
+			CheckID:  "check1",
+		},
+		{
+			Name:     "sbd",
+			Gatherer: "watchdog",
+			Argument: "sbd",
+			CheckID:  "check2",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name: "watchdog",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"path":       &entities.FactValueString{Value: "/dev/watchdog"},
+				"identity":   &entities.FactValueString{Value: "Software Watchdog"},
+				"driver":     &entities.FactValueString{Value: "softdog"},
+				"type":       &entities.FactValueString{Value: "software"},
+				"timeout":    &entities.FactValueInt{Value: 5},
+				"pretimeout": &entities.FactValueString{Value: ""},
+				"state":      &entities.FactValueString{Value: "active"},
+				"nowayout":   &entities.FactValueBool{Value: false},
+				"holders": &entities.FactValueList{Value: []entities.FactValue{
+					&entities.FactValueMap{Value: map[string]entities.FactValue{
+						"pid":     &entities.FactValueInt{Value: 2150},
+						"command": &entities.FactValueString{Value: "sbd"},
+					}},
+				}},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name:    "watchdog0_holders",
+			Value:   &entities.FactValueList{Value: []entities.FactValue{}},
+			CheckID: "check1",
+		},
+		{
+			Name: "modules",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "softdog"},
+			}},
+			CheckID: "check1",
+		},
+		{
+			Name: "sbd",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"watchdog_device":  &entities.FactValueString{Value: "/dev/watchdog"},
+				"watchdog_timeout": &entities.FactValueInt{Value: 5},
+				"device_present":   &entities.FactValueBool{Value: true},
+				"held_by_sbd":      &entities.FactValueBool{Value: true},
+				"timeout_matches":  &entities.FactValueBool{Value: true},
+			}},
+			CheckID: "check2",
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *WatchdogTestSuite) TestWatchdogGatherHardwareDevice() {
+	suite.writeFile("/sys/class/watchdog/watchdog0/identity", "sp805-wdt\n")
+	suite.writeFile("/sys/class/watchdog/watchdog0/timeout", "30\n")
+	suite.mockDriver("watchdog0", "sp805-wdt", "sp805_wdt")
+	suite.writeFile("/dev/watchdog1", "")
+	suite.writeFile("/sys/class/watchdog/watchdog1/identity", "wdat_wdt\n")
+	suite.mockDriver("watchdog1", "wdat_wdt", "")
+	suite.writeFile("/proc/modules", string(readFixture("gatherers/proc-modules"))+
+		"sp805_wdt 16384 0 - Live 0x0000000000000000\n")
+	suite.mockExecutor.On("Exec", "fuser", "/dev/watchdog").Return([]byte{}, errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "fuser", "/dev/watchdog0").Return([]byte{}, errors.New("exit status 1"))
+	suite.mockExecutor.On("Exec", "fuser", "/dev/watchdog1").Return([]byte{}, errors.New("exit status 1"))
+
+	c := gatherers.NewWatchdogGatherer(
+		suite.mockExecutor, suite.fs, helpers.GetFixturePath("discovery/cluster/sbd/sbd_config"))
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "identity",
+			Gatherer: "watchdog",
+			Argument: "devices.watchdog0.identity",
+		},
+		{
+			Name:     "driver",
+			Gatherer: "watchdog",
+			Argument: "devices.watchdog0.driver",
+		},
+		{
+			Name:     "type",
+			Gatherer: "watchdog",
+			Argument: "devices.watchdog0.type",
+		},
+		{
+			Name:     "builtin_driver",
+			Gatherer: "watchdog",
+			Argument: "devices.watchdog1.driver",
+		},
+		{
+			Name:     "modules",
+			Gatherer: "watchdog",
+			Argument: "modules",
+		},
+		{
+			Name:     "sbd",
+			Gatherer: "watchdog",
+			Argument: "sbd",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "identity",
+			Value: &entities.FactValueString{Value: "sp805-wdt"},
+		},
+		{
+			Name:  "driver",
+			Value: &entities.FactValueString{Value: "sp805_wdt"},
+		},
+		{
+			Name:  "type",
+			Value: &entities.FactValueString{Value: "hardware"},
+		},
+		{
+			Name:  "builtin_driver",
+			Value: &entities.FactValueString{Value: "wdat_wdt"},
+		},
+		{
+			Name: "modules",
+			Value: &entities.FactValueList{Value: []entities.FactValue{
+				&entities.FactValueString{Value: "sp805_wdt"},
+			}},
+		},
+		{
+			Name: "sbd",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{
+				"watchdog_device":  &entities.FactValueString{Value: "/dev/watchdog"},
+				"watchdog_timeout": &entities.FactValueInt{Value: 5},
+				"device_present":   &entities.FactValueBool{Value: true},
+				"held_by_sbd":      &entities.FactValueBool{Value: false},
+				"timeout_matches":  &entities.FactValueBool{Value: false},
+			}},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+}
+
+func (suite *WatchdogTestSuite) TestWatchdogGatherNoDevices() {
+	c := gatherers.NewWatchdogGatherer(suite.mockExecutor, afero.NewMemMapFs(), "non_existing_sbd_config")
+
+	factRequests := []entities.FactRequest{
+		{
+			Name:     "devices",
+			Gatherer: "watchdog",
+			Argument: "devices",
+		},
+		{
+			Name:     "sbd",
+			Gatherer: "watchdog",
+			Argument: "sbd",
+		},
+	}
+
+	factResults, err := c.Gather(factRequests)
+
+	expectedResults := []entities.Fact{
+		{
+			Name:  "devices",
+			Value: &entities.FactValueMap{Value: map[string]entities.FactValue{}},
+		},
+		{
+			Name:  "sbd",
+			Value: nil,
+			Error: &entities.FactGatheringError{
+				Message: "error getting value: requested field value not found: sbd",
+				Type:    "value-not-found",
+			},
+		},
+	}
+
+	suite.NoError(err)
+	suite.ElementsMatch(expectedResults, factResults)
+	suite.mockExecutor.AssertNotCalled(suite.T(), "Exec")
+}
@@ -4,11 +4,14 @@ package factsengine
 import (
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/trento-project/agent/internal/redaction"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
 	"github.com/trento-project/contracts/go/pkg/events"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+const factsGatheredRedactionSource = "facts_gathered"
+
 func FactsGatheringRequestedFromEvent(event []byte) (*entities.FactsGatheringRequested, error) {
 	var factsGatheringRequestedEvent events.FactsGatheringRequested
 
@@ -43,7 +46,7 @@ func FactsGatheringRequestedFromEvent(event []byte) (*entities.FactsGatheringReq
 	}, nil
 }
 
-func factGatheredItemToEvent(fact entities.Fact) (*events.Fact, error) {
+func factGatheredItemToEvent(fact entities.Fact, redactor *redaction.Redactor) (*events.Fact, error) {
 	eventFact := &events.Fact{
 		CheckId: fact.CheckID,
 		Name:    fact.Name,
@@ -57,7 +60,8 @@ func factGatheredItemToEvent(fact entities.Fact) (*events.Fact, error) {
 			},
 		}
 	} else {
-		value, err := structpb.NewValue(fact.Value.AsInterface())
+		redactedValue := redactor.RedactValue(factsGatheredRedactionSource, fact.Name, fact.Value.AsInterface())
+		value, err := structpb.NewValue(redactedValue)
 
 		if err != nil {
 			return nil, err
@@ -71,10 +75,10 @@ func factGatheredItemToEvent(fact entities.Fact) (*events.Fact, error) {
 	return eventFact, nil
 }
 
-func FactsGatheredToEvent(gatheredFacts entities.FactsGathered) ([]byte, error) {
+func FactsGatheredToEvent(gatheredFacts entities.FactsGathered, redactor *redaction.Redactor) ([]byte, error) {
 	facts := []*events.Fact{}
 	for _, fact := range gatheredFacts.FactsGathered {
-		eventFact, err := factGatheredItemToEvent(fact)
+		eventFact, err := factGatheredItemToEvent(fact, redactor)
 		if err != nil {
 			return nil, err
 		}
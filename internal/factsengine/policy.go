@@ -78,7 +78,7 @@ func getAgentFacts(
 
 func (c *FactsEngine) publishFacts(facts entities.FactsGathered) error {
 	log.Infof("Publishing gathered facts to the checks engine service")
-	event, err := FactsGatheredToEvent(facts)
+	event, err := FactsGatheredToEvent(facts, c.redactor)
 	if err != nil {
 		return err
 	}
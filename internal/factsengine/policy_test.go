@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/trento-project/agent/internal/factsengine/adapters/mocks"
+	"github.com/trento-project/agent/internal/redaction"
 	"github.com/trento-project/agent/pkg/factsengine/entities"
 	"github.com/trento-project/contracts/go/pkg/events"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -34,6 +35,7 @@ func (suite *PolicyTestSuite) SetupTest() {
 	suite.factsEngine = FactsEngine{    // nolint
 		agentID:             suite.agentID,
 		factsServiceAdapter: &suite.mockAdapter,
+		redactor:            redaction.NewDefaultRedactor(),
 	}
 }
 
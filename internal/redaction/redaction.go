@@ -0,0 +1,264 @@
+package redaction
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	MarkerMode string = "marker"
+	HashMode   string = "hash"
+
+	Marker     string = "********"
+	hashPrefix string = "sha256:"
+)
+
+// nolint:gochecknoglobals
+var (
+	// DefaultKeyPatterns are matched, case insensitive, against the whole field names and the names
+	// of the name/value pairs, like the CIB instance attributes. * matches any character, so
+	// flags like disablePasswordAuthentication are kept
+	DefaultKeyPatterns = []string{
+		"passw*",
+		"*password",
+		"*passwd",
+		"secret*",
+		"*secret",
+		"*token",
+		"*access_key",
+		"*private_key",
+		"*api_key",
+		"*apikey",
+	}
+
+	// DefaultValuePatterns detect well known secrets, whatever the name of the field they are in
+	DefaultValuePatterns = []string{
+		`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+		`\bAKIA[0-9A-Z]{16}\b`,
+		`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`,
+		`(?i)AccountKey=[A-Za-z0-9+/=]+`,
+	}
+)
+
+type Config struct {
+	KeyPatterns   []string
+	ValuePatterns []string
+	// Mode is marker, to replace the secrets with a fixed marker, or hash, to replace them with
+	// a salted hash, so the server can tell if a secret changed without knowing it
+	Mode string
+	Salt string
+}
+
+// Redactor replaces the secrets in the data published by the agent, before it leaves the host
+type Redactor struct {
+	keyPatterns    []*regexp.Regexp
+	valueDetectors []*regexp.Regexp
+	mode           string
+	salt           string
+}
+
+func NewDefaultRedactor() *Redactor {
+	redactor, err := NewRedactor(Config{
+		KeyPatterns:   DefaultKeyPatterns,
+		ValuePatterns: DefaultValuePatterns,
+		Mode:          MarkerMode,
+		Salt:          "",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return redactor
+}
+
+func NewRedactor(config Config) (*Redactor, error) {
+	redactor := &Redactor{
+		keyPatterns:    []*regexp.Regexp{},
+		valueDetectors: []*regexp.Regexp{},
+		mode:           config.Mode,
+		salt:           config.Salt,
+	}
+
+	switch config.Mode {
+	case MarkerMode:
+	case HashMode:
+		if config.Salt == "" {
+			return nil, errors.New("a salt is required to redact the secrets with a hash")
+		}
+	default:
+		return nil, errors.Errorf("invalid redaction mode %s, should be %s or %s", config.Mode, MarkerMode, HashMode)
+	}
+
+	for _, pattern := range config.KeyPatterns {
+		redactor.keyPatterns = append(redactor.keyPatterns, globToRegexp(pattern))
+	}
+
+	for _, pattern := range config.ValuePatterns {
+		detector, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid redaction value pattern %s", pattern)
+		}
+		redactor.valueDetectors = append(redactor.valueDetectors, detector)
+	}
+
+	return redactor, nil
+}
+
+// Redact returns the data with the secrets replaced. The data is walked in its JSON form,
+// so it is returned as is if there is nothing to redact, keeping its JSON encoding
+func (r *Redactor) Redact(source string, data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	// keep the numbers as they are, instead of converting them to float64
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	redactedFields := []string{}
+	redacted := r.redact("", "", generic, &redactedFields)
+	if len(redactedFields) == 0 {
+		return data, nil
+	}
+
+	r.audit(source, redactedFields)
+	return redacted, nil
+}
+
+// RedactValue redacts a value already made of maps, lists and scalars, like the facts values.
+// The key is the name of the value, only used to log the redacted fields. It is not checked
+// against the key patterns, as it names what was gathered, like hacluster_password for a check
+// of the password, not a secret. The key patterns apply to the keys of the maps in the value
+func (r *Redactor) RedactValue(source, key string, value interface{}) interface{} {
+	redactedFields := []string{}
+	redacted := r.redact("", key, value, &redactedFields)
+	if len(redactedFields) > 0 {
+		r.audit(source, redactedFields)
+	}
+
+	return redacted
+}
+
+func (r *Redactor) redact(key, path string, value interface{}, redactedFields *[]string) interface{} {
+	if key != "" && r.isSecretKey(key) {
+		return r.replace(path, value, redactedFields)
+	}
+
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		secretValueKey := r.secretNameValuePair(typedValue)
+		result := make(map[string]interface{}, len(typedValue))
+		for itemKey, item := range typedValue {
+			itemPath := joinPath(path, itemKey)
+			if itemKey == secretValueKey {
+				result[itemKey] = r.replace(itemPath, item, redactedFields)
+				continue
+			}
+			result[itemKey] = r.redact(itemKey, itemPath, item, redactedFields)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for index, item := range typedValue {
+			result[index] = r.redact("", fmt.Sprintf("%s[%d]", path, index), item, redactedFields)
+		}
+		return result
+	case string:
+		if r.isSecretValue(typedValue) {
+			return r.replace(path, value, redactedFields)
+		}
+	}
+
+	return value
+}
+
+// secretNameValuePair returns the key holding the value of a name/value pair with a secret name,
+// like a CIB nvpair {"Name": "password", "Value": "..."}, or an empty string
+func (r *Redactor) secretNameValuePair(pair map[string]interface{}) string {
+	var name, valueKey string
+	for key, item := range pair {
+		switch strings.ToLower(key) {
+		case "name":
+			name, _ = item.(string)
+		case "value":
+			valueKey = key
+		}
+	}
+
+	if name == "" || valueKey == "" || !r.isSecretKey(name) {
+		return ""
+	}
+	return valueKey
+}
+
+func (r *Redactor) isSecretKey(key string) bool {
+	for _, pattern := range r.keyPatterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) isSecretValue(value string) bool {
+	for _, detector := range r.valueDetectors {
+		if detector.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) replace(path string, value interface{}, redactedFields *[]string) interface{} {
+	*redactedFields = append(*redactedFields, path)
+
+	if r.mode != HashMode {
+		return Marker
+	}
+
+	plain, isString := value.(string)
+	if !isString {
+		encoded, _ := json.Marshal(value)
+		plain = string(encoded)
+	}
+
+	hash := sha256.Sum256([]byte(r.salt + plain))
+	return hashPrefix + hex.EncodeToString(hash[:])
+}
+
+// audit logs the redacted fields, never their values
+func (r *Redactor) audit(source string, redactedFields []string) {
+	sort.Strings(redactedFields)
+	log.WithFields(log.Fields{
+		"source": source,
+		"fields": strings.Join(redactedFields, ", "),
+	}).Infof("Redacted %d fields before publishing", len(redactedFields))
+}
+
+func globToRegexp(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("(?i)^" + quoted + "$")
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -23,6 +23,10 @@ cat <<EOF
           <nvpair id="nodes-1084783375-hana_prd_srmode" name="hana_prd_srmode" value="sync"/>
           <nvpair id="nodes-1084783375-hana_prd_remoteHost" name="hana_prd_remoteHost" value="node02"/>
         </instance_attributes>
+        <utilization id="nodes-1084783375-utilization">
+          <nvpair id="nodes-1084783375-utilization-cpu" name="cpu" value="8"/>
+          <nvpair id="nodes-1084783375-utilization-memory" name="memory" value="65536"/>
+        </utilization>
       </node>
       <node id="1084783376" uname="node02">
         <instance_attributes id="nodes-1084783376">
@@ -124,6 +128,19 @@ cat <<EOF
       <rsc_location id="cli-prefer-cln_SAPHanaTopology_PRD_HDB00" rsc="cln_SAPHanaTopology_PRD_HDB00" role="Started" node="node01" score="INFINITY"/>
       <rsc_location id="cli-ban-msl_SAPHana_PRD_HDB00-on-node01" rsc="msl_SAPHana_PRD_HDB00" role="Started" node="node01" score="-INFINITY"/>
       <rsc_location id="test" rsc="test" role="Started" node="node02" score="666"/>
+      <rsc_location id="loc_ip_PRD_HDB00_not_on_maintenance" rsc="rsc_ip_PRD_HDB00">
+        <rule id="loc_ip_PRD_HDB00_not_on_maintenance-rule" score="-INFINITY" boolean-op="or">
+          <expression id="loc_ip_PRD_HDB00_not_on_maintenance-rule-expression" attribute="maintenance" operation="eq" value="true"/>
+          <expression id="loc_ip_PRD_HDB00_not_on_maintenance-rule-expression-0" attribute="#uname" operation="not_defined"/>
+        </rule>
+      </rsc_location>
+      <rsc_colocation id="col_test_set" score="INFINITY">
+        <resource_set id="col_test_set-0" sequential="false">
+          <resource_ref id="test"/>
+          <resource_ref id="test-stop"/>
+        </resource_set>
+      </rsc_colocation>
+      <rsc_ticket id="tkt_SAPHana_PRD_HDB00" ticket="ticket-PRD" rsc="msl_SAPHana_PRD_HDB00" rsc-role="Master" loss-policy="demote"/>
     </constraints>
     <rsc_defaults>
       <meta_attributes id="rsc-options">
@@ -137,6 +154,18 @@ cat <<EOF
         <nvpair name="record-pending" value="true" id="op-options-record-pending"/>
       </meta_attributes>
     </op_defaults>
+    <fencing-topology>
+      <fencing-level id="fencing-1" index="1" target="node01" devices="stonith-sbd"/>
+      <fencing-level id="fencing-2" index="1" target="node02" devices="stonith-sbd"/>
+    </fencing-topology>
+    <alerts>
+      <alert id="alert_file" path="/usr/share/pacemaker/alerts/alert_file.sh.sample">
+        <meta_attributes id="alert_file-meta_attributes">
+          <nvpair id="alert_file-meta_attributes-timeout" name="timeout" value="15s"/>
+        </meta_attributes>
+        <recipient id="alert_file-recipient" value="/var/log/cluster-alerts.log"/>
+      </alert>
+    </alerts>
   </configuration>
   <status>
     <node_state id="1084783375" uname="node01" in_ccm="true" crmd="online" crm-debug-origin="do_update_resource" join="member" expected="member">